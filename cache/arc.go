@@ -0,0 +1,202 @@
+package cache
+
+import "github.com/denismitr/gds/linkedlist"
+
+type arcQueue int8
+
+const (
+	arcNone arcQueue = iota
+	arcRecent
+	arcFrequent
+)
+
+// ghost remembers a key that was recently evicted, without its value
+type ghost[K comparable] struct {
+	key  K
+	cost int64
+}
+
+type ghostList[K comparable] struct {
+	keys  *linkedlist.DoublyLinkedList[ghost[K]]
+	index map[K]*linkedlist.Element[ghost[K]]
+	cost  int64
+}
+
+func newGhostList[K comparable]() *ghostList[K] {
+	return &ghostList[K]{
+		keys:  linkedlist.NewDoubly[ghost[K]](false),
+		index: make(map[K]*linkedlist.Element[ghost[K]]),
+	}
+}
+
+func (g *ghostList[K]) push(key K, cost int64) {
+	g.index[key] = g.keys.PushFront(ghost[K]{key: key, cost: cost})
+	g.cost += cost
+}
+
+func (g *ghostList[K]) remove(key K) bool {
+	elem, ok := g.index[key]
+	if !ok {
+		return false
+	}
+
+	g.keys.Remove(elem)
+	delete(g.index, key)
+	g.cost -= elem.Value().cost
+	return true
+}
+
+func (g *ghostList[K]) dropOldest() bool {
+	oldest := g.keys.Back()
+	if oldest == nil {
+		return false
+	}
+
+	return g.remove(oldest.Value().key)
+}
+
+// arc implements the adaptive replacement cache by Megiddo and Modha with sizes measured in cost.
+// Entries seen once live in the recent queue (T1), entries seen at least twice in the frequent one (T2).
+// Keys evicted from them are remembered in ghost lists (B1 and B2), a hit in a ghost list
+// shifts the target size of T1 towards the queue that would have kept the key.
+type arc[K comparable, V any] struct {
+	capacity       int64
+	target         int64
+	recent         *linkedlist.DoublyLinkedList[*entry[K, V]]
+	frequent       *linkedlist.DoublyLinkedList[*entry[K, V]]
+	recentCost     int64
+	frequentCost   int64
+	recentGhosts   *ghostList[K]
+	frequentGhosts *ghostList[K]
+}
+
+func newARC[K comparable, V any](capacity int64) *arc[K, V] {
+	p := &arc[K, V]{capacity: capacity}
+	p.reset()
+	return p
+}
+
+func (p *arc[K, V]) add(e *entry[K, V]) {
+	switch {
+	case p.recentGhosts.remove(e.key):
+		p.target = minInt64(p.capacity, p.target+p.delta(e.cost, p.frequentGhosts.cost, p.recentGhosts.cost))
+		p.place(e, arcFrequent)
+	case p.frequentGhosts.remove(e.key):
+		p.target = maxInt64(0, p.target-p.delta(e.cost, p.recentGhosts.cost, p.frequentGhosts.cost))
+		p.place(e, arcFrequent)
+	default:
+		p.trimGhosts(e.cost)
+		p.place(e, arcRecent)
+	}
+}
+
+func (p *arc[K, V]) access(e *entry[K, V]) {
+	p.detach(e)
+	p.place(e, arcFrequent)
+}
+
+func (p *arc[K, V]) remove(e *entry[K, V], evicted bool) {
+	queue := e.queue
+	p.detach(e)
+	e.queue = arcNone
+
+	if !evicted {
+		return
+	}
+
+	if queue == arcRecent {
+		p.recentGhosts.push(e.key, e.cost)
+	} else {
+		p.frequentGhosts.push(e.key, e.cost)
+	}
+}
+
+func (p *arc[K, V]) victim(keep *entry[K, V]) *entry[K, V] {
+	first, second := p.frequent, p.recent
+	if p.recentCost > 0 && (p.recentCost > p.target || p.frequentCost == 0) {
+		first, second = p.recent, p.frequent
+	}
+
+	if e := lastExcept(first, keep); e != nil {
+		return e
+	}
+
+	return lastExcept(second, keep)
+}
+
+func (p *arc[K, V]) reset() {
+	p.target = 0
+	p.recent = linkedlist.NewDoubly[*entry[K, V]](false)
+	p.frequent = linkedlist.NewDoubly[*entry[K, V]](false)
+	p.recentCost, p.frequentCost = 0, 0
+	p.recentGhosts = newGhostList[K]()
+	p.frequentGhosts = newGhostList[K]()
+}
+
+// delta is how much the target moves on a ghost hit,
+// the smaller the ghost list that was hit, the bigger the move
+func (p *arc[K, V]) delta(cost, otherGhostsCost, hitGhostsCost int64) int64 {
+	if hitGhostsCost > 0 && otherGhostsCost > hitGhostsCost {
+		return cost * otherGhostsCost / hitGhostsCost
+	}
+
+	return cost
+}
+
+func (p *arc[K, V]) place(e *entry[K, V], queue arcQueue) {
+	e.queue = queue
+	e.charged = e.cost
+
+	if queue == arcRecent {
+		e.elem = p.recent.PushFront(e)
+		p.recentCost += e.cost
+	} else {
+		e.elem = p.frequent.PushFront(e)
+		p.frequentCost += e.cost
+	}
+}
+
+func (p *arc[K, V]) detach(e *entry[K, V]) {
+	switch e.queue {
+	case arcRecent:
+		p.recent.Remove(e.elem)
+		p.recentCost -= e.charged
+	case arcFrequent:
+		p.frequent.Remove(e.elem)
+		p.frequentCost -= e.charged
+	}
+
+	e.elem = nil
+}
+
+// trimGhosts makes room for a new key of the given cost, so that T1 with B1
+// stay within capacity and all four lists stay within twice the capacity
+func (p *arc[K, V]) trimGhosts(cost int64) {
+	for p.recentCost+p.recentGhosts.cost+cost > p.capacity {
+		if !p.recentGhosts.dropOldest() {
+			break
+		}
+	}
+
+	for p.recentCost+p.frequentCost+p.recentGhosts.cost+p.frequentGhosts.cost+cost > 2*p.capacity {
+		if !p.frequentGhosts.dropOldest() {
+			break
+		}
+	}
+}
+
+func minInt64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+func maxInt64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+
+	return b
+}
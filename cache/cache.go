@@ -0,0 +1,401 @@
+package cache
+
+import (
+	"github.com/denismitr/gds/internal/utils"
+	"github.com/denismitr/gds/linkedlist"
+	"github.com/pkg/errors"
+	"time"
+)
+
+var ErrInvalidCapacity = errors.New("capacity must be greater than 0")
+var ErrInvalidPolicy = errors.New("unknown eviction policy")
+var ErrInvalidCost = errors.New("cost must be between 1 and the cache capacity")
+
+// Policy decides which entry gets evicted when the cache is over capacity
+type Policy int8
+
+const (
+	// LRU evicts the least recently used entry
+	LRU Policy = iota
+	// LFU evicts the least frequently used entry,
+	// the least recently used one among entries with equal frequency
+	LFU
+	// ARC is the adaptive replacement cache balancing between recency and frequency
+	ARC
+)
+
+// EvictionReason tells the eviction callback why the entry left the cache
+type EvictionReason int8
+
+const (
+	// Capacity - the entry was evicted to make room for other entries
+	Capacity EvictionReason = iota
+	// Expired - the entry outlived its TTL
+	Expired
+)
+
+func (r EvictionReason) String() string {
+	switch r {
+	case Capacity:
+		return "capacity"
+	case Expired:
+		return "expired"
+	default:
+		return "unknown"
+	}
+}
+
+// EvictionCallback is called after the cache lock is released,
+// so it is allowed to use the cache
+type EvictionCallback[K comparable, V any] func(key K, value V, reason EvictionReason)
+
+type Stats struct {
+	Hits        uint64
+	Misses      uint64
+	Evictions   uint64
+	Expirations uint64
+}
+
+// HitRatio is the share of Get calls that found the key
+func (s Stats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+
+	return float64(s.Hits) / float64(total)
+}
+
+type options struct {
+	useMutex bool
+	ttl      time.Duration
+	now      func() time.Time
+}
+
+type OptionFunc func(*options)
+
+// WithMutex makes the cache safe for concurrent use
+func WithMutex() OptionFunc {
+	return func(o *options) {
+		o.useMutex = true
+	}
+}
+
+// WithTTL sets the default time to live of entries added with Set
+func WithTTL(ttl time.Duration) OptionFunc {
+	return func(o *options) {
+		o.ttl = ttl
+	}
+}
+
+// WithClock replaces time.Now, mostly useful in tests
+func WithClock(now func() time.Time) OptionFunc {
+	return func(o *options) {
+		o.now = now
+	}
+}
+
+type entry[K comparable, V any] struct {
+	key       K
+	value     V
+	cost      int64
+	expiresAt time.Time
+
+	// bookkeeping of the eviction policies
+	elem    *linkedlist.Element[*entry[K, V]]
+	bucket  *linkedlist.Element[*frequencyBucket[K, V]]
+	queue   arcQueue
+	charged int64
+}
+
+func (e *entry[K, V]) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// policy keeps track of entries in the order of eviction
+type policy[K comparable, V any] interface {
+	add(e *entry[K, V])
+	access(e *entry[K, V])
+	// remove forgets the entry, evicted is true when it was chosen by victim
+	remove(e *entry[K, V], evicted bool)
+	// victim returns the next entry to evict other than keep
+	victim(keep *entry[K, V]) *entry[K, V]
+	reset()
+}
+
+type eviction[K comparable, V any] struct {
+	key    K
+	value  V
+	reason EvictionReason
+}
+
+// Cache maps keys to values keeping the total cost of the entries within capacity.
+// Every entry added with Set costs 1, so by default the capacity is the number of entries.
+type Cache[K comparable, V any] struct {
+	locker   utils.Locker
+	items    map[K]*entry[K, V]
+	policy   policy[K, V]
+	capacity int64
+	cost     int64
+	ttl      time.Duration
+	now      func() time.Time
+	onEvict  EvictionCallback[K, V]
+	stats    Stats
+}
+
+func New[K comparable, V any](p Policy, capacity int64, ofs ...OptionFunc) (*Cache[K, V], error) {
+	if capacity < 1 {
+		return nil, ErrInvalidCapacity
+	}
+
+	opts := options{now: time.Now}
+	for _, opt := range ofs {
+		opt(&opts)
+	}
+
+	c := Cache[K, V]{
+		items:    make(map[K]*entry[K, V]),
+		capacity: capacity,
+		ttl:      opts.ttl,
+		now:      opts.now,
+	}
+
+	switch p {
+	case LRU:
+		c.policy = newLRU[K, V]()
+	case LFU:
+		c.policy = newLFU[K, V]()
+	case ARC:
+		c.policy = newARC[K, V](capacity)
+	default:
+		return nil, errors.Wrapf(ErrInvalidPolicy, "policy %d", p)
+	}
+
+	if opts.useMutex {
+		c.locker = &utils.MutexLock{}
+	} else {
+		c.locker = utils.NullLocker{}
+	}
+
+	return &c, nil
+}
+
+func NewLRU[K comparable, V any](capacity int64, ofs ...OptionFunc) (*Cache[K, V], error) {
+	return New[K, V](LRU, capacity, ofs...)
+}
+
+func NewLFU[K comparable, V any](capacity int64, ofs ...OptionFunc) (*Cache[K, V], error) {
+	return New[K, V](LFU, capacity, ofs...)
+}
+
+func NewARC[K comparable, V any](capacity int64, ofs ...OptionFunc) (*Cache[K, V], error) {
+	return New[K, V](ARC, capacity, ofs...)
+}
+
+// OnEviction registers a callback for entries evicted by capacity or expiration,
+// entries removed with Delete or Purge are not reported
+func (c *Cache[K, V]) OnEviction(fn EvictionCallback[K, V]) {
+	c.locker.WriteLock()
+	defer c.locker.WriteUnlock()
+
+	c.onEvict = fn
+}
+
+// Get returns the value stored by key and marks it as used
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.locker.WriteLock()
+
+	var evicted []eviction[K, V]
+	e, ok := c.items[key]
+	if ok && e.expired(c.now()) {
+		evicted = append(evicted, c.expire(e))
+		ok = false
+	}
+
+	var value V
+	if ok {
+		c.stats.Hits++
+		c.policy.access(e)
+		value = e.value
+	} else {
+		c.stats.Misses++
+	}
+
+	notify := c.onEvict
+	c.locker.WriteUnlock()
+
+	c.notify(notify, evicted)
+	return value, ok
+}
+
+// Peek returns the value stored by key without affecting the eviction order and stats
+func (c *Cache[K, V]) Peek(key K) (V, bool) {
+	c.locker.ReadLock()
+	defer c.locker.ReadUnlock()
+
+	e, ok := c.items[key]
+	if !ok || e.expired(c.now()) {
+		var zero V
+		return zero, false
+	}
+
+	return e.value, true
+}
+
+// Contains reports whether a live entry exists without affecting the eviction order and stats
+func (c *Cache[K, V]) Contains(key K) bool {
+	_, ok := c.Peek(key)
+	return ok
+}
+
+// Set stores value by key with cost 1 and the default TTL
+func (c *Cache[K, V]) Set(key K, value V) {
+	// cost 1 always fits since capacity is at least 1
+	_ = c.SetWith(key, value, 1, 0)
+}
+
+// SetWith stores value by key with the given cost and TTL,
+// zero TTL means the default one configured with WithTTL
+func (c *Cache[K, V]) SetWith(key K, value V, cost int64, ttl time.Duration) error {
+	if cost < 1 || cost > c.capacity {
+		return errors.Wrapf(ErrInvalidCost, "cost %d, capacity %d", cost, c.capacity)
+	}
+
+	if ttl == 0 {
+		ttl = c.ttl
+	}
+
+	c.locker.WriteLock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = c.now().Add(ttl)
+	}
+
+	if e, ok := c.items[key]; ok {
+		e.value = value
+		e.expiresAt = expiresAt
+		c.cost += cost - e.cost
+		e.cost = cost
+		c.policy.access(e)
+	} else {
+		e = &entry[K, V]{key: key, value: value, cost: cost, expiresAt: expiresAt}
+		c.items[key] = e
+		c.cost += cost
+		c.policy.add(e)
+	}
+
+	evicted := c.evict(c.items[key])
+	notify := c.onEvict
+	c.locker.WriteUnlock()
+
+	c.notify(notify, evicted)
+	return nil
+}
+
+// Delete removes the entry by key and reports whether it was present
+func (c *Cache[K, V]) Delete(key K) bool {
+	c.locker.WriteLock()
+	defer c.locker.WriteUnlock()
+
+	e, ok := c.items[key]
+	if !ok {
+		return false
+	}
+
+	c.remove(e, false)
+	return true
+}
+
+// DeleteExpired removes all the expired entries and returns their number
+func (c *Cache[K, V]) DeleteExpired() int {
+	c.locker.WriteLock()
+
+	var evicted []eviction[K, V]
+	now := c.now()
+	for _, e := range c.items {
+		if e.expired(now) {
+			evicted = append(evicted, c.expire(e))
+		}
+	}
+
+	notify := c.onEvict
+	c.locker.WriteUnlock()
+
+	c.notify(notify, evicted)
+	return len(evicted)
+}
+
+// Purge removes all the entries, stats are kept
+func (c *Cache[K, V]) Purge() {
+	c.locker.WriteLock()
+	defer c.locker.WriteUnlock()
+
+	c.items = make(map[K]*entry[K, V])
+	c.cost = 0
+	c.policy.reset()
+}
+
+// Len is the number of entries including the expired ones that were not removed yet
+func (c *Cache[K, V]) Len() int {
+	c.locker.ReadLock()
+	defer c.locker.ReadUnlock()
+	return len(c.items)
+}
+
+// Cost is the total cost of the entries
+func (c *Cache[K, V]) Cost() int64 {
+	c.locker.ReadLock()
+	defer c.locker.ReadUnlock()
+	return c.cost
+}
+
+func (c *Cache[K, V]) Capacity() int64 {
+	return c.capacity
+}
+
+func (c *Cache[K, V]) Stats() Stats {
+	c.locker.ReadLock()
+	defer c.locker.ReadUnlock()
+	return c.stats
+}
+
+// evict removes entries until the cost fits into capacity,
+// keep is the entry that has just been set and must stay
+func (c *Cache[K, V]) evict(keep *entry[K, V]) []eviction[K, V] {
+	var evicted []eviction[K, V]
+	for c.cost > c.capacity {
+		e := c.policy.victim(keep)
+		if e == nil {
+			break
+		}
+
+		c.remove(e, true)
+		c.stats.Evictions++
+		evicted = append(evicted, eviction[K, V]{key: e.key, value: e.value, reason: Capacity})
+	}
+
+	return evicted
+}
+
+func (c *Cache[K, V]) expire(e *entry[K, V]) eviction[K, V] {
+	c.remove(e, false)
+	c.stats.Expirations++
+	return eviction[K, V]{key: e.key, value: e.value, reason: Expired}
+}
+
+func (c *Cache[K, V]) remove(e *entry[K, V], evicted bool) {
+	delete(c.items, e.key)
+	c.cost -= e.cost
+	c.policy.remove(e, evicted)
+}
+
+func (c *Cache[K, V]) notify(fn EvictionCallback[K, V], evicted []eviction[K, V]) {
+	if fn == nil {
+		return
+	}
+
+	for _, ev := range evicted {
+		fn(ev.key, ev.value, ev.reason)
+	}
+}
@@ -0,0 +1,203 @@
+package cache_test
+
+import (
+	"fmt"
+	"github.com/denismitr/gds/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+var policies = []cache.Policy{cache.LRU, cache.LFU, cache.ARC}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestNew(t *testing.T) {
+	_, err := cache.New[string, int](cache.LRU, 0)
+	assert.ErrorIs(t, err, cache.ErrInvalidCapacity)
+
+	_, err = cache.New[string, int](cache.Policy(42), 10)
+	assert.ErrorIs(t, err, cache.ErrInvalidPolicy)
+}
+
+func TestCache_Basics(t *testing.T) {
+	for _, p := range policies {
+		t.Run(fmt.Sprintf("policy %d", p), func(t *testing.T) {
+			c, err := cache.New[string, int](p, 3)
+			require.NoError(t, err)
+
+			_, ok := c.Get("a")
+			assert.False(t, ok)
+
+			c.Set("a", 1)
+			c.Set("b", 2)
+
+			v, ok := c.Get("a")
+			require.True(t, ok)
+			assert.Equal(t, 1, v)
+
+			c.Set("a", 10)
+			v, ok = c.Peek("a")
+			require.True(t, ok)
+			assert.Equal(t, 10, v)
+			assert.Equal(t, 2, c.Len())
+
+			assert.True(t, c.Delete("a"))
+			assert.False(t, c.Delete("a"))
+			assert.False(t, c.Contains("a"))
+			assert.True(t, c.Contains("b"))
+
+			c.Set("c", 3)
+			c.Set("d", 4)
+			c.Set("e", 5)
+			assert.Equal(t, 3, c.Len())
+			assert.Equal(t, int64(3), c.Cost())
+
+			c.Purge()
+			assert.Equal(t, 0, c.Len())
+			assert.Equal(t, int64(0), c.Cost())
+
+			stats := c.Stats()
+			assert.Equal(t, uint64(1), stats.Hits)
+			assert.Equal(t, uint64(1), stats.Misses)
+			assert.Equal(t, uint64(1), stats.Evictions)
+			assert.Equal(t, 0.5, stats.HitRatio())
+		})
+	}
+}
+
+func TestCache_Cost(t *testing.T) {
+	for _, p := range policies {
+		t.Run(fmt.Sprintf("policy %d", p), func(t *testing.T) {
+			c, err := cache.New[string, []byte](p, 100)
+			require.NoError(t, err)
+
+			assert.ErrorIs(t, c.SetWith("huge", nil, 101, 0), cache.ErrInvalidCost)
+			assert.ErrorIs(t, c.SetWith("free", nil, 0, 0), cache.ErrInvalidCost)
+
+			require.NoError(t, c.SetWith("a", make([]byte, 40), 40, 0))
+			require.NoError(t, c.SetWith("b", make([]byte, 40), 40, 0))
+			assert.Equal(t, int64(80), c.Cost())
+
+			require.NoError(t, c.SetWith("c", make([]byte, 50), 50, 0))
+			assert.Equal(t, 2, c.Len())
+			assert.LessOrEqual(t, c.Cost(), c.Capacity())
+			assert.True(t, c.Contains("c"), "the entry that was just set must stay")
+
+			// growing an existing entry evicts others but never the entry itself
+			require.NoError(t, c.SetWith("c", make([]byte, 100), 100, 0))
+			assert.Equal(t, 1, c.Len())
+			assert.Equal(t, int64(100), c.Cost())
+			assert.True(t, c.Contains("c"))
+		})
+	}
+}
+
+func TestCache_TTL(t *testing.T) {
+	for _, p := range policies {
+		t.Run(fmt.Sprintf("policy %d", p), func(t *testing.T) {
+			clock := &fakeClock{now: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)}
+			c, err := cache.New[string, int](p, 10, cache.WithTTL(time.Minute), cache.WithClock(clock.Now))
+			require.NoError(t, err)
+
+			var expired []string
+			c.OnEviction(func(key string, _ int, reason cache.EvictionReason) {
+				assert.Equal(t, cache.Expired, reason)
+				expired = append(expired, key)
+			})
+
+			c.Set("default", 1)
+			require.NoError(t, c.SetWith("short", 2, 1, time.Second))
+			require.NoError(t, c.SetWith("long", 3, 1, time.Hour))
+
+			clock.Advance(2 * time.Second)
+			_, ok := c.Get("short")
+			assert.False(t, ok)
+			assert.False(t, c.Contains("short"))
+			assert.True(t, c.Contains("default"))
+
+			clock.Advance(time.Minute)
+			assert.False(t, c.Contains("default"))
+			assert.Equal(t, 2, c.Len(), "expired entry is still stored until removed")
+
+			assert.Equal(t, 1, c.DeleteExpired())
+			assert.Equal(t, 1, c.Len())
+			assert.Equal(t, []string{"short", "default"}, expired)
+
+			v, ok := c.Get("long")
+			require.True(t, ok)
+			assert.Equal(t, 3, v)
+			assert.Equal(t, uint64(2), c.Stats().Expirations)
+		})
+	}
+}
+
+func TestCache_EvictionCallback(t *testing.T) {
+	c, err := cache.NewLRU[int, string](2, cache.WithMutex())
+	require.NoError(t, err)
+
+	var evicted []int
+	c.OnEviction(func(key int, value string, reason cache.EvictionReason) {
+		assert.Equal(t, cache.Capacity, reason)
+		assert.Equal(t, fmt.Sprint(key), value)
+		// the callback is called without the lock held
+		assert.False(t, c.Contains(key))
+		evicted = append(evicted, key)
+	})
+
+	for i := 0; i < 5; i++ {
+		c.Set(i, fmt.Sprint(i))
+	}
+
+	c.Delete(4)
+	c.Purge()
+
+	assert.Equal(t, []int{0, 1, 2}, evicted)
+}
+
+func TestCache_Concurrency(t *testing.T) {
+	for _, p := range policies {
+		t.Run(fmt.Sprintf("policy %d", p), func(t *testing.T) {
+			c, err := cache.New[int, int](p, 64, cache.WithMutex())
+			require.NoError(t, err)
+
+			var wg sync.WaitGroup
+			for w := 0; w < 8; w++ {
+				wg.Add(1)
+				go func(w int) {
+					defer wg.Done()
+					for i := 0; i < 2000; i++ {
+						key := (w * i) % 200
+						if v, ok := c.Get(key); ok {
+							assert.Equal(t, key*2, v)
+						} else {
+							c.Set(key, key*2)
+						}
+						if i%100 == 0 {
+							c.Delete(key)
+						}
+					}
+				}(w)
+			}
+
+			wg.Wait()
+			assert.LessOrEqual(t, c.Len(), 64)
+			assert.LessOrEqual(t, c.Cost(), int64(64))
+
+			stats := c.Stats()
+			assert.Equal(t, uint64(8*2000), stats.Hits+stats.Misses)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package cache
+
+import "github.com/denismitr/gds/linkedlist"
+
+// frequencyBucket holds entries that were used the same number of times,
+// ordered from the most to the least recently used
+type frequencyBucket[K comparable, V any] struct {
+	frequency uint64
+	entries   *linkedlist.DoublyLinkedList[*entry[K, V]]
+}
+
+// lfu is the O(1) LFU: buckets are kept in ascending order of frequency,
+// so the victim is always the last entry of the first bucket
+type lfu[K comparable, V any] struct {
+	buckets *linkedlist.DoublyLinkedList[*frequencyBucket[K, V]]
+}
+
+func newLFU[K comparable, V any]() *lfu[K, V] {
+	return &lfu[K, V]{buckets: linkedlist.NewDoubly[*frequencyBucket[K, V]](false)}
+}
+
+func (p *lfu[K, V]) add(e *entry[K, V]) {
+	first := p.buckets.Front()
+	if first == nil || first.Value().frequency != 1 {
+		first = p.buckets.PushFront(p.newBucket(1))
+	}
+
+	p.place(e, first)
+}
+
+func (p *lfu[K, V]) access(e *entry[K, V]) {
+	curr := e.bucket
+	frequency := curr.Value().frequency + 1
+
+	next := curr.Next()
+	if next == nil || next.Value().frequency != frequency {
+		next = p.buckets.InsertAfter(p.newBucket(frequency), curr)
+	}
+
+	p.detach(e)
+	p.place(e, next)
+}
+
+func (p *lfu[K, V]) remove(e *entry[K, V], _ bool) {
+	p.detach(e)
+	e.elem, e.bucket = nil, nil
+}
+
+func (p *lfu[K, V]) victim(keep *entry[K, V]) *entry[K, V] {
+	// keep can be the only entry of the first bucket, then the victim is in the second one
+	for b := p.buckets.Front(); b != nil; b = b.Next() {
+		if e := lastExcept(b.Value().entries, keep); e != nil {
+			return e
+		}
+	}
+
+	return nil
+}
+
+func (p *lfu[K, V]) reset() {
+	p.buckets = linkedlist.NewDoubly[*frequencyBucket[K, V]](false)
+}
+
+func (p *lfu[K, V]) newBucket(frequency uint64) *frequencyBucket[K, V] {
+	return &frequencyBucket[K, V]{
+		frequency: frequency,
+		entries:   linkedlist.NewDoubly[*entry[K, V]](false),
+	}
+}
+
+func (p *lfu[K, V]) place(e *entry[K, V], bucket *linkedlist.Element[*frequencyBucket[K, V]]) {
+	e.elem = bucket.Value().entries.PushFront(e)
+	e.bucket = bucket
+}
+
+// detach removes the entry from its bucket and drops the bucket once it is empty
+func (p *lfu[K, V]) detach(e *entry[K, V]) {
+	entries := e.bucket.Value().entries
+	entries.Remove(e.elem)
+	if entries.Empty() {
+		p.buckets.Remove(e.bucket)
+	}
+}
@@ -0,0 +1,47 @@
+package cache
+
+import "github.com/denismitr/gds/linkedlist"
+
+// lru keeps entries ordered from the most to the least recently used
+type lru[K comparable, V any] struct {
+	entries *linkedlist.DoublyLinkedList[*entry[K, V]]
+}
+
+func newLRU[K comparable, V any]() *lru[K, V] {
+	return &lru[K, V]{entries: linkedlist.NewDoubly[*entry[K, V]](false)}
+}
+
+func (p *lru[K, V]) add(e *entry[K, V]) {
+	e.elem = p.entries.PushFront(e)
+}
+
+func (p *lru[K, V]) access(e *entry[K, V]) {
+	p.entries.MoveToFront(e.elem)
+}
+
+func (p *lru[K, V]) remove(e *entry[K, V], _ bool) {
+	p.entries.Remove(e.elem)
+	e.elem = nil
+}
+
+func (p *lru[K, V]) victim(keep *entry[K, V]) *entry[K, V] {
+	return lastExcept(p.entries, keep)
+}
+
+func (p *lru[K, V]) reset() {
+	p.entries = linkedlist.NewDoubly[*entry[K, V]](false)
+}
+
+// lastExcept returns the last entry of the list skipping keep
+func lastExcept[K comparable, V any](entries *linkedlist.DoublyLinkedList[*entry[K, V]], keep *entry[K, V]) *entry[K, V] {
+	last := entries.Back()
+	if last != nil && last.Value() == keep {
+		last = last.Prev()
+	}
+
+	if last == nil {
+		return nil
+	}
+
+	return last.Value()
+}
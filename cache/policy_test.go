@@ -0,0 +1,117 @@
+package cache_test
+
+import (
+	"github.com/denismitr/gds/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func keys(c *cache.Cache[string, int], candidates ...string) []string {
+	var result []string
+	for _, k := range candidates {
+		if c.Contains(k) {
+			result = append(result, k)
+		}
+	}
+	return result
+}
+
+func TestLRU_EvictionOrder(t *testing.T) {
+	c, err := cache.NewLRU[string, int](3)
+	require.NoError(t, err)
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("c", 3)
+
+	// a becomes the most recently used, so b is the victim
+	c.Get("a")
+	c.Set("d", 4)
+	assert.Equal(t, []string{"a", "c", "d"}, keys(c, "a", "b", "c", "d"))
+
+	// updating counts as usage too
+	c.Set("c", 30)
+	c.Set("e", 5)
+	assert.Equal(t, []string{"c", "d", "e"}, keys(c, "a", "b", "c", "d", "e"))
+}
+
+func TestLFU_EvictionOrder(t *testing.T) {
+	c, err := cache.NewLFU[string, int](3)
+	require.NoError(t, err)
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("c", 3)
+
+	for i := 0; i < 3; i++ {
+		c.Get("a")
+	}
+	c.Get("b")
+	c.Get("c")
+
+	// b and c were used twice, c more recently, so b is the victim
+	c.Set("d", 4)
+	assert.Equal(t, []string{"a", "c", "d"}, keys(c, "a", "b", "c", "d"))
+
+	// d is the least frequent, but a new entry never evicts itself
+	c.Set("e", 5)
+	assert.Equal(t, []string{"a", "c", "e"}, keys(c, "a", "b", "c", "d", "e"))
+}
+
+func TestARC_ScanResistance(t *testing.T) {
+	scan := func(c *cache.Cache[string, int]) int {
+		hot := []string{"h1", "h2", "h3", "h4"}
+		for _, k := range hot {
+			c.Set(k, 1)
+		}
+
+		for round := 0; round < 10; round++ {
+			for _, k := range hot {
+				if _, ok := c.Get(k); !ok {
+					c.Set(k, 1)
+				}
+			}
+
+			// a long scan of keys used only once
+			for i := 0; i < 20; i++ {
+				c.Set(string(rune('a'+round))+string(rune('a'+i)), 1)
+			}
+		}
+
+		hits := 0
+		for _, k := range hot {
+			if c.Contains(k) {
+				hits++
+			}
+		}
+		return hits
+	}
+
+	lru, err := cache.NewLRU[string, int](8)
+	require.NoError(t, err)
+	arc, err := cache.NewARC[string, int](8)
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, scan(lru), "LRU is flushed by the scan")
+	assert.Equal(t, 4, scan(arc), "ARC keeps the frequently used keys")
+	assert.Greater(t, arc.Stats().HitRatio(), lru.Stats().HitRatio())
+}
+
+func TestARC_GhostHitsAdaptTarget(t *testing.T) {
+	c, err := cache.NewARC[string, int](2)
+	require.NoError(t, err)
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("c", 3) // a goes to the recent ghost list
+
+	assert.False(t, c.Contains("a"))
+
+	// a ghost hit brings a straight into the frequent queue
+	c.Set("a", 1)
+	c.Set("d", 4)
+	c.Set("e", 5)
+	assert.True(t, c.Contains("a"))
+	assert.Equal(t, 2, c.Len())
+}
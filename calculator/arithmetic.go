@@ -0,0 +1,505 @@
+package calculator
+
+import (
+	"github.com/pkg/errors"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// arithmetic implements the operations of a numeric mode,
+// operands are always converted to the mode before they get here,
+// convert leaves booleans and quantities as they are. The integer modes fail with ErrOverflow when a result
+// does not fit in their width, and the float modes when a result of finite operands is infinite.
+type arithmetic interface {
+	mode() Mode
+	parse(literal string) (Value, bool)
+	convert(v Value) Value
+	add(a, b Value) (Value, error)
+	sub(a, b Value) (Value, error)
+	mul(a, b Value) (Value, error)
+	div(a, b Value) (Value, error)
+	neg(a Value) (Value, error)
+	pow(a, b Value) (Value, error)
+	// mod and floorDiv round the quotient down, so a == b*floorDiv(a, b) + mod(a, b)
+	// and the result of mod has the sign of b
+	mod(a, b Value) (Value, error)
+	floorDiv(a, b Value) (Value, error)
+	// cmp returns -1, 0 or +1 when a is less than, equal to or greater than b
+	cmp(a, b Value) int
+	// bitwise applies &, |, ~ (exclusive or), << or >>, the operands must have integer values
+	bitwise(op string, a, b Value) (Value, error)
+	complement(a Value) (Value, error)
+	format(v Value, digits int) string
+}
+
+type floatArithmetic struct{}
+
+func (floatArithmetic) mode() Mode {
+	return ModeFloat
+}
+
+func (floatArithmetic) parse(literal string) (Value, bool) {
+	if i, ok := parseInteger(literal); ok {
+		f, _ := new(big.Float).SetInt(i).Float64()
+		return Float(f), true
+	}
+
+	f, err := strconv.ParseFloat(literal, 64)
+	if err != nil {
+		return Value{}, false
+	}
+
+	return Float(f), true
+}
+
+func (floatArithmetic) convert(v Value) Value {
+	if v.mode == ModeFloat || v.mode < 0 {
+		return v
+	}
+
+	return Float(v.Float64())
+}
+
+func (floatArithmetic) add(a, b Value) (Value, error) {
+	return finiteFloat(a.f+b.f, a, b)
+}
+
+func (floatArithmetic) sub(a, b Value) (Value, error) {
+	return finiteFloat(a.f-b.f, a, b)
+}
+
+func (floatArithmetic) mul(a, b Value) (Value, error) {
+	return finiteFloat(a.f*b.f, a, b)
+}
+
+func (floatArithmetic) div(a, b Value) (Value, error) {
+	if b.f == 0 {
+		return Value{}, ErrDivisionByZero
+	}
+
+	return finiteFloat(a.f/b.f, a, b)
+}
+
+func (floatArithmetic) neg(a Value) (Value, error) {
+	return Float(-a.f), nil
+}
+
+func (floatArithmetic) pow(a, b Value) (Value, error) {
+	if a.f == 0 && b.f < 0 {
+		return Value{}, ErrDivisionByZero
+	}
+
+	f := math.Pow(a.f, b.f)
+	if math.IsNaN(f) {
+		return Value{}, errors.Wrapf(ErrDomain, "%s ^ %s", a, b)
+	}
+
+	return finiteFloat(f, a, b)
+}
+
+func (floatArithmetic) mod(a, b Value) (Value, error) {
+	if b.f == 0 {
+		return Value{}, ErrDivisionByZero
+	}
+
+	r := math.Mod(a.f, b.f)
+	if r != 0 && (r < 0) != (b.f < 0) {
+		r += b.f
+	}
+
+	return Float(r), nil
+}
+
+func (floatArithmetic) floorDiv(a, b Value) (Value, error) {
+	if b.f == 0 {
+		return Value{}, ErrDivisionByZero
+	}
+
+	return finiteFloat(math.Floor(a.f/b.f), a, b)
+}
+
+// finiteFloat fails with ErrOverflow when the result of finite operands is infinite,
+// infinities that come from the operands like in inf + 1 stay as they are
+func finiteFloat(f float64, a, b Value) (Value, error) {
+	if math.IsInf(f, 0) && !math.IsInf(a.f, 0) && !math.IsInf(b.f, 0) {
+		return Value{}, ErrOverflow
+	}
+
+	return Float(f), nil
+}
+
+func (floatArithmetic) cmp(a, b Value) int {
+	switch {
+	case a.f < b.f:
+		return -1
+	case a.f > b.f:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (floatArithmetic) bitwise(op string, a, b Value) (Value, error) {
+	return bigBitwise(floatArithmetic{}, op, a, b)
+}
+
+func (floatArithmetic) complement(a Value) (Value, error) {
+	return bigComplement(floatArithmetic{}, a)
+}
+
+func (floatArithmetic) format(v Value, digits int) string {
+	if digits >= 0 && !math.IsInf(v.f, 0) && !math.IsNaN(v.f) {
+		return strconv.FormatFloat(v.f, 'f', digits, 64)
+	}
+
+	return strconv.FormatFloat(v.f, 'g', -1, 64)
+}
+
+type ratArithmetic struct{}
+
+func (ratArithmetic) mode() Mode {
+	return ModeRational
+}
+
+func (ratArithmetic) parse(literal string) (Value, bool) {
+	if i, ok := parseInteger(literal); ok {
+		return Rat(new(big.Rat).SetInt(i)), true
+	}
+
+	r, ok := new(big.Rat).SetString(literal)
+	if !ok {
+		return Value{}, false
+	}
+
+	return Rat(r), true
+}
+
+func (ratArithmetic) convert(v Value) Value {
+	if v.mode == ModeRational || v.mode < 0 {
+		return v
+	}
+
+	if r := v.Rat(); r != nil {
+		return Rat(r)
+	}
+
+	// infinities and NaN have no exact representation, they stay floats
+	return Float(v.Float64())
+}
+
+func (ratArithmetic) add(a, b Value) (Value, error) {
+	return Rat(new(big.Rat).Add(a.r, b.r)), nil
+}
+
+func (ratArithmetic) sub(a, b Value) (Value, error) {
+	return Rat(new(big.Rat).Sub(a.r, b.r)), nil
+}
+
+func (ratArithmetic) mul(a, b Value) (Value, error) {
+	return Rat(new(big.Rat).Mul(a.r, b.r)), nil
+}
+
+func (ratArithmetic) div(a, b Value) (Value, error) {
+	if b.r.Sign() == 0 {
+		return Value{}, ErrDivisionByZero
+	}
+
+	return Rat(new(big.Rat).Quo(a.r, b.r)), nil
+}
+
+func (ratArithmetic) neg(a Value) (Value, error) {
+	return Rat(new(big.Rat).Neg(a.r)), nil
+}
+
+// maxExactExponent keeps exact powers like 10^1000000 from growing without bounds,
+// maxExactBits does the same for the powers of large bases like (2^65536)^65536
+const (
+	maxExactExponent = 1 << 16
+	maxExactBits     = 1 << 22
+)
+
+func (ratArithmetic) pow(a, b Value) (Value, error) {
+	if !b.r.IsInt() {
+		return approximatePow(ratArithmetic{}, a, b)
+	}
+
+	if b.r.Num().CmpAbs(big.NewInt(maxExactExponent)) > 0 {
+		return Value{}, errors.Wrapf(ErrOverflow, "exponent %s is too large", b.r.RatString())
+	}
+
+	e := b.r.Num()
+	if e.Sign() < 0 && a.r.Sign() == 0 {
+		return Value{}, ErrDivisionByZero
+	}
+
+	abs := new(big.Int).Abs(e)
+	bits := a.r.Num().BitLen()
+	if a.r.Denom().BitLen() > bits {
+		bits = a.r.Denom().BitLen()
+	}
+	if int64(bits-1)*abs.Int64() > maxExactBits {
+		return Value{}, errors.Wrapf(ErrOverflow, "power %s of a %d bit number is too large", e, bits)
+	}
+
+	num := new(big.Int).Exp(a.r.Num(), abs, nil)
+	den := new(big.Int).Exp(a.r.Denom(), abs, nil)
+	if e.Sign() < 0 {
+		num, den = den, num
+	}
+
+	return Rat(new(big.Rat).SetFrac(num, den)), nil
+}
+
+func (ratArithmetic) mod(a, b Value) (Value, error) {
+	q, err := ratArithmetic{}.floorDiv(a, b)
+	if err != nil {
+		return Value{}, err
+	}
+
+	r := new(big.Rat).Mul(b.r, q.r)
+	return Rat(r.Sub(a.r, r)), nil
+}
+
+func (ratArithmetic) floorDiv(a, b Value) (Value, error) {
+	if b.r.Sign() == 0 {
+		return Value{}, ErrDivisionByZero
+	}
+
+	q := new(big.Rat).Quo(a.r, b.r)
+	// the denominator is always positive, so the euclidean division rounds down
+	return Rat(new(big.Rat).SetInt(new(big.Int).Div(q.Num(), q.Denom()))), nil
+}
+
+func (ratArithmetic) cmp(a, b Value) int {
+	return a.r.Cmp(b.r)
+}
+
+func (ratArithmetic) bitwise(op string, a, b Value) (Value, error) {
+	return bigBitwise(ratArithmetic{}, op, a, b)
+}
+
+func (ratArithmetic) complement(a Value) (Value, error) {
+	return bigComplement(ratArithmetic{}, a)
+}
+
+func (ratArithmetic) format(v Value, digits int) string {
+	if v.mode != ModeRational {
+		return v.String()
+	}
+
+	if digits >= 0 {
+		return v.r.FloatString(digits)
+	}
+
+	return v.r.RatString()
+}
+
+type bigFloatArithmetic struct {
+	precision uint
+}
+
+func (bigFloatArithmetic) mode() Mode {
+	return ModeBigFloat
+}
+
+func (a bigFloatArithmetic) parse(literal string) (Value, bool) {
+	if i, ok := parseInteger(literal); ok {
+		return BigFloat(a.new().SetInt(i)), true
+	}
+
+	f, _, err := big.ParseFloat(literal, 10, a.precision, big.ToNearestEven)
+	if err != nil {
+		return Value{}, false
+	}
+
+	return BigFloat(f), true
+}
+
+func (a bigFloatArithmetic) convert(v Value) Value {
+	if v.mode == ModeBigFloat && v.bigFloat().Prec() == a.precision || v.mode < 0 {
+		return v
+	}
+
+	if f := v.BigFloat(a.precision); f != nil {
+		return BigFloat(f)
+	}
+
+	return v
+}
+
+// add, sub, mul and div reject infinite operands, big.Float panics on Inf - Inf, 0 * Inf and Inf / Inf
+func (a bigFloatArithmetic) add(x, y Value) (Value, error) {
+	if err := infinite(x, y); err != nil {
+		return Value{}, err
+	}
+
+	return finite(a.new().Add(x.bigFloat(), y.bigFloat()))
+}
+
+func (a bigFloatArithmetic) sub(x, y Value) (Value, error) {
+	if err := infinite(x, y); err != nil {
+		return Value{}, err
+	}
+
+	return finite(a.new().Sub(x.bigFloat(), y.bigFloat()))
+}
+
+func (a bigFloatArithmetic) mul(x, y Value) (Value, error) {
+	if err := infinite(x, y); err != nil {
+		return Value{}, err
+	}
+
+	return finite(a.new().Mul(x.bigFloat(), y.bigFloat()))
+}
+
+func (a bigFloatArithmetic) div(x, y Value) (Value, error) {
+	if err := infinite(x, y); err != nil {
+		return Value{}, err
+	}
+
+	if y.bigFloat().Sign() == 0 {
+		return Value{}, ErrDivisionByZero
+	}
+
+	return finite(a.new().Quo(x.bigFloat(), y.bigFloat()))
+}
+
+func (a bigFloatArithmetic) neg(x Value) (Value, error) {
+	return BigFloat(a.new().Neg(x.bigFloat())), nil
+}
+
+func (a bigFloatArithmetic) pow(x, y Value) (Value, error) {
+	if err := infinite(x, y); err != nil {
+		return Value{}, err
+	}
+
+	if !y.bigFloat().IsInt() {
+		return approximatePow(a, x, y)
+	}
+
+	e, acc := y.bigFloat().Int64()
+	if acc != big.Exact || abs(e) > maxExactExponent {
+		return Value{}, errors.Wrapf(ErrOverflow, "exponent %s is too large", y)
+	}
+
+	if e < 0 && x.bigFloat().Sign() == 0 {
+		return Value{}, ErrDivisionByZero
+	}
+
+	result := a.new().SetInt64(1)
+	base := a.new().Set(x.bigFloat())
+	for n := abs(e); n > 0; n >>= 1 {
+		if n&1 == 1 {
+			result.Mul(result, base)
+		}
+		base.Mul(base, base)
+	}
+
+	if e < 0 {
+		result.Quo(a.new().SetInt64(1), result)
+	}
+
+	return finite(result)
+}
+
+func (a bigFloatArithmetic) mod(x, y Value) (Value, error) {
+	q, err := a.floorDiv(x, y)
+	if err != nil {
+		return Value{}, err
+	}
+
+	r := a.new().Mul(y.bigFloat(), q.bigFloat())
+	return BigFloat(r.Sub(x.bigFloat(), r)), nil
+}
+
+func (a bigFloatArithmetic) floorDiv(x, y Value) (Value, error) {
+	if y.bigFloat().Sign() == 0 {
+		return Value{}, ErrDivisionByZero
+	}
+
+	if err := infinite(x, y); err != nil {
+		return Value{}, err
+	}
+
+	q := a.new().Quo(x.bigFloat(), y.bigFloat())
+	if q.IsInf() {
+		return Value{}, ErrOverflow
+	}
+
+	floor, _ := q.Int(nil)
+	if q.Sign() < 0 && !q.IsInt() {
+		floor.Sub(floor, big.NewInt(1))
+	}
+
+	return BigFloat(a.new().SetInt(floor)), nil
+}
+
+func (bigFloatArithmetic) cmp(x, y Value) int {
+	return x.bigFloat().Cmp(y.bigFloat())
+}
+
+func (a bigFloatArithmetic) bitwise(op string, x, y Value) (Value, error) {
+	return bigBitwise(a, op, x, y)
+}
+
+func (a bigFloatArithmetic) complement(x Value) (Value, error) {
+	return bigComplement(a, x)
+}
+
+func (bigFloatArithmetic) format(v Value, digits int) string {
+	if v.mode != ModeBigFloat {
+		return v.String()
+	}
+
+	if digits >= 0 {
+		return v.bigFloat().Text('f', digits)
+	}
+
+	return v.bigFloat().Text('g', -1)
+}
+
+// finite fails with ErrOverflow when the exponent of the result went out of the range of big.Float
+func finite(f *big.Float) (Value, error) {
+	if f.IsInf() {
+		return Value{}, ErrOverflow
+	}
+
+	return BigFloat(f), nil
+}
+
+// infinite fails with ErrOverflow when an operand is an infinity converted from a float
+func infinite(x, y Value) error {
+	if x.bigFloat().IsInf() || y.bigFloat().IsInf() {
+		return errors.Wrapf(ErrOverflow, "%s and %s must be finite", x, y)
+	}
+
+	return nil
+}
+
+func (a bigFloatArithmetic) new() *big.Float {
+	return new(big.Float).SetPrec(a.precision)
+}
+
+// approximatePow computes powers with fractional exponents in float64,
+// exact modes have no closed form for them
+func approximatePow(a arithmetic, x, y Value) (Value, error) {
+	f := math.Pow(x.Float64(), y.Float64())
+	if math.IsNaN(f) {
+		return Value{}, errors.Wrapf(ErrDomain, "%s ^ %s", x, y)
+	}
+
+	if math.IsInf(f, 0) {
+		return Value{}, errors.Wrapf(ErrOverflow, "%s ^ %s", x, y)
+	}
+
+	return a.convert(Float(f)), nil
+}
+
+func abs(n int64) uint64 {
+	if n < 0 {
+		return uint64(-n)
+	}
+
+	return uint64(n)
+}
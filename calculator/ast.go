@@ -0,0 +1,255 @@
+package calculator
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"math/big"
+)
+
+type nodeKind int8
+
+const (
+	numberNode nodeKind = iota
+	variableNode
+	unaryNode
+	binaryNode
+	callNode
+	conditionalNode
+)
+
+// node is an element of the syntax tree, name holds the literal of a number, the variable name,
+// the operator symbol or the function name depending on the kind, folded constants have no literal
+type node struct {
+	kind  nodeKind
+	value Value
+	name  string
+	pos   int
+	args  []*node
+	// unit marks a name in the position of a unit, see token
+	unit bool
+}
+
+// parseTree turns an expression in reverse polish notation into a syntax tree,
+// number nodes keep their literals in name until the tree is bound to a calculator
+func parseTree(queue tokenQueue) (*node, error) {
+	var stack []*node
+
+	for {
+		next, exists := queue.dequeue()
+		if !exists {
+			break
+		}
+
+		switch next.getKind() {
+		case number:
+			stack = append(stack, &node{kind: numberNode, name: next.value, pos: next.pos})
+		case identifier:
+			stack = append(stack, &node{kind: variableNode, name: next.value, pos: next.pos, unit: next.unit})
+		case function:
+			if len(stack) < next.args {
+				return nil, syntaxError(next.pos, "missing arguments for %s", next.value)
+			}
+
+			n := &node{kind: callNode, name: next.value, pos: next.pos, args: popNodes(&stack, next.args)}
+			stack = append(stack, n)
+		case operator:
+			oi, ok := lookupOperator(next)
+			if !ok {
+				return nil, &SyntaxError{Pos: next.pos, Msg: fmt.Sprintf("unknown operator %q", next.value), Err: ErrUnknownToken}
+			}
+
+			if len(stack) < oi.arity() {
+				return nil, syntaxError(next.pos, "missing operand for %s", next.value)
+			}
+
+			kind := binaryNode
+			switch oi.arity() {
+			case 1:
+				kind = unaryNode
+			case 3:
+				kind = conditionalNode
+			}
+
+			n := &node{kind: kind, name: next.value, pos: next.pos, args: popNodes(&stack, oi.arity())}
+			stack = append(stack, n)
+		}
+	}
+
+	switch len(stack) {
+	case 0:
+		return nil, syntaxError(0, "empty expression")
+	case 1:
+		return stack[0], nil
+	default:
+		return nil, syntaxError(stack[1].position(), "missing operator")
+	}
+}
+
+// bind parses the numbers of the tree in the mode of the calculator and checks that the functions exist,
+// folded numbers without literals are converted to the mode
+func (c *Calculator) bind(n *node) error {
+	// the smallest number of a signed integer mode like -128 does not fit without its minus
+	if n.kind == unaryNode && n.name == minus && c.Mode().signed() {
+		arg := n.args[0]
+		if arg.kind == numberNode && arg.name != "" && integerBase(arg.name) == 0 {
+			if v, ok := c.arithmetic.parse(minus + arg.name); ok {
+				*n = node{kind: numberNode, value: v, name: minus + arg.name, pos: n.pos}
+				return nil
+			}
+		}
+	}
+
+	for _, arg := range n.args {
+		if err := c.bind(arg); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case n.kind == numberNode && n.name == "":
+		n.value = c.arithmetic.convert(n.value)
+	case n.kind == numberNode:
+		v, ok := c.arithmetic.parse(n.name)
+		if !ok {
+			// a well formed literal is only rejected when it is out of the range of the mode
+			if end, err := scanNumber(n.name, 0); err == nil && end == len(n.name) {
+				if r, ok := new(big.Rat).SetString(n.name); ok && !r.IsInt() {
+					return errors.Wrapf(ErrDomain, "%s at position %d: number is not an integer", n.name, n.pos)
+				}
+				return errors.Wrapf(ErrOverflow, "%s at position %d: number does not fit in %s", n.name, n.pos, c.Mode())
+			}
+			return syntaxError(n.pos, "invalid number %s", n.name)
+		}
+		n.value = v
+	case n.kind == callNode:
+		if _, err := c.resolve(token{value: n.name, pos: n.pos}, len(n.args)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// position is the position of the leftmost token of the subtree
+func (n *node) position() int {
+	if n.kind == binaryNode || n.kind == conditionalNode {
+		return n.args[0].position()
+	}
+
+	return n.pos
+}
+
+// fold replaces subtrees without variables by their values, calls of registered functions are
+// never folded because they are not known to be pure, subtrees that fail are kept to fail on evaluation.
+// The tree is not modified, the folded parts are new nodes.
+func (c *Calculator) fold(n *node) *node {
+	if len(n.args) == 0 {
+		return n
+	}
+
+	args := make([]*node, len(n.args))
+	for i, arg := range n.args {
+		args[i] = c.fold(arg)
+	}
+
+	return c.foldNode(&node{kind: n.kind, name: n.name, pos: n.pos, args: args})
+}
+
+// foldNode folds a node with folded arguments. A constant condition, or a constant left operand
+// of && and || that decides the result, folds the node even when the rest of it is not constant.
+func (c *Calculator) foldNode(n *node) *node {
+	if short := n.shortCircuit(); short != nil {
+		return short
+	}
+
+	for _, arg := range n.args {
+		if arg.kind != numberNode {
+			return n
+		}
+	}
+
+	if n.kind == conditionalNode || n.kind == callNode && !c.functions[n.name].pure {
+		return n
+	}
+
+	values := make([]Value, len(n.args))
+	for i, arg := range n.args {
+		values[i] = arg.value
+	}
+
+	v, err := c.evaluate(n, values)
+	if err != nil {
+		return n
+	}
+
+	return &node{kind: numberNode, value: v, pos: n.position()}
+}
+
+// shortCircuit returns the subtree that a constant condition or a constant left operand
+// of a logical operator selects, it is nil when the node has to be evaluated
+func (n *node) shortCircuit() *node {
+	first := n.args[0]
+	if first.kind != numberNode || first.value.Type() != BoolType {
+		return nil
+	}
+
+	switch {
+	case n.kind == conditionalNode && first.value.Bool():
+		return n.args[1]
+	case n.kind == conditionalNode:
+		return n.args[2]
+	case n.kind == binaryNode && n.name == and && !first.value.Bool(),
+		n.kind == binaryNode && n.name == or && first.value.Bool():
+		return first
+	default:
+		return nil
+	}
+}
+
+// evaluate applies the operator or the function of the node to the values of its arguments
+func (c *Calculator) evaluate(n *node, values []Value) (Value, error) {
+	switch n.kind {
+	case callNode:
+		f := c.functions[n.name]
+		if err := argumentsError(n.name, n.pos, values); err != nil {
+			return Value{}, err
+		}
+
+		if hasQuantity(values) {
+			return c.callQuantity(n.name, f, values)
+		}
+
+		return f.call(c.arithmetic, values)
+	case unaryNode:
+		oi := unaryOperators[n.name]
+		if err := oi.typeError(n.name, n.pos, values[0], values[0]); err != nil {
+			return Value{}, err
+		}
+
+		if values[0].mode == modeQuantity {
+			return c.unaryQuantity(n.name, values[0])
+		}
+
+		return oi.unary(c.arithmetic, values[0])
+	default:
+		oi := binaryOperators[n.name]
+		for _, v := range values {
+			if err := oi.typeError(n.name, n.pos, values[0], v); err != nil {
+				return Value{}, err
+			}
+		}
+
+		if hasQuantity(values) {
+			return c.binaryQuantity(n.name, values[0], values[1])
+		}
+
+		return oi.binary(c.arithmetic, values[0], values[1])
+	}
+}
+
+func popNodes(stack *[]*node, n int) []*node {
+	args := make([]*node, n)
+	copy(args, (*stack)[len(*stack)-n:])
+	*stack = (*stack)[:len(*stack)-n]
+	return args
+}
@@ -1,10 +1,163 @@
 package calculator
 
+import (
+	"github.com/pkg/errors"
+	"strings"
+)
+
+var ErrInvalidMode = errors.New("invalid mode")
+var ErrInvalidPrecision = errors.New("precision must be greater than 0")
+var ErrInvalidBase = errors.New("base must be 2, 8, 10 or 16")
+
+// DefaultPrecision is the number of mantissa bits used by ModeBigFloat
+const DefaultPrecision uint = 256
+
+type options struct {
+	mode      Mode
+	precision uint
+	digits    int
+	base      int
+}
+
+type OptionFunc func(*options)
+
+// WithMode selects the numeric mode, ModeFloat is used by default
+func WithMode(mode Mode) OptionFunc {
+	return func(o *options) {
+		o.mode = mode
+	}
+}
+
+// WithPrecision sets the number of mantissa bits of ModeBigFloat
+func WithPrecision(bits uint) OptionFunc {
+	return func(o *options) {
+		o.precision = bits
+	}
+}
+
+// WithDigits formats results with exactly n digits after the decimal point,
+// by default floats use the shortest exact representation and rationals are printed as fractions
+func WithDigits(n int) OptionFunc {
+	return func(o *options) {
+		o.digits = n
+	}
+}
+
+// WithBase formats integer results in base 2, 8 or 16 with the prefix of their literals like 0xff,
+// the integer modes show negative numbers in two's complement, other numbers are always decimal
+func WithBase(base int) OptionFunc {
+	return func(o *options) {
+		o.base = base
+	}
+}
+
+// Calculator evaluates expressions in a fixed numeric mode,
+// it is safe for concurrent use once all the functions are registered
+type Calculator struct {
+	arithmetic arithmetic
+	digits     int
+	base       int
+	functions  map[string]callable
+	constants  map[string]Value
+	units      map[string]Value
+}
+
+var defaultCalculator, _ = New()
+
+func New(ofs ...OptionFunc) (*Calculator, error) {
+	opts := options{mode: ModeFloat, precision: DefaultPrecision, digits: -1, base: 10}
+	for _, opt := range ofs {
+		opt(&opts)
+	}
+
+	if opts.precision == 0 {
+		return nil, ErrInvalidPrecision
+	}
+
+	if _, ok := basePrefixes[opts.base]; !ok && opts.base != 10 {
+		return nil, errors.Wrapf(ErrInvalidBase, "%d", opts.base)
+	}
+
+	c := Calculator{digits: opts.digits, base: opts.base}
+	switch opts.mode {
+	case ModeFloat:
+		c.arithmetic = floatArithmetic{}
+	case ModeRational:
+		c.arithmetic = ratArithmetic{}
+	case ModeBigFloat:
+		c.arithmetic = bigFloatArithmetic{precision: opts.precision}
+	case ModeInt8, ModeInt16, ModeInt32, ModeInt64, ModeUint8, ModeUint16, ModeUint32, ModeUint64:
+		c.arithmetic = newIntArithmetic(opts.mode)
+	default:
+		return nil, errors.Wrapf(ErrInvalidMode, "%d", opts.mode)
+	}
+
+	c.functions = make(map[string]callable, len(builtins))
+	for name, f := range builtins {
+		f.pure = true
+		c.functions[name] = f
+	}
+
+	c.constants = make(map[string]Value, len(constants)+2)
+	for name, literal := range constants {
+		// the integer modes have no pi, the exact value makes the lookup fail with ErrDomain
+		v, ok := c.arithmetic.parse(literal)
+		if !ok {
+			v = constant(ratArithmetic{}, literal)
+		}
+		c.constants[name] = v
+	}
+	c.constants["true"] = Bool(true)
+	c.constants["false"] = Bool(false)
+
+	c.units = make(map[string]Value, len(standardUnits))
+	for name, v := range standardUnits {
+		c.units[name] = v
+	}
+
+	return &c, nil
+}
+
+// Calculate evaluates the expression with float64 numbers
 func Calculate(input string) (string, error) {
-	queue, err := reversePolishTokenizer(input)
+	return defaultCalculator.Calculate(input)
+}
+
+// Calculate evaluates the expression and formats the result
+func (c *Calculator) Calculate(input string) (string, error) {
+	v, err := c.Evaluate(input, nil)
 	if err != nil {
 		return "", err
 	}
 
-	return parseQueue(queue)
+	return c.Format(v), nil
+}
+
+func (c *Calculator) Mode() Mode {
+	return c.arithmetic.mode()
+}
+
+// Format renders the value according to the formatting options of the calculator,
+// the magnitude of a quantity is converted to the mode and followed by its unit
+func (c *Calculator) Format(v Value) string {
+	if v.Type() == BoolType {
+		return v.String()
+	}
+
+	if v.mode == modeQuantity {
+		s := c.Format(Rat(v.magnitude()))
+		if strings.Contains(s, div) {
+			s = leftBracket + s + rightBracket
+		}
+		return s + " " + v.Unit()
+	}
+
+	v = c.arithmetic.convert(v)
+	if c.base != 10 {
+		if s, ok := formatInteger(v, c.base); ok {
+			return s
+		}
+	}
+
+	return c.arithmetic.format(v, c.digits)
 }
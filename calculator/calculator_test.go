@@ -3,6 +3,9 @@ package calculator_test
 import (
 	"fmt"
 	"github.com/denismitr/gds/calculator"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"testing"
 )
 
@@ -16,6 +19,10 @@ func TestCalculate(t *testing.T) {
 		{input: "2 + 2 - 1", output: "3"},
 		{input: "9 + ( ( 24 / 6 ) - 2 )", output: "11"},
 		{input: "9 + 24 / ( 7 - 3 )", output: "15"},
+		{input: "2+2", output: "4"},
+		{input: "(1 + 2)", output: "3"},
+		{input: "\t9+24/(7-3)\n", output: "15"},
+		{input: "((2))*(3 + 4)", output: "14"},
 	}
 
 	for _, tc := range tt {
@@ -31,3 +38,415 @@ func TestCalculate(t *testing.T) {
 		})
 	}
 }
+
+func TestCalculate_Errors(t *testing.T) {
+	tt := []struct {
+		input string
+		err   error
+		pos   int
+	}{
+		{input: "2 $ 2", err: calculator.ErrUnknownToken, pos: 2},
+		{input: "(1 + 2", err: calculator.ErrMismatchedParentheses, pos: 0},
+		{input: "1 + 2)", err: calculator.ErrMismatchedParentheses, pos: 5},
+		{input: "(1 + (2 * 3)", err: calculator.ErrMismatchedParentheses, pos: 0},
+		{input: "", pos: 0},
+		{input: "   ", pos: 0},
+		{input: "()", pos: 0},
+		{input: "2 +", pos: 2},
+		{input: "* 3", pos: 0},
+		{input: "2 3", pos: 2},
+		{input: "1 + 2e", pos: 4},
+		{input: "1 + .", pos: 4},
+	}
+
+	for _, tc := range tt {
+		t.Run(fmt.Sprintf("%q", tc.input), func(t *testing.T) {
+			output, err := calculator.Calculate(tc.input)
+			require.Error(t, err)
+			assert.Equal(t, "", output)
+
+			var syntaxErr *calculator.SyntaxError
+			require.True(t, errors.As(err, &syntaxErr), "got %v", err)
+			assert.Equal(t, tc.pos, syntaxErr.Pos)
+
+			if tc.err != nil {
+				assert.True(t, errors.Is(err, tc.err), "got %v", err)
+			}
+		})
+	}
+}
+
+func TestCalculate_DivisionByZero(t *testing.T) {
+	for _, input := range []string{"1 / 0", "4 / (2 - 2)", "(1 / 0) + 1"} {
+		_, err := calculator.Calculate(input)
+		assert.True(t, errors.Is(err, calculator.ErrDivisionByZero), "%s: got %v", input, err)
+	}
+}
+
+func TestCalculate_Decimals(t *testing.T) {
+	tt := []struct {
+		input  string
+		output string
+	}{
+		{input: "1.5 * 2", output: "3"},
+		{input: "7 / 2", output: "3.5"},
+		{input: ".5 + 2.", output: "2.5"},
+		{input: "1e3 + 2.5E-1", output: "1000.25"},
+		{input: "6.02e+23 / 2", output: "3.01e+23"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.input, func(t *testing.T) {
+			output, err := calculator.Calculate(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.output, output)
+		})
+	}
+}
+
+func TestCalculator_Modes(t *testing.T) {
+	tt := []struct {
+		name   string
+		opts   []calculator.OptionFunc
+		input  string
+		output string
+	}{
+		{name: "float", input: "0.1 + 0.2", output: "0.30000000000000004"},
+		{name: "float digits", opts: []calculator.OptionFunc{calculator.WithDigits(2)}, input: "10 / 3", output: "3.33"},
+		{name: "rational", opts: []calculator.OptionFunc{calculator.WithMode(calculator.ModeRational)}, input: "0.1 + 0.2", output: "3/10"},
+		{name: "rational fraction", opts: []calculator.OptionFunc{calculator.WithMode(calculator.ModeRational)}, input: "1 / 3 + 1 / 6", output: "1/2"},
+		{name: "rational integer", opts: []calculator.OptionFunc{calculator.WithMode(calculator.ModeRational)}, input: "2.5 * 4", output: "10"},
+		{
+			name:   "rational digits",
+			opts:   []calculator.OptionFunc{calculator.WithMode(calculator.ModeRational), calculator.WithDigits(5)},
+			input:  "2 / 3",
+			output: "0.66667",
+		},
+		{name: "bigfloat", opts: []calculator.OptionFunc{calculator.WithMode(calculator.ModeBigFloat)}, input: "0.1 + 0.2", output: "0.3"},
+		{
+			name:   "bigfloat precision",
+			opts:   []calculator.OptionFunc{calculator.WithMode(calculator.ModeBigFloat), calculator.WithPrecision(24)},
+			input:  "1 / 3",
+			output: "0.33333334",
+		},
+		{
+			name:   "bigfloat digits",
+			opts:   []calculator.OptionFunc{calculator.WithMode(calculator.ModeBigFloat), calculator.WithDigits(30)},
+			input:  "1 / 7",
+			output: "0.142857142857142857142857142857",
+		},
+		{
+			name:   "bigfloat beyond float64",
+			opts:   []calculator.OptionFunc{calculator.WithMode(calculator.ModeBigFloat)},
+			input:  "1e400 * 1e400",
+			output: "1e+800",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := calculator.New(tc.opts...)
+			require.NoError(t, err)
+
+			output, err := c.Calculate(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.output, output)
+		})
+	}
+}
+
+func TestCalculator_DivisionByZeroInEveryMode(t *testing.T) {
+	for _, mode := range []calculator.Mode{calculator.ModeFloat, calculator.ModeRational, calculator.ModeBigFloat} {
+		c, err := calculator.New(calculator.WithMode(mode))
+		require.NoError(t, err)
+		assert.Equal(t, mode, c.Mode())
+
+		_, err = c.Calculate("1 / (0.5 - 0.5)")
+		assert.True(t, errors.Is(err, calculator.ErrDivisionByZero), "%s: got %v", mode, err)
+	}
+}
+
+func TestNew_InvalidOptions(t *testing.T) {
+	_, err := calculator.New(calculator.WithMode(calculator.Mode(42)))
+	assert.True(t, errors.Is(err, calculator.ErrInvalidMode))
+
+	_, err = calculator.New(calculator.WithPrecision(0))
+	assert.True(t, errors.Is(err, calculator.ErrInvalidPrecision))
+}
+
+func TestCalculate_Operators(t *testing.T) {
+	tt := []struct {
+		input  string
+		output string
+	}{
+		{input: "8 - 3 - 2", output: "3"},
+		{input: "64 / 4 / 2", output: "8"},
+		{input: "2 - 3 + 4", output: "3"},
+		{input: "-3 + 5", output: "2"},
+		{input: "2 * -4", output: "-8"},
+		{input: "-(2 + 3)", output: "-5"},
+		{input: "+4 - -4", output: "8"},
+		{input: "--3", output: "3"},
+		{input: "2 ^ 3 ^ 2", output: "512"},
+		{input: "(2 ^ 3) ^ 2", output: "64"},
+		{input: "-2 ^ 2", output: "-4"},
+		{input: "(-2) ^ 2", output: "4"},
+		{input: "2 ^ -1", output: "0.5"},
+		{input: "2 * 3 ^ 2", output: "18"},
+		{input: "7 % 3", output: "1"},
+		{input: "-7 % 3", output: "2"},
+		{input: "7 % -3", output: "-2"},
+		{input: "5.5 % 2", output: "1.5"},
+		{input: "7 // 2", output: "3"},
+		{input: "-7 // 2", output: "-4"},
+		{input: "1 + 7 // 2 * 2", output: "7"},
+		{input: "10 - 2 % 3 * 4", output: "2"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.input, func(t *testing.T) {
+			output, err := calculator.Calculate(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.output, output)
+		})
+	}
+}
+
+func TestCalculator_OperatorsInExactModes(t *testing.T) {
+	tt := []struct {
+		input    string
+		rational string
+		bigFloat string
+	}{
+		{input: "2 ^ 100", rational: "1267650600228229401496703205376", bigFloat: "1.267650600228229401496703205376e+30"},
+		{input: "(2 / 3) ^ -2", rational: "9/4", bigFloat: "2.25"},
+		{input: "-7.5 % 2", rational: "1/2", bigFloat: "0.5"},
+		{input: "-7.5 // 2", rational: "-4", bigFloat: "-4"},
+		{input: "4 ^ 0.5", rational: "2", bigFloat: "2"},
+		{input: "-(1 / 4)", rational: "-1/4", bigFloat: "-0.25"},
+	}
+
+	rat, err := calculator.New(calculator.WithMode(calculator.ModeRational))
+	require.NoError(t, err)
+	bf, err := calculator.New(calculator.WithMode(calculator.ModeBigFloat))
+	require.NoError(t, err)
+
+	for _, tc := range tt {
+		t.Run(tc.input, func(t *testing.T) {
+			output, err := rat.Calculate(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.rational, output)
+
+			output, err = bf.Calculate(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.bigFloat, output)
+		})
+	}
+}
+
+func TestCalculator_OperatorErrors(t *testing.T) {
+	rat, err := calculator.New(calculator.WithMode(calculator.ModeRational))
+	require.NoError(t, err)
+
+	for _, input := range []string{"5 % 0", "5 // 0", "0 ^ -1"} {
+		_, err := rat.Calculate(input)
+		assert.True(t, errors.Is(err, calculator.ErrDivisionByZero), "%s: got %v", input, err)
+	}
+
+	_, err = rat.Calculate("(-8) ^ 0.5")
+	assert.True(t, errors.Is(err, calculator.ErrDomain), "got %v", err)
+
+	_, err = rat.Calculate("10 ^ 1000000")
+	assert.True(t, errors.Is(err, calculator.ErrOverflow), "got %v", err)
+
+	bf, err := calculator.New(calculator.WithMode(calculator.ModeBigFloat))
+	require.NoError(t, err)
+
+	powers := []struct {
+		c     *calculator.Calculator
+		input string
+		err   error
+	}{
+		{input: "10 ^ 400", err: calculator.ErrOverflow},
+		{input: "(-8) ^ 0.5", err: calculator.ErrDomain},
+		{input: "0 ^ -1", err: calculator.ErrDivisionByZero},
+		{input: "1e308 * 10", err: calculator.ErrOverflow},
+		{input: "-1e308 - 1e308", err: calculator.ErrOverflow},
+		{input: "1e308 / 0.1", err: calculator.ErrOverflow},
+		{input: "1e308 // 0.1", err: calculator.ErrOverflow},
+		{c: bf, input: "10 ^ (10 ^ 10) - 10 ^ (10 ^ 10)", err: calculator.ErrOverflow},
+		{c: bf, input: "(10 ^ 60000) ^ 60000", err: calculator.ErrOverflow},
+		{c: rat, input: "(10 ^ 60000) ^ 60000", err: calculator.ErrOverflow},
+		{c: rat, input: "(2 ^ 65536) ^ 65536", err: calculator.ErrOverflow},
+		{c: rat, input: "(1/3 ^ 65536) ^ -65536", err: calculator.ErrOverflow},
+	}
+
+	for _, tc := range powers {
+		c := tc.c
+		if c == nil {
+			c, err = calculator.New()
+			require.NoError(t, err)
+		}
+
+		require.NotPanics(t, func() {
+			_, err = c.Calculate(tc.input)
+		}, tc.input)
+		assert.True(t, errors.Is(err, tc.err), "%s: got %v", tc.input, err)
+	}
+
+	for _, input := range []string{"2 * * 3", "^ 2", "2 ^"} {
+		_, err := calculator.Calculate(input)
+		var syntaxErr *calculator.SyntaxError
+		assert.True(t, errors.As(err, &syntaxErr), "%s: got %v", input, err)
+	}
+}
+
+func TestCalculate_Functions(t *testing.T) {
+	tt := []struct {
+		input  string
+		output string
+	}{
+		{input: "sqrt(16)", output: "4"},
+		{input: "sqrt(2) * sqrt(2)", output: "2.0000000000000004"},
+		{input: "abs(-3) + abs(3)", output: "6"},
+		{input: "max(1, 7, 3)", output: "7"},
+		{input: "min(4, -2 * 3, 0)", output: "-6"},
+		{input: "max(2)", output: "2"},
+		{input: "log(1000)", output: "3"},
+		{input: "log(8, 2)", output: "3"},
+		{input: "ln(e)", output: "1"},
+		{input: "exp(0)", output: "1"},
+		{input: "round(sin(pi / 2))", output: "1"},
+		{input: "cos(0) + tan(0)", output: "1"},
+		{input: "round(2.5) + round(-2.5)", output: "0"},
+		{input: "floor(-1.5)", output: "-2"},
+		{input: "ceil(-1.5)", output: "-1"},
+		{input: "round(asin(1) * 2 / pi)", output: "1"},
+		{input: "round(0.49999999999999994)", output: "0"},
+		{input: "round(2^52 + 1) - 2^52", output: "1"},
+		{input: "2 * pi", output: "6.283185307179586"},
+		{input: "max(sqrt(9), min(10, 2 ^ 3), -abs(-20))", output: "8"},
+		{input: "-sqrt(4)^2", output: "-4"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.input, func(t *testing.T) {
+			output, err := calculator.Calculate(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.output, output)
+		})
+	}
+}
+
+func TestCalculator_FunctionsInExactModes(t *testing.T) {
+	rat, err := calculator.New(calculator.WithMode(calculator.ModeRational))
+	require.NoError(t, err)
+
+	output, err := rat.Calculate("max(1/3, 1/4) + round(7/2) + abs(-1/6)")
+	require.NoError(t, err)
+	assert.Equal(t, "9/2", output)
+
+	bf, err := calculator.New(calculator.WithMode(calculator.ModeBigFloat), calculator.WithDigits(40))
+	require.NoError(t, err)
+
+	output, err = bf.Calculate("sqrt(2)")
+	require.NoError(t, err)
+	assert.Equal(t, "1.4142135623730950488016887242096980785697", output)
+
+	output, err = bf.Calculate("pi")
+	require.NoError(t, err)
+	assert.Equal(t, "3.1415926535897932384626433832795028841972", output)
+}
+
+func TestCalculator_RegisterFunction(t *testing.T) {
+	c, err := calculator.New()
+	require.NoError(t, err)
+
+	hypot := calculator.Function{
+		MinArgs: 2,
+		MaxArgs: 2,
+		Call: func(args []calculator.Value) (calculator.Value, error) {
+			x, y := args[0].Float64(), args[1].Float64()
+			return calculator.Float(x*x + y*y), nil
+		},
+	}
+	require.NoError(t, c.RegisterFunction("sumsq", hypot))
+
+	sum := calculator.Function{
+		MinArgs: 0,
+		MaxArgs: calculator.Variadic,
+		Call: func(args []calculator.Value) (calculator.Value, error) {
+			total := 0.0
+			for _, a := range args {
+				total += a.Float64()
+			}
+			return calculator.Float(total), nil
+		},
+	}
+	require.NoError(t, c.RegisterFunction("sum", sum))
+
+	output, err := c.Calculate("sqrt(sumsq(3, 4)) + sum() + sum(1, 2, 3)")
+	require.NoError(t, err)
+	assert.Equal(t, "11", output)
+
+	_, err = c.Calculate("sumsq(1)")
+	assert.True(t, errors.Is(err, calculator.ErrWrongArgumentCount), "got %v", err)
+
+	var syntaxErr *calculator.SyntaxError
+	_, err = c.Calculate("1 + sumsq(1, 2, 3)")
+	require.True(t, errors.As(err, &syntaxErr))
+	assert.Equal(t, 4, syntaxErr.Pos)
+
+	_, err = calculator.Calculate("sumsq(3, 4)")
+	assert.True(t, errors.Is(err, calculator.ErrUnknownToken), "functions are registered per calculator, got %v", err)
+
+	failing := calculator.Function{MinArgs: 1, MaxArgs: 1, Call: func([]calculator.Value) (calculator.Value, error) {
+		return calculator.Value{}, calculator.ErrDomain
+	}}
+	require.NoError(t, c.RegisterFunction("fail", failing))
+	_, err = c.Calculate("fail(1)")
+	assert.True(t, errors.Is(err, calculator.ErrDomain))
+
+	for name, f := range map[string]calculator.Function{
+		"1abc":   hypot,
+		"a-b":    hypot,
+		"nocall": {MinArgs: 1, MaxArgs: 1},
+		"range":  {MinArgs: 2, MaxArgs: 1, Call: hypot.Call},
+		"neg":    {MinArgs: -1, MaxArgs: 1, Call: hypot.Call},
+	} {
+		err := c.RegisterFunction(name, f)
+		assert.True(t, errors.Is(err, calculator.ErrInvalidFunction), "%s: got %v", name, err)
+	}
+}
+
+func TestCalculate_FunctionErrors(t *testing.T) {
+	tt := []struct {
+		input string
+		err   error
+	}{
+		{input: "sqrt(-1)", err: calculator.ErrDomain},
+		{input: "log(0)", err: calculator.ErrDomain},
+		{input: "log(8, 1)", err: calculator.ErrDomain},
+		{input: "ln(-2)", err: calculator.ErrDomain},
+		{input: "asin(2)", err: calculator.ErrDomain},
+		{input: "exp(1000)", err: calculator.ErrOverflow},
+		{input: "sqrt(1, 2)", err: calculator.ErrWrongArgumentCount},
+		{input: "max()", err: calculator.ErrWrongArgumentCount},
+		{input: "nope(1)", err: calculator.ErrUnknownToken},
+		{input: "tau * 2", err: calculator.ErrUndefinedVariable},
+		{input: "max(1, 2", err: calculator.ErrMismatchedParentheses},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.input, func(t *testing.T) {
+			_, err := calculator.Calculate(tc.input)
+			assert.True(t, errors.Is(err, tc.err), "got %v", err)
+		})
+	}
+
+	for _, input := range []string{"max(1,)", "max(,1)", "1, 2", "(1, 2)", "max(1 2)", "sqrt()4"} {
+		_, err := calculator.Calculate(input)
+		var syntaxErr *calculator.SyntaxError
+		assert.True(t, errors.As(err, &syntaxErr), "%s: got %v", input, err)
+	}
+}
@@ -0,0 +1,261 @@
+package calculator_test
+
+import (
+	"github.com/denismitr/gds/calculator"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+)
+
+func TestCalculate_Conditionals(t *testing.T) {
+	tt := []struct {
+		input  string
+		output string
+	}{
+		{input: "1 < 2", output: "true"},
+		{input: "2 <= 2", output: "true"},
+		{input: "3 > 4", output: "false"},
+		{input: "3 >= 4", output: "false"},
+		{input: "2 + 2 == 4", output: "true"},
+		{input: "2 * 3 != 6", output: "false"},
+		{input: "true == false", output: "false"},
+		{input: "1 < 2 == 3 < 4", output: "true"},
+		{input: "!true", output: "false"},
+		{input: "!!true", output: "true"},
+		{input: "!(1 > 2)", output: "true"},
+		{input: "true && false", output: "false"},
+		{input: "true || false", output: "true"},
+		{input: "true || false && false", output: "true"},
+		{input: "(true || false) && false", output: "false"},
+		{input: "1 < 2 ? 10 : 20", output: "10"},
+		{input: "1 > 2 ? 10 : 20", output: "20"},
+		{input: "false ? 1 : true ? 2 : 3", output: "2"},
+		{input: "true ? false ? 1 : 2 : 3", output: "2"},
+		{input: "2 * (1 > 2 ? 10 : 20) + 1", output: "41"},
+		{input: "max(1 == 1 ? 5 : 6, 2)", output: "5"},
+		{input: "1 < 2 ? 1 > 2 : true", output: "false"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.input, func(t *testing.T) {
+			output, err := calculator.Calculate(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.output, output)
+		})
+	}
+}
+
+func TestEvaluate_BusinessRule(t *testing.T) {
+	const rule = "amount > 100 && region == 2 ? 0.1 : 0"
+
+	tt := []struct {
+		amount, region float64
+		discount       float64
+	}{
+		{amount: 150, region: 2, discount: 0.1},
+		{amount: 150, region: 1, discount: 0},
+		{amount: 100, region: 2, discount: 0},
+	}
+
+	p, err := calculator.Compile(rule)
+	require.NoError(t, err)
+
+	for _, tc := range tt {
+		env := map[string]calculator.Value{"amount": calculator.Float(tc.amount), "region": calculator.Float(tc.region)}
+
+		v, err := calculator.Evaluate(rule, env)
+		require.NoError(t, err)
+		assert.Equal(t, tc.discount, v.Float64())
+
+		v, err = p.Eval(env)
+		require.NoError(t, err)
+		assert.Equal(t, tc.discount, v.Float64())
+	}
+}
+
+func TestEvaluate_ShortCircuit(t *testing.T) {
+	c, err := calculator.New()
+	require.NoError(t, err)
+
+	calls := 0
+	require.NoError(t, c.RegisterFunction("touch", calculator.Function{
+		MinArgs: 0,
+		MaxArgs: 0,
+		Call: func(args []calculator.Value) (calculator.Value, error) {
+			calls++
+			return calculator.Bool(true), nil
+		},
+	}))
+
+	tt := []struct {
+		input string
+		env   map[string]calculator.Value
+		calls int
+		value calculator.Value
+	}{
+		{input: "false && touch()", calls: 0, value: calculator.Bool(false)},
+		{input: "true || touch()", calls: 0, value: calculator.Bool(true)},
+		{input: "true && touch()", calls: 1, value: calculator.Bool(true)},
+		{input: "false || touch()", calls: 1, value: calculator.Bool(true)},
+		{input: "x > 0 && touch()", env: map[string]calculator.Value{"x": calculator.Float(0)}, calls: 0, value: calculator.Bool(false)},
+		{input: "x > 0 || touch()", env: map[string]calculator.Value{"x": calculator.Float(1)}, calls: 0, value: calculator.Bool(true)},
+		{input: "x > 0 ? touch() : false", env: map[string]calculator.Value{"x": calculator.Float(0)}, calls: 0, value: calculator.Bool(false)},
+		{input: "x > 0 ? false : touch()", env: map[string]calculator.Value{"x": calculator.Float(0)}, calls: 1, value: calculator.Bool(true)},
+		{input: "x != 0 ? 1 / x : 0", env: map[string]calculator.Value{"x": calculator.Float(0)}, value: calculator.Float(0)},
+		{input: "x == 0 || undefined", env: map[string]calculator.Value{"x": calculator.Float(0)}, value: calculator.Bool(true)},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.input, func(t *testing.T) {
+			calls = 0
+			v, err := c.Evaluate(tc.input, tc.env)
+			require.NoError(t, err)
+			assert.Equal(t, tc.value, v)
+			assert.Equal(t, tc.calls, calls)
+		})
+	}
+}
+
+func TestEvaluate_TypeErrors(t *testing.T) {
+	tt := []struct {
+		input string
+		op    string
+		pos   int
+		want  calculator.Type
+		got   calculator.Type
+	}{
+		{input: "1 + true", op: "+", pos: 2, want: calculator.NumberType, got: calculator.BoolType},
+		{input: "(1 < 2) * 3", op: "*", pos: 8, want: calculator.NumberType, got: calculator.BoolType},
+		{input: "-false", op: "-", pos: 0, want: calculator.NumberType, got: calculator.BoolType},
+		{input: "!1", op: "!", pos: 0, want: calculator.BoolType, got: calculator.NumberType},
+		{input: "1 && true", op: "&&", pos: 2, want: calculator.BoolType, got: calculator.NumberType},
+		{input: "true && 1", op: "&&", pos: 5, want: calculator.BoolType, got: calculator.NumberType},
+		{input: "false || x", op: "||", pos: 6, want: calculator.BoolType, got: calculator.NumberType},
+		{input: "x ? 1 : 2", op: "?:", pos: 2, want: calculator.BoolType, got: calculator.NumberType},
+		{input: "true == x", op: "==", pos: 5, want: calculator.BoolType, got: calculator.NumberType},
+		{input: "x < true", op: "<", pos: 2, want: calculator.NumberType, got: calculator.BoolType},
+		{input: "sqrt(x > 1)", op: "sqrt", pos: 0, want: calculator.NumberType, got: calculator.BoolType},
+	}
+
+	env := map[string]calculator.Value{"x": calculator.Float(3)}
+
+	for _, tc := range tt {
+		t.Run(tc.input, func(t *testing.T) {
+			_, err := calculator.Evaluate(tc.input, env)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, calculator.ErrType))
+
+			var typeErr *calculator.TypeError
+			require.True(t, errors.As(err, &typeErr))
+			assert.Equal(t, calculator.TypeError{Op: tc.op, Pos: tc.pos, Want: tc.want, Got: tc.got}, *typeErr)
+		})
+	}
+}
+
+func TestEvaluate_ConditionalSyntaxErrors(t *testing.T) {
+	tt := []struct {
+		input string
+		pos   int
+	}{
+		{input: "1 < 2 ? 3", pos: 6},
+		{input: "1 < 2 : 3", pos: 6},
+		{input: "(true ? 1) : 2", pos: 6},
+		{input: "true ? : 2", pos: 7},
+		{input: "true ? 1 :", pos: 9},
+		{input: "max(true ? 1, 2)", pos: 9},
+		{input: "? 1 : 2", pos: 0},
+		{input: "2 !3", pos: 2},
+		{input: "1 & & 2", pos: 4},
+		{input: "1 = = 2", pos: 2},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.input, func(t *testing.T) {
+			_, err := calculator.Evaluate(tc.input, nil)
+			var syntaxErr *calculator.SyntaxError
+			require.True(t, errors.As(err, &syntaxErr), "got %v", err)
+			assert.Equal(t, tc.pos, syntaxErr.Pos)
+		})
+	}
+}
+
+func TestEvaluate_ComparisonsInModes(t *testing.T) {
+	rat, err := calculator.New(calculator.WithMode(calculator.ModeRational))
+	require.NoError(t, err)
+
+	v, err := rat.Evaluate("1/10 + 2/10 == 3/10", nil)
+	require.NoError(t, err)
+	assert.True(t, v.Bool())
+
+	v, err = calculator.Evaluate("1/10 + 2/10 == 3/10", nil)
+	require.NoError(t, err)
+	assert.False(t, v.Bool())
+
+	v, err = rat.Evaluate("flag ? 1/3 : 0", map[string]calculator.Value{"flag": calculator.Bool(true)})
+	require.NoError(t, err)
+	assert.Equal(t, "1/3", rat.Format(v))
+
+	nan := map[string]calculator.Value{"x": calculator.Float(math.NaN())}
+	for input, expected := range map[string]bool{"x == x": false, "x != x": true, "x < 1": false, "x >= 1": false} {
+		v, err := calculator.Evaluate(input, nan)
+		require.NoError(t, err)
+		assert.Equal(t, expected, v.Bool(), input)
+	}
+}
+
+func TestExecute_Conditionals(t *testing.T) {
+	env := map[string]calculator.Value{}
+
+	v, err := calculator.Execute(`
+		amount = 250
+		vip = amount > 200
+		discount = vip ?
+			amount * 0.2 :
+			amount > 100 ? 10 : 0
+		amount - discount`, env)
+	require.NoError(t, err)
+	assert.Equal(t, 200.0, v.Float64())
+	assert.Equal(t, calculator.Bool(true), env["vip"])
+	assert.Equal(t, calculator.BoolType, env["vip"].Type())
+}
+
+func TestConditionals_Notations(t *testing.T) {
+	rpn, err := calculator.ToRPN("a > 1 && !b ? c : d ? e : f")
+	require.NoError(t, err)
+	assert.Equal(t, "a 1 > b ! && c d e f ?: ?:", rpn.String())
+	assert.Equal(t, calculator.Token{Kind: calculator.OperatorToken, Text: "?:", Args: 3, Pos: 12}, rpn[len(rpn)-1])
+
+	prefix, err := calculator.ToPrefix("a > 1 && !b ? c : d ? e : f")
+	require.NoError(t, err)
+	assert.Equal(t, "?: && > a 1 ! b c ?: d e f", prefix.String())
+
+	env := map[string]calculator.Value{"a": calculator.Float(2), "b": calculator.Bool(false)}
+	v, err := calculator.EvalRPNString("a 1 > b ! && 10 20 ?:", env)
+	require.NoError(t, err)
+	assert.Equal(t, 10.0, v.Float64())
+
+	v, err = calculator.EvalRPN(rpn, map[string]calculator.Value{
+		"a": calculator.Float(0), "b": calculator.Bool(false),
+		"c": calculator.Float(1), "d": calculator.Bool(false), "e": calculator.Float(2), "f": calculator.Float(3),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3.0, v.Float64())
+
+	formats := map[string]string{
+		"(a ? b : c) ? d : e":           "(a ? b : c) ? d : e",
+		"a ? (b ? c : d) : (e ? f : g)": "a ? b ? c : d : e ? f : g",
+		"(a || b) && c":                 "(a || b) && c",
+		"a || (b && c)":                 "a || b && c",
+		"!(a == b)":                     "!(a == b)",
+		"(a < b) == (c < d)":            "a < b == c < d",
+		"1 + (a ? 2 : 3)":               "1 + (a ? 2 : 3)",
+		"(1 < 2) ? x : y":               "1 < 2 ? x : y",
+	}
+	for input, expected := range formats {
+		output, err := calculator.FormatInfix(input)
+		require.NoError(t, err)
+		assert.Equal(t, expected, output, input)
+	}
+}
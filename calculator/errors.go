@@ -0,0 +1,51 @@
+package calculator
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+)
+
+var ErrDivisionByZero = errors.New("division by zero")
+var ErrMismatchedParentheses = errors.New("mismatched parentheses")
+var ErrUnknownToken = errors.New("unknown token")
+var ErrDomain = errors.New("argument out of domain")
+var ErrOverflow = errors.New("overflow")
+var ErrType = errors.New("type mismatch")
+
+// SyntaxError describes a problem with the input expression,
+// Pos is the byte offset of the offending token in the input
+type SyntaxError struct {
+	Pos int
+	Msg string
+	Err error
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at position %d: %s", e.Pos, e.Msg)
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+func syntaxError(pos int, format string, args ...interface{}) *SyntaxError {
+	return &SyntaxError{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// TypeError is returned when an operator, a function or a condition
+// gets a boolean where a number is expected or the other way around
+type TypeError struct {
+	// Op is the operator or the name of the function
+	Op   string
+	Pos  int
+	Want Type
+	Got  Type
+}
+
+func (e *TypeError) Error() string {
+	return fmt.Sprintf("%s at position %d expects %s, got %s", e.Op, e.Pos, e.Want, e.Got)
+}
+
+func (e *TypeError) Unwrap() error {
+	return ErrType
+}
@@ -0,0 +1,304 @@
+package calculator
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"sort"
+)
+
+var ErrUndefinedVariable = errors.New("undefined variable")
+
+// UndefinedVariableError is returned when an expression refers to a name
+// that is neither a variable of the environment nor a constant
+type UndefinedVariableError struct {
+	Name string
+	Pos  int
+	// Suggestion is the closest defined name, it is empty when nothing is similar enough
+	Suggestion string
+}
+
+func (e *UndefinedVariableError) Error() string {
+	msg := fmt.Sprintf("undefined variable %s at position %d", e.Name, e.Pos)
+	if e.Suggestion != "" {
+		msg += fmt.Sprintf(", did you mean %s?", e.Suggestion)
+	}
+
+	return msg
+}
+
+func (e *UndefinedVariableError) Unwrap() error {
+	return ErrUndefinedVariable
+}
+
+// Evaluate evaluates a single expression with float64 numbers
+func Evaluate(expr string, env map[string]Value) (Value, error) {
+	return defaultCalculator.Evaluate(expr, env)
+}
+
+// Execute runs statements with float64 numbers
+func Execute(script string, env map[string]Value) (Value, error) {
+	return defaultCalculator.Execute(script, env)
+}
+
+// Evaluate evaluates a single expression, identifiers are looked up in env first and among the constants after that,
+// names in the position of a unit like km in 3 km or m in 3 km in m are looked up among the units before that, env is never modified
+func (c *Calculator) Evaluate(expr string, env map[string]Value) (Value, error) {
+	p, err := c.Compile(expr)
+	if err != nil {
+		return Value{}, err
+	}
+
+	return p.Eval(env)
+}
+
+// Execute runs statements separated by semicolons or new lines and returns the value of the last one.
+// A statement is either an expression or an assignment like x = 3 * y, assigned variables
+// are stored in env and are visible to the following statements. A new line does not end
+// a statement inside brackets or after an operator, so long expressions can span several lines.
+func (c *Calculator) Execute(script string, env map[string]Value) (Value, error) {
+	tokens, err := lex(script)
+	if err != nil {
+		return Value{}, err
+	}
+
+	statements := splitStatements(tokens)
+	if len(statements) == 0 {
+		return Value{}, syntaxError(0, "empty expression")
+	}
+
+	if env == nil {
+		env = make(map[string]Value)
+	}
+
+	var result Value
+	for _, statement := range statements {
+		target, expr, err := assignment(statement)
+		if err != nil {
+			return Value{}, err
+		}
+
+		queue, err := shuntingYard(expr)
+		if err != nil {
+			return Value{}, err
+		}
+
+		p, err := c.program(queue)
+		if err != nil {
+			return Value{}, err
+		}
+
+		result, err = p.Eval(env)
+		if err != nil {
+			return Value{}, err
+		}
+
+		if target != nil {
+			env[target.value] = result
+		}
+	}
+
+	return result, nil
+}
+
+// expression lexes the input and makes sure it is a single expression without assignments
+func expression(input string) ([]token, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+
+	statements := splitStatements(tokens)
+	switch {
+	case len(statements) == 0:
+		return nil, syntaxError(0, "empty expression")
+	case len(statements) > 1:
+		return nil, syntaxError(statements[1][0].pos, "unexpected statement, only Execute runs several statements")
+	}
+
+	if len(statements[0]) > 1 && statements[0][1].kind == assign {
+		return nil, syntaxError(statements[0][1].pos, "unexpected %s, only Execute runs assignments", statements[0][1].value)
+	}
+
+	return statements[0], nil
+}
+
+// assignment splits a statement like x = expr into the assigned variable and the expression
+func assignment(statement []token) (*token, []token, error) {
+	if len(statement) < 2 || statement[1].kind != assign {
+		return nil, statement, nil
+	}
+
+	if statement[0].kind != identifier {
+		return nil, nil, syntaxError(statement[1].pos, "unexpected %s, only a variable can be assigned", statement[1].value)
+	}
+
+	if len(statement) == 2 {
+		return nil, nil, syntaxError(statement[1].pos, "missing expression after %s", statement[1].value)
+	}
+
+	return &statement[0], statement[2:], nil
+}
+
+// splitStatements groups tokens into statements dropping the separators, a new line
+// inside brackets or after an operator, a comma, a colon or = continues the statement
+func splitStatements(tokens []token) [][]token {
+	var statements [][]token
+	var current []token
+	depth := 0
+
+	for _, t := range tokens {
+		if t.kind == separator {
+			if t.value == "\n" && (depth > 0 || continues(current)) {
+				continue
+			}
+
+			if len(current) > 0 {
+				statements = append(statements, current)
+				current = nil
+			}
+			continue
+		}
+
+		switch t.value {
+		case leftBracket:
+			depth++
+		case rightBracket:
+			depth--
+		}
+
+		current = append(current, t)
+	}
+
+	if len(current) > 0 {
+		statements = append(statements, current)
+	}
+
+	return statements
+}
+
+// Complete reports whether the input can be executed as it is, unclosed brackets or a trailing
+// operator, comma, colon or = mean that the last statement continues on the next line.
+// Input that can not be lexed is complete, so that the error is reported by Execute.
+func Complete(input string) bool {
+	tokens, err := lex(input)
+	if err != nil {
+		return true
+	}
+
+	depth := 0
+	var last []token
+	for i, t := range tokens {
+		switch {
+		case t.value == leftBracket:
+			depth++
+		case t.value == rightBracket:
+			depth--
+		case t.kind != separator:
+			last = tokens[i : i+1]
+		}
+	}
+
+	return depth <= 0 && !continues(last)
+}
+
+func continues(statement []token) bool {
+	if len(statement) == 0 {
+		return false
+	}
+
+	switch statement[len(statement)-1].kind {
+	case operator, comma, assign, colon:
+		return true
+	default:
+		return false
+	}
+}
+
+// lookup resolves a name in the position of a unit as a unit first, so h in 1 h in s is an hour even when h is a variable
+func (c *Calculator) lookup(t token, env map[string]Value) (Value, error) {
+	var v Value
+	var ok bool
+	if t.unit {
+		v, ok = c.units[t.value]
+	}
+	if !ok {
+		v, ok = env[t.value]
+	}
+	if !ok {
+		v, ok = c.constants[t.value]
+	}
+
+	if !ok && t.unit {
+		return Value{}, &UnknownUnitError{Name: t.value, Pos: t.pos, Suggestion: c.suggest(t.value, env, true)}
+	}
+	if !ok {
+		return Value{}, &UndefinedVariableError{Name: t.value, Pos: t.pos, Suggestion: c.suggest(t.value, env, false)}
+	}
+
+	v = c.arithmetic.convert(v)
+	if v.Type() == NumberType && v.mode != modeQuantity && v.mode != c.arithmetic.mode() || v.mode == modeQuantity && !c.represents(v) {
+		return Value{}, errors.Wrapf(ErrDomain, "%s = %s cannot be used in %s mode", t.value, v, c.arithmetic.mode())
+	}
+
+	return v, nil
+}
+
+// suggest finds the defined name closest to the misspelled one, the units are proposed for names in their position
+func (c *Calculator) suggest(name string, env map[string]Value, units bool) string {
+	candidates := make([]string, 0, len(env)+len(c.constants))
+	for n := range env {
+		candidates = append(candidates, n)
+	}
+	for n := range c.constants {
+		candidates = append(candidates, n)
+	}
+	if units {
+		for n := range c.units {
+			candidates = append(candidates, n)
+		}
+	}
+	sort.Strings(candidates)
+
+	best, bestDistance := "", len(name)/2+1
+	for _, candidate := range candidates {
+		if d := editDistance(name, candidate); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+
+	return best
+}
+
+// editDistance is the Levenshtein distance between two names
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+func minInt(first int, rest ...int) int {
+	for _, n := range rest {
+		if n < first {
+			first = n
+		}
+	}
+
+	return first
+}
@@ -0,0 +1,159 @@
+package calculator_test
+
+import (
+	"github.com/denismitr/gds/calculator"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+	env := map[string]calculator.Value{
+		"price":    calculator.Float(120),
+		"quantity": calculator.Float(3),
+		"discount": calculator.Float(0.25),
+	}
+
+	v, err := calculator.Evaluate("price * quantity * (1 - discount)", env)
+	require.NoError(t, err)
+	assert.Equal(t, 270.0, v.Float64())
+	assert.Equal(t, calculator.ModeFloat, v.Mode())
+
+	env["quantity"] = calculator.Float(1)
+	v, err = calculator.Evaluate("price * quantity * (1 - discount)", env)
+	require.NoError(t, err)
+	assert.Equal(t, 90.0, v.Float64())
+
+	v, err = calculator.Evaluate("2 * pi", nil)
+	require.NoError(t, err)
+	assert.InDelta(t, 6.283185, v.Float64(), 1e-6)
+
+	v, err = calculator.Evaluate("pi", map[string]calculator.Value{"pi": calculator.Float(3)})
+	require.NoError(t, err)
+	assert.Equal(t, 3.0, v.Float64(), "variables shadow constants")
+}
+
+func TestEvaluate_ConvertsVariablesToTheMode(t *testing.T) {
+	c, err := calculator.New(calculator.WithMode(calculator.ModeRational))
+	require.NoError(t, err)
+
+	env := map[string]calculator.Value{
+		"a": calculator.Float(0.5),
+		"b": calculator.Rat(big.NewRat(1, 3)),
+	}
+
+	v, err := c.Evaluate("a + b", env)
+	require.NoError(t, err)
+	assert.Equal(t, calculator.ModeRational, v.Mode())
+	assert.Equal(t, "5/6", c.Format(v))
+
+	_, err = c.Evaluate("x + 1", map[string]calculator.Value{"x": calculator.Float(math.Inf(1))})
+	assert.True(t, errors.Is(err, calculator.ErrDomain), "got %v", err)
+}
+
+func TestEvaluate_UndefinedVariable(t *testing.T) {
+	env := map[string]calculator.Value{"amount": calculator.Float(1)}
+
+	_, err := calculator.Evaluate("2 * amont + 1", env)
+	require.True(t, errors.Is(err, calculator.ErrUndefinedVariable))
+
+	var undefined *calculator.UndefinedVariableError
+	require.True(t, errors.As(err, &undefined))
+	assert.Equal(t, "amont", undefined.Name)
+	assert.Equal(t, 4, undefined.Pos)
+	assert.Equal(t, "amount", undefined.Suggestion)
+	assert.Equal(t, "undefined variable amont at position 4, did you mean amount?", err.Error())
+
+	_, err = calculator.Evaluate("zzzzzz", env)
+	require.True(t, errors.As(err, &undefined))
+	assert.Equal(t, "", undefined.Suggestion)
+}
+
+func TestEvaluate_RejectsStatements(t *testing.T) {
+	for _, input := range []string{"x = 1", "1; 2", "1\n2"} {
+		_, err := calculator.Evaluate(input, nil)
+		var syntaxErr *calculator.SyntaxError
+		assert.True(t, errors.As(err, &syntaxErr), "%q: got %v", input, err)
+	}
+
+	v, err := calculator.Evaluate("1 +\n2;\n", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3.0, v.Float64())
+}
+
+func TestExecute(t *testing.T) {
+	env := map[string]calculator.Value{"y": calculator.Float(2)}
+
+	v, err := calculator.Execute("x = 3 * y; z = x + 1\nx * z", env)
+	require.NoError(t, err)
+	assert.Equal(t, 42.0, v.Float64())
+	assert.Equal(t, 6.0, env["x"].Float64())
+	assert.Equal(t, 7.0, env["z"].Float64())
+
+	v, err = calculator.Execute("x = x + 1", env)
+	require.NoError(t, err)
+	assert.Equal(t, 7.0, v.Float64(), "the value of an assignment is the assigned value")
+	assert.Equal(t, 7.0, env["x"].Float64())
+
+	v, err = calculator.Execute("total = max(\n  1,\n  2\n) *\n  10\n\ntotal / 4", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, v.Float64())
+}
+
+func TestExecute_Errors(t *testing.T) {
+	tt := []struct {
+		input string
+		pos   int
+	}{
+		{input: "", pos: 0},
+		{input: " ; \n ;", pos: 0},
+		{input: "1 = 2", pos: 2},
+		{input: "x =", pos: 2},
+		{input: "x = y = 2", pos: 6},
+		{input: "x = 1; (y) = 2", pos: 11},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.input, func(t *testing.T) {
+			_, err := calculator.Execute(tc.input, nil)
+			var syntaxErr *calculator.SyntaxError
+			require.True(t, errors.As(err, &syntaxErr), "got %v", err)
+			assert.Equal(t, tc.pos, syntaxErr.Pos)
+		})
+	}
+
+	env := map[string]calculator.Value{}
+	_, err := calculator.Execute("a = 1; b = a / 0; c = 3", env)
+	assert.True(t, errors.Is(err, calculator.ErrDivisionByZero))
+	assert.Contains(t, env, "a")
+	assert.NotContains(t, env, "b")
+	assert.NotContains(t, env, "c")
+}
+
+func TestComplete(t *testing.T) {
+	tt := []struct {
+		input    string
+		complete bool
+	}{
+		{input: "", complete: true},
+		{input: "1 + 2", complete: true},
+		{input: "x = 3; y = x *", complete: false},
+		{input: "max(1,", complete: false},
+		{input: "(1 + 2", complete: false},
+		{input: "(1 + 2\n) * 3\n", complete: true},
+		{input: "x =\n", complete: false},
+		{input: "x > 1 ?", complete: false},
+		{input: "x > 1 ? 2 :", complete: false},
+		{input: "1 + 2)", complete: true},
+		{input: "1 + $", complete: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.input, func(t *testing.T) {
+			assert.Equal(t, tc.complete, calculator.Complete(tc.input))
+		})
+	}
+}
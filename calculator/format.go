@@ -0,0 +1,188 @@
+package calculator
+
+import "strings"
+
+// atomPrecedence is the precedence of numbers, variables and function calls, they never need brackets
+const atomPrecedence = 100
+
+// FormatInfix parses the expression and renders it back with single spaces
+// around binary operators and only the brackets the structure requires
+func FormatInfix(expr string) (string, error) {
+	queue, err := reversePolishTokenizer(expr)
+	if err != nil {
+		return "", err
+	}
+
+	root, err := parseTree(queue)
+	if err != nil {
+		return "", err
+	}
+
+	return root.format(standardUnits), nil
+}
+
+// String renders the compiled expression after constant folding
+func (p *Program) String() string {
+	if p.root == nil {
+		return ""
+	}
+
+	return p.root.format(p.calculator.units)
+}
+
+func (n *node) String() string {
+	return n.format(standardUnits)
+}
+
+// format renders the subtree, a name in the position of a unit is written right after
+// the number like in 3 km only when it is one of the units
+func (n *node) format(units map[string]Value) string {
+	var sb strings.Builder
+	n.render(&sb, units)
+	return sb.String()
+}
+
+func (n *node) render(sb *strings.Builder, units map[string]Value) {
+	switch n.kind {
+	case numberNode:
+		sb.WriteString(n.literal())
+	case variableNode:
+		sb.WriteString(n.name)
+	case callNode:
+		sb.WriteString(n.name)
+		sb.WriteByte('(')
+		for i, arg := range n.args {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			arg.render(sb, units)
+		}
+		sb.WriteByte(')')
+	case unaryNode:
+		sb.WriteString(n.name)
+		// a prefix operator applies to everything on its right that binds tighter
+		n.args[0].renderOperand(sb, units, n.args[0].precedence(units) < n.precedence(units))
+	case binaryNode:
+		oi := binaryOperators[n.name]
+		left, right := n.args[0], n.args[1]
+
+		if n.implicit(units) {
+			// the unit is recognized only after a number or a closing bracket
+			left.renderOperand(sb, units, left.precedence(units) < implicitMultiplication.precedence || left.kind != numberNode && left.kind != callNode)
+			sb.WriteByte(' ')
+			right.render(sb, units)
+			return
+		}
+
+		left.renderOperand(sb, units, left.precedence(units) < oi.precedence ||
+			left.precedence(units) == oi.precedence && oi.associativity == rightAssociative)
+
+		sb.WriteString(" " + n.name + " ")
+
+		// a prefix operator on the right can not be mistaken for anything else
+		right.renderOperand(sb, units, right.kind != unaryNode && !right.negative() && (right.precedence(units) < oi.precedence ||
+			right.precedence(units) == oi.precedence && oi.associativity == leftAssociative))
+	case conditionalNode:
+		// the first branch is enclosed by ? and : like brackets, the second one
+		// needs them only for the unit conversions that bind looser
+		n.args[0].renderOperand(sb, units, n.args[0].precedence(units) <= conditionalOperator.precedence)
+		sb.WriteString(" " + question + " ")
+		n.args[1].render(sb, units)
+		sb.WriteString(" : ")
+		n.args[2].renderOperand(sb, units, n.args[2].precedence(units) < conditionalOperator.precedence)
+	}
+}
+
+func (n *node) renderOperand(sb *strings.Builder, units map[string]Value, brackets bool) {
+	if brackets {
+		sb.WriteByte('(')
+	}
+
+	n.render(sb, units)
+
+	if brackets {
+		sb.WriteByte(')')
+	}
+}
+
+// precedence of the subtree as it is rendered, folded numbers can look like
+// prefix minus or a division, for example -2 or 7/2
+func (n *node) precedence(units map[string]Value) int {
+	switch n.kind {
+	case unaryNode:
+		return unaryOperators[n.name].precedence
+	case binaryNode:
+		if n.implicit(units) {
+			return implicitMultiplication.precedence
+		}
+		return binaryOperators[n.name].precedence
+	case conditionalNode:
+		return conditionalOperator.precedence
+	case numberNode:
+		switch literal := n.literal(); {
+		case n.negative():
+			return unaryOperators[minus].precedence
+		case strings.Contains(literal, div):
+			return binaryOperators[div].precedence
+		}
+	}
+
+	return atomPrecedence
+}
+
+// implicit reports whether the node multiplies by a unit that is rendered right after the number like 3 km,
+// a unit after another one like kg in 2 m * kg is a written multiplication
+func (n *node) implicit(units map[string]Value) bool {
+	return n.kind == binaryNode && n.name == mul && n.args[1].startsWithUnit(units) && !n.args[0].endsWithUnit(units)
+}
+
+// startsWithUnit reports whether the subtree is a unit that can follow a number like km or m^2,
+// a variable in the position of a unit like x in -2 km * x is not one
+func (n *node) startsWithUnit(units map[string]Value) bool {
+	if n.kind == binaryNode && n.name == pow {
+		return n.args[0].startsWithUnit(units)
+	}
+
+	if n.kind != variableNode || !n.unit {
+		return false
+	}
+
+	_, ok := units[n.name]
+	return ok
+}
+
+// endsWithUnit reports whether the subtree is rendered with a unit at its end, a unit after * or /
+// is in the position of a unit there, the unit can have an exponent like m^2 or s^-1
+func (n *node) endsWithUnit(units map[string]Value) bool {
+	switch n.kind {
+	case variableNode:
+		return n.unit
+	case unaryNode:
+		return n.args[0].precedence(units) >= n.precedence(units) && n.args[0].endsWithUnit(units)
+	case binaryNode:
+		if n.name == pow {
+			exp := n.args[1]
+			if exp.kind == unaryNode && exp.name == minus {
+				exp = exp.args[0]
+			}
+			return n.args[0].kind == variableNode && n.args[0].unit && exp.kind == numberNode && !strings.Contains(exp.literal(), div)
+		}
+
+		return n.args[1].precedence(units) > n.precedence(units) && n.args[1].endsWithUnit(units)
+	default:
+		return false
+	}
+}
+
+func (n *node) negative() bool {
+	return n.kind == numberNode && strings.HasPrefix(n.literal(), minus)
+}
+
+// literal is the number as it was written, folded numbers are formatted from their values
+func (n *node) literal() string {
+	if n.name != "" {
+		return n.name
+	}
+
+	return n.value.String()
+}
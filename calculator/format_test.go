@@ -0,0 +1,63 @@
+package calculator_test
+
+import (
+	"github.com/denismitr/gds/calculator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestFormatInfix(t *testing.T) {
+	tt := []struct {
+		input  string
+		output string
+	}{
+		{input: "((2))+((3))", output: "2 + 3"},
+		{input: "(2 * 3) + 4", output: "2 * 3 + 4"},
+		{input: "2 * (3 + 4)", output: "2 * (3 + 4)"},
+		{input: "(8 - 3) - 2", output: "8 - 3 - 2"},
+		{input: "8 - (3 - 2)", output: "8 - (3 - 2)"},
+		{input: "8 - (3 + 2)", output: "8 - (3 + 2)"},
+		{input: "(2 ^ 3) ^ 2", output: "(2 ^ 3) ^ 2"},
+		{input: "2 ^ (3 ^ 2)", output: "2 ^ 3 ^ 2"},
+		{input: "-(2 ^ 2)", output: "-2 ^ 2"},
+		{input: "(-2) ^ 2", output: "(-2) ^ 2"},
+		{input: "-(x + 1)", output: "-(x + 1)"},
+		{input: "2 * (-x)", output: "2 * -x"},
+		{input: "2 ^ (-x)", output: "2 ^ -x"},
+		{input: "-(-x)", output: "--x"},
+		{input: "max((a), (b + 1) * 2,3)", output: "max(a, (b + 1) * 2, 3)"},
+		{input: "(x // 2) % (y * 3)", output: "x // 2 % (y * 3)"},
+		{input: "(x & 1) == 0", output: "x & 1 == 0"},
+		{input: "x & (1 == 0)", output: "x & (1 == 0)"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.input, func(t *testing.T) {
+			output, err := calculator.FormatInfix(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.output, output)
+
+			again, err := calculator.FormatInfix(output)
+			require.NoError(t, err)
+			assert.Equal(t, output, again, "formatting is stable")
+		})
+	}
+}
+
+func TestProgram_String(t *testing.T) {
+	p, err := calculator.Compile("x * (2 + 3) - (1 - 5)")
+	require.NoError(t, err)
+	assert.Equal(t, "x * 5 - -4", p.String())
+
+	rat, err := calculator.New(calculator.WithMode(calculator.ModeRational))
+	require.NoError(t, err)
+
+	p, err = rat.Compile("x ^ (7 / 2) + (1 - 3) ^ y")
+	require.NoError(t, err)
+	assert.Equal(t, "x ^ (7/2) + (-2) ^ y", p.String())
+
+	output, err := rat.Evaluate(p.String(), map[string]calculator.Value{"x": calculator.Float(4), "y": calculator.Float(2)})
+	require.NoError(t, err)
+	assert.Equal(t, "132", rat.Format(output))
+}
@@ -0,0 +1,273 @@
+package calculator
+
+import (
+	"github.com/pkg/errors"
+	"math"
+	"math/big"
+)
+
+var ErrWrongArgumentCount = errors.New("wrong number of arguments")
+var ErrInvalidFunction = errors.New("invalid function")
+
+// Variadic is used as MaxArgs of a function that accepts any number of arguments
+const Variadic = -1
+
+// Function is a Go function that can be called from expressions,
+// Call receives as many arguments as allowed by MinArgs and MaxArgs, they are always numbers,
+// its result is converted to the mode of the calculator unless it is a bool
+type Function struct {
+	MinArgs int
+	MaxArgs int
+	Call    func(args []Value) (Value, error)
+}
+
+type callable struct {
+	minArgs int
+	maxArgs int
+	call    func(a arithmetic, args []Value) (Value, error)
+	// pure functions depend only on their arguments, so calls with constant arguments can be folded
+	pure bool
+}
+
+func (f callable) accepts(n int) bool {
+	return n >= f.minArgs && (f.maxArgs == Variadic || n <= f.maxArgs)
+}
+
+// constants are stored as decimal literals so that every mode gets them
+// with its own precision, 100 digits are enough for about 330 bits
+var constants = map[string]string{
+	"pi": "3.1415926535897932384626433832795028841971693993751058209749445923078164062862089986280348253421170679",
+	"e":  "2.7182818284590452353602874713526624977572470936999595749669676277240766303535475945713821785251664274",
+}
+
+var builtins = map[string]callable{
+	"sqrt":  {minArgs: 1, maxArgs: 1, call: sqrt},
+	"abs":   {minArgs: 1, maxArgs: 1, call: absolute},
+	"min":   {minArgs: 1, maxArgs: Variadic, call: extremum(-1)},
+	"max":   {minArgs: 1, maxArgs: Variadic, call: extremum(1)},
+	"log":   {minArgs: 1, maxArgs: 2, call: logarithm},
+	"ln":    {minArgs: 1, maxArgs: 1, call: positive(math.Log)},
+	"exp":   {minArgs: 1, maxArgs: 1, call: approximate(math.Exp)},
+	"sin":   {minArgs: 1, maxArgs: 1, call: approximate(math.Sin)},
+	"cos":   {minArgs: 1, maxArgs: 1, call: approximate(math.Cos)},
+	"tan":   {minArgs: 1, maxArgs: 1, call: approximate(math.Tan)},
+	"asin":  {minArgs: 1, maxArgs: 1, call: approximate(math.Asin)},
+	"acos":  {minArgs: 1, maxArgs: 1, call: approximate(math.Acos)},
+	"atan":  {minArgs: 1, maxArgs: 1, call: approximate(math.Atan)},
+	"round": {minArgs: 1, maxArgs: 1, call: round},
+	"floor": {minArgs: 1, maxArgs: 1, call: floor},
+	"ceil":  {minArgs: 1, maxArgs: 1, call: ceil},
+}
+
+// RegisterFunction makes a Go function callable by name, it replaces a builtin function with the same name.
+// Functions must be registered before the calculator is used concurrently.
+func (c *Calculator) RegisterFunction(name string, f Function) error {
+	if !isIdentifier(name) {
+		return errors.Wrapf(ErrInvalidFunction, "%q is not a valid name", name)
+	}
+
+	if f.Call == nil {
+		return errors.Wrapf(ErrInvalidFunction, "%s has no implementation", name)
+	}
+
+	if f.MinArgs < 0 || f.MaxArgs != Variadic && f.MaxArgs < f.MinArgs {
+		return errors.Wrapf(ErrInvalidFunction, "%s accepts from %d to %d arguments", name, f.MinArgs, f.MaxArgs)
+	}
+
+	c.functions[name] = callable{
+		minArgs: f.MinArgs,
+		maxArgs: f.MaxArgs,
+		call: func(a arithmetic, args []Value) (Value, error) {
+			v, err := f.Call(args)
+			if err != nil {
+				return Value{}, err
+			}
+
+			v = a.convert(v)
+			if v.Type() == NumberType && v.mode != a.mode() {
+				return Value{}, errors.Wrapf(inexact(v, a.mode()), "%s returned %s", name, v)
+			}
+
+			return v, nil
+		},
+	}
+
+	return nil
+}
+
+// argumentsError reports a boolean argument, functions work with numbers only
+func argumentsError(name string, pos int, args []Value) error {
+	for _, v := range args {
+		if v.Type() != NumberType {
+			return &TypeError{Op: name, Pos: pos, Want: NumberType, Got: v.Type()}
+		}
+	}
+
+	return nil
+}
+
+// approximate evaluates a function in float64, exact modes get the result converted back
+func approximate(fn func(float64) float64) func(a arithmetic, args []Value) (Value, error) {
+	return func(a arithmetic, args []Value) (Value, error) {
+		r := fn(args[0].Float64())
+		if math.IsNaN(r) {
+			return Value{}, errors.Wrapf(ErrDomain, "%s", args[0])
+		}
+
+		if math.IsInf(r, 0) {
+			return Value{}, errors.Wrapf(ErrOverflow, "%s", args[0])
+		}
+
+		v := a.convert(Float(r))
+		if v.mode != a.mode() {
+			return Value{}, errors.Wrapf(inexact(v, a.mode()), "%s", args[0])
+		}
+
+		return v, nil
+	}
+}
+
+// positive is approximate for functions defined only for positive numbers
+func positive(fn func(float64) float64) func(a arithmetic, args []Value) (Value, error) {
+	return func(a arithmetic, args []Value) (Value, error) {
+		if sign(a, args[0]) <= 0 {
+			return Value{}, errors.Wrapf(ErrDomain, "%s is not positive", args[0])
+		}
+
+		return approximate(fn)(a, args)
+	}
+}
+
+func logarithm(a arithmetic, args []Value) (Value, error) {
+	if len(args) == 1 {
+		return positive(math.Log10)(a, args)
+	}
+
+	base := args[1]
+	if sign(a, base) <= 0 || base.Float64() == 1 {
+		return Value{}, errors.Wrapf(ErrDomain, "%s is not a valid base", base)
+	}
+
+	return positive(func(x float64) float64 {
+		return math.Log(x) / math.Log(base.Float64())
+	})(a, args[:1])
+}
+
+func sqrt(a arithmetic, args []Value) (Value, error) {
+	x := args[0]
+	if sign(a, x) < 0 {
+		return Value{}, errors.Wrapf(ErrDomain, "%s is negative", x)
+	}
+
+	if x.mode == ModeBigFloat {
+		return BigFloat(new(big.Float).SetPrec(x.bigFloat().Prec()).Sqrt(x.bigFloat())), nil
+	}
+
+	return approximate(math.Sqrt)(a, args)
+}
+
+func absolute(a arithmetic, args []Value) (Value, error) {
+	if sign(a, args[0]) < 0 {
+		return a.neg(args[0])
+	}
+
+	return args[0], nil
+}
+
+// extremum returns min for -1 and max for +1
+func extremum(direction int) func(a arithmetic, args []Value) (Value, error) {
+	return func(a arithmetic, args []Value) (Value, error) {
+		result := args[0]
+		for _, v := range args[1:] {
+			if a.cmp(v, result) == direction {
+				result = v
+			}
+		}
+
+		return result, nil
+	}
+}
+
+func floor(a arithmetic, args []Value) (Value, error) {
+	return a.floorDiv(args[0], constant(a, "1"))
+}
+
+func ceil(a arithmetic, args []Value) (Value, error) {
+	// negating the smallest integer of a mode would overflow
+	if a.mode().integer() {
+		return args[0], nil
+	}
+
+	x, err := a.neg(args[0])
+	if err != nil {
+		return Value{}, err
+	}
+
+	v, err := a.floorDiv(x, constant(a, "1"))
+	if err != nil {
+		return Value{}, err
+	}
+
+	return a.neg(v)
+}
+
+// round rounds half away from zero, integers are already rounded. Floats are rounded by math.Round
+// since adding a half to them is not exact, like in 0.49999999999999994 + 0.5
+func round(a arithmetic, args []Value) (Value, error) {
+	x := args[0]
+	if a.mode().integer() {
+		return x, nil
+	}
+
+	if x.mode == ModeFloat {
+		return Float(math.Round(x.f)), nil
+	}
+
+	if sign(a, x) < 0 {
+		neg, err := a.neg(x)
+		if err != nil {
+			return Value{}, err
+		}
+
+		v, err := round(a, []Value{neg})
+		if err != nil {
+			return Value{}, err
+		}
+
+		return a.neg(v)
+	}
+
+	half, err := a.add(x, constant(a, "0.5"))
+	if err != nil {
+		return Value{}, err
+	}
+
+	return a.floorDiv(half, constant(a, "1"))
+}
+
+func sign(a arithmetic, x Value) int {
+	return a.cmp(x, constant(a, "0"))
+}
+
+func constant(a arithmetic, literal string) Value {
+	v, _ := a.parse(literal)
+	return v
+}
+
+func isIdentifier(s string) bool {
+	if s == "" || !isLetter(rune(s[0])) || isKeyword(s) {
+		return false
+	}
+
+	for _, r := range s {
+		if !isLetter(r) && !isDigit(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isLetter(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '_'
+}
@@ -0,0 +1,419 @@
+package calculator
+
+import (
+	"github.com/pkg/errors"
+	"math"
+	"math/big"
+	"math/bits"
+	"strconv"
+)
+
+// intArithmetic implements the fixed-width integer modes, signed values are computed as int64
+// and unsigned ones as uint64, results that do not fit in the width fail with ErrOverflow
+type intArithmetic struct {
+	m      Mode
+	signed bool
+	bits   uint
+}
+
+func newIntArithmetic(m Mode) intArithmetic {
+	return intArithmetic{m: m, signed: m.signed(), bits: m.bits()}
+}
+
+func (a intArithmetic) mode() Mode {
+	return a.m
+}
+
+// parse accepts decimal literals of integers like 12 or 1e3, hexadecimal, octal and binary literals are the bits
+// of the number, so 0xff is -1 in ModeInt8 like it is formatted with WithBase(16)
+func (a intArithmetic) parse(literal string) (Value, bool) {
+	if i, ok := parseInteger(literal); ok {
+		if !i.IsUint64() || i.Uint64() > a.mask() {
+			return Value{}, false
+		}
+
+		u := i.Uint64()
+		if a.signed && a.bits < 64 && u > a.mask()>>1 {
+			u |= ^a.mask()
+		}
+		return intValue(a.m, int64(u)), true
+	}
+
+	r, ok := new(big.Rat).SetString(literal)
+	if !ok || !r.IsInt() {
+		return Value{}, false
+	}
+
+	return a.fromBig(r.Num())
+}
+
+// convert leaves non-integers and values that do not fit as they are, so 1.5 is never truncated to 1
+func (a intArithmetic) convert(v Value) Value {
+	if v.mode == a.m || v.mode < 0 {
+		return v
+	}
+
+	if r := v.Rat(); r != nil && r.IsInt() {
+		if c, ok := a.fromBig(r.Num()); ok {
+			return c
+		}
+	}
+
+	return v
+}
+
+// inexact is the error for a number that convert left as it is, it either does not fit
+// in the width of the integer mode or has a fraction the mode can not represent
+func inexact(v Value, m Mode) error {
+	r := v.Rat()
+	if r == nil {
+		return errors.Wrapf(ErrOverflow, "%s does not fit in %s", v, m)
+	}
+
+	if _, ok := newIntArithmetic(m).fromBig(new(big.Int).Quo(r.Num(), r.Denom())); !ok {
+		return errors.Wrapf(ErrOverflow, "%s does not fit in %s", v, m)
+	}
+
+	return errors.Wrapf(ErrDomain, "%s is not an integer", v)
+}
+
+func (a intArithmetic) fromBig(i *big.Int) (Value, bool) {
+	if a.signed {
+		if !i.IsInt64() || !a.fitsInt(i.Int64()) {
+			return Value{}, false
+		}
+		return intValue(a.m, i.Int64()), true
+	}
+
+	if !i.IsUint64() || !a.fitsUint(i.Uint64()) {
+		return Value{}, false
+	}
+	return intValue(a.m, int64(i.Uint64())), true
+}
+
+func (a intArithmetic) add(x, y Value) (Value, error) {
+	if a.signed {
+		r := x.int() + y.int()
+		return a.int(r, (r > x.int()) == (y.int() > 0))
+	}
+
+	r, carry := bits.Add64(x.uint(), y.uint(), 0)
+	return a.uint(r, carry == 0)
+}
+
+func (a intArithmetic) sub(x, y Value) (Value, error) {
+	if a.signed {
+		r := x.int() - y.int()
+		return a.int(r, (r < x.int()) == (y.int() > 0))
+	}
+
+	r, borrow := bits.Sub64(x.uint(), y.uint(), 0)
+	return a.uint(r, borrow == 0)
+}
+
+func (a intArithmetic) mul(x, y Value) (Value, error) {
+	if a.signed {
+		i, j := x.int(), y.int()
+		r := i * j
+		return a.int(r, i == 0 || r/i == j && !(i == -1 && j == math.MinInt64))
+	}
+
+	hi, lo := bits.Mul64(x.uint(), y.uint())
+	return a.uint(lo, hi == 0)
+}
+
+// div truncates toward zero like integer division in C
+func (a intArithmetic) div(x, y Value) (Value, error) {
+	if y.uint() == 0 {
+		return Value{}, ErrDivisionByZero
+	}
+
+	if a.signed {
+		return a.int(x.int()/y.int(), !(x.int() == math.MinInt64 && y.int() == -1))
+	}
+
+	return a.uint(x.uint()/y.uint(), true)
+}
+
+func (a intArithmetic) neg(x Value) (Value, error) {
+	if a.signed {
+		return a.int(-x.int(), x.int() != math.MinInt64)
+	}
+
+	return a.uint(-x.uint(), x.uint() == 0)
+}
+
+// pow multiplies with overflow checks, a negative exponent truncates 1 / x ^ -y toward zero
+func (a intArithmetic) pow(x, y Value) (Value, error) {
+	e := y.uint()
+	if a.signed && y.int() < 0 {
+		switch x.int() {
+		case 0:
+			return Value{}, ErrDivisionByZero
+		case 1:
+			return x, nil
+		case -1:
+			return a.int(1-2*(y.int()&1), true)
+		default:
+			return a.int(0, true)
+		}
+	}
+
+	result, base := intValue(a.m, 1), x
+	for ; e > 0; e >>= 1 {
+		var err error
+		if e&1 == 1 {
+			if result, err = a.mul(result, base); err != nil {
+				return Value{}, err
+			}
+		}
+
+		if e > 1 {
+			if base, err = a.mul(base, base); err != nil {
+				return Value{}, err
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (a intArithmetic) mod(x, y Value) (Value, error) {
+	if y.uint() == 0 {
+		return Value{}, ErrDivisionByZero
+	}
+
+	if !a.signed {
+		return a.uint(x.uint()%y.uint(), true)
+	}
+
+	r := x.int() % y.int()
+	if r != 0 && (r < 0) != (y.int() < 0) {
+		r += y.int()
+	}
+
+	return a.int(r, true)
+}
+
+func (a intArithmetic) floorDiv(x, y Value) (Value, error) {
+	if !a.signed || y.uint() == 0 {
+		return a.div(x, y)
+	}
+
+	q, err := a.div(x, y)
+	if err != nil {
+		return Value{}, err
+	}
+
+	if x.int()%y.int() != 0 && (x.int() < 0) != (y.int() < 0) {
+		return a.int(q.int()-1, true)
+	}
+
+	return q, nil
+}
+
+func (a intArithmetic) cmp(x, y Value) int {
+	switch {
+	case a.signed && x.int() < y.int(), !a.signed && x.uint() < y.uint():
+		return -1
+	case x.uint() == y.uint():
+		return 0
+	default:
+		return 1
+	}
+}
+
+func (a intArithmetic) bitwise(op string, x, y Value) (Value, error) {
+	switch op {
+	case bitAnd:
+		return intValue(a.m, x.int()&y.int()), nil
+	case bitOr:
+		return intValue(a.m, x.int()|y.int()), nil
+	case bitXor:
+		return intValue(a.m, x.int()^y.int()), nil
+	}
+
+	if a.signed && y.int() < 0 {
+		return Value{}, errors.Wrapf(ErrDomain, "negative shift count %s", y)
+	}
+
+	n := y.uint()
+	switch {
+	case op == shiftRight && a.signed:
+		return a.int(x.int()>>n, true)
+	case op == shiftRight:
+		return a.uint(x.uint()>>n, true)
+	case n >= uint64(a.bits):
+		// every bit is shifted out, Go shifts by 64 or more yield 0
+		return a.int(0, x.uint() == 0)
+	case a.signed:
+		r := x.int() << n
+		return a.int(r, r>>n == x.int())
+	default:
+		r := x.uint() << n
+		return a.uint(r, r>>n == x.uint())
+	}
+}
+
+func (a intArithmetic) complement(x Value) (Value, error) {
+	if a.signed {
+		return a.int(^x.int(), true)
+	}
+
+	return a.uint(^x.uint()&a.mask(), true)
+}
+
+func (a intArithmetic) format(v Value, _ int) string {
+	return v.String()
+}
+
+// int makes a signed result, ok is false when it overflowed int64
+func (a intArithmetic) int(i int64, ok bool) (Value, error) {
+	if !ok || !a.fitsInt(i) {
+		return Value{}, a.overflow()
+	}
+
+	return intValue(a.m, i), nil
+}
+
+// uint makes an unsigned result, ok is false when it overflowed uint64
+func (a intArithmetic) uint(u uint64, ok bool) (Value, error) {
+	if !ok || !a.fitsUint(u) {
+		return Value{}, a.overflow()
+	}
+
+	return intValue(a.m, int64(u)), nil
+}
+
+func (a intArithmetic) fitsInt(i int64) bool {
+	return a.bits == 64 || i >= -1<<(a.bits-1) && i < 1<<(a.bits-1)
+}
+
+func (a intArithmetic) fitsUint(u uint64) bool {
+	return u <= a.mask()
+}
+
+// mask has the lowest bits of the width set
+func (a intArithmetic) mask() uint64 {
+	return math.MaxUint64 >> (64 - a.bits)
+}
+
+func (a intArithmetic) overflow() error {
+	return errors.Wrapf(ErrOverflow, "result does not fit in %s", a.m)
+}
+
+// integerBase returns the base of hexadecimal, octal and binary literals like 0xff, 0o17 and 0b101, 0 for others
+func integerBase(literal string) int {
+	if len(literal) < 2 || literal[0] != '0' {
+		return 0
+	}
+
+	switch literal[1] {
+	case 'x', 'X':
+		return 16
+	case 'o', 'O':
+		return 8
+	case 'b', 'B':
+		return 2
+	default:
+		return 0
+	}
+}
+
+// parseInteger parses hexadecimal, octal and binary literals, it returns false for decimal ones
+func parseInteger(literal string) (*big.Int, bool) {
+	base := integerBase(literal)
+	if base == 0 {
+		return nil, false
+	}
+
+	return new(big.Int).SetString(literal[2:], base)
+}
+
+// bigBitwise implements the bitwise operators for the modes without a fixed width,
+// negative numbers behave like two's complement numbers with infinitely many bits
+func bigBitwise(a arithmetic, op string, x, y Value) (Value, error) {
+	i, err := bigInteger(x)
+	if err != nil {
+		return Value{}, err
+	}
+
+	j, err := bigInteger(y)
+	if err != nil {
+		return Value{}, err
+	}
+
+	r := new(big.Int)
+	switch op {
+	case bitAnd:
+		r.And(i, j)
+	case bitOr:
+		r.Or(i, j)
+	case bitXor:
+		r.Xor(i, j)
+	default:
+		if j.Sign() < 0 {
+			return Value{}, errors.Wrapf(ErrDomain, "negative shift count %s", y)
+		}
+
+		if op == shiftRight {
+			// shifting by more than the length yields 0 or -1 anyway
+			n := uint(i.BitLen()) + 1
+			if j.IsUint64() && j.Uint64() < uint64(n) {
+				n = uint(j.Uint64())
+			}
+			r.Rsh(i, n)
+			break
+		}
+
+		if j.Cmp(big.NewInt(maxExactExponent)) > 0 {
+			return Value{}, errors.Wrapf(ErrOverflow, "shift count %s is too large", y)
+		}
+		r.Lsh(i, uint(j.Uint64()))
+	}
+
+	return a.convert(Rat(new(big.Rat).SetInt(r))), nil
+}
+
+func bigComplement(a arithmetic, x Value) (Value, error) {
+	i, err := bigInteger(x)
+	if err != nil {
+		return Value{}, err
+	}
+
+	return a.convert(Rat(new(big.Rat).SetInt(i.Not(i)))), nil
+}
+
+func bigInteger(v Value) (*big.Int, error) {
+	r := v.Rat()
+	if r == nil || !r.IsInt() {
+		return nil, errors.Wrapf(ErrDomain, "%s is not an integer", v)
+	}
+
+	return r.Num(), nil
+}
+
+var basePrefixes = map[int]string{2: "0b", 8: "0o", 16: "0x"}
+
+// formatInteger formats an integer in base 2, 8 or 16 with the prefix of its literals,
+// the integer modes show negative numbers in two's complement of their width
+func formatInteger(v Value, base int) (string, bool) {
+	if v.mode.integer() {
+		u := v.uint()
+		if v.mode.signed() {
+			u &= newIntArithmetic(v.mode).mask()
+		}
+		return basePrefixes[base] + strconv.FormatUint(u, base), true
+	}
+
+	i, err := bigInteger(v)
+	if err != nil {
+		return "", false
+	}
+
+	if i.Sign() < 0 {
+		return "-" + basePrefixes[base] + i.Neg(i).Text(base), true
+	}
+
+	return basePrefixes[base] + i.Text(base), true
+}
@@ -0,0 +1,331 @@
+package calculator_test
+
+import (
+	"github.com/denismitr/gds/calculator"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCalculate_IntegerLiterals(t *testing.T) {
+	tt := []struct {
+		input  string
+		output string
+	}{
+		{input: "0xFF", output: "255"},
+		{input: "0x1f + 1", output: "32"},
+		{input: "0o17", output: "15"},
+		{input: "0b1010", output: "10"},
+		{input: "0B11 * 0X10", output: "48"},
+		{input: "0xFF & (1 << 4) | 0b1010", output: "26"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.input, func(t *testing.T) {
+			output, err := calculator.Calculate(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.output, output)
+		})
+	}
+}
+
+func TestCalculate_MalformedIntegerLiterals(t *testing.T) {
+	for _, input := range []string{"0x", "0xG", "0b102", "0o8", "1 + 0b"} {
+		t.Run(input, func(t *testing.T) {
+			_, err := calculator.Calculate(input)
+			var syntaxErr *calculator.SyntaxError
+			require.True(t, errors.As(err, &syntaxErr), "got %v", err)
+			assert.Contains(t, syntaxErr.Msg, "malformed number")
+		})
+	}
+}
+
+func TestCalculate_BitwiseOperators(t *testing.T) {
+	tt := []struct {
+		input  string
+		output string
+	}{
+		{input: "12 & 10", output: "8"},
+		{input: "12 | 10", output: "14"},
+		{input: "12 ~ 10", output: "6"},
+		{input: "~5", output: "-6"},
+		{input: "1 << 10", output: "1024"},
+		{input: "1024 >> 3", output: "128"},
+		{input: "-7 >> 1", output: "-4"},
+		{input: "1 << 2 + 1", output: "8"},
+		{input: "1 | 2 ~ 3 & 4", output: "3"},
+		{input: "6 & 3 == 2", output: "true"},
+		// unlike in C the bitwise operators bind tighter than comparisons
+		{input: "2 & 1 == 0", output: "true"},
+		{input: "1 | 2 == 3", output: "true"},
+		{input: "4 ~ 4 != 0", output: "false"},
+		{input: "3 < 4 & 5", output: "true"},
+		{input: "5 < 6 & 5", output: "false"},
+		{input: "-1 & 0xFF", output: "255"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.input, func(t *testing.T) {
+			output, err := calculator.Calculate(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.output, output)
+		})
+	}
+}
+
+func TestCalculate_BitwiseOperatorErrors(t *testing.T) {
+	tt := []struct {
+		input string
+		err   error
+	}{
+		{input: "2.5 & 1", err: calculator.ErrDomain},
+		{input: "~0.5", err: calculator.ErrDomain},
+		{input: "1 << -1", err: calculator.ErrDomain},
+		{input: "1 << 100000", err: calculator.ErrOverflow},
+		{input: "true & 1", err: calculator.ErrType},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.input, func(t *testing.T) {
+			_, err := calculator.Calculate(tc.input)
+			assert.True(t, errors.Is(err, tc.err), "got %v", err)
+		})
+	}
+}
+
+func TestCalculate_IntegerModes(t *testing.T) {
+	tt := []struct {
+		mode   calculator.Mode
+		input  string
+		output string
+	}{
+		{mode: calculator.ModeInt8, input: "100 + 27", output: "127"},
+		{mode: calculator.ModeInt8, input: "-128", output: "-128"},
+		{mode: calculator.ModeInt8, input: "0xFF", output: "-1"},
+		{mode: calculator.ModeInt8, input: "0x80", output: "-128"},
+		{mode: calculator.ModeInt8, input: "~0", output: "-1"},
+		{mode: calculator.ModeInt8, input: "-7 / 2", output: "-3"},
+		{mode: calculator.ModeInt8, input: "-7 // 2", output: "-4"},
+		{mode: calculator.ModeInt8, input: "-7 % 3", output: "2"},
+		{mode: calculator.ModeInt8, input: "2 ^ 6", output: "64"},
+		{mode: calculator.ModeInt8, input: "2 ^ -1", output: "0"},
+		{mode: calculator.ModeInt8, input: "(-1) ^ -3", output: "-1"},
+		{mode: calculator.ModeInt8, input: "7.0", output: "7"},
+		{mode: calculator.ModeInt8, input: "1e2", output: "100"},
+		{mode: calculator.ModeInt8, input: "sqrt(81)", output: "9"},
+		{mode: calculator.ModeInt8, input: "round(-128)", output: "-128"},
+		{mode: calculator.ModeInt8, input: "-64 >> 10", output: "-1"},
+		{mode: calculator.ModeInt16, input: "0x7FFF", output: "32767"},
+		{mode: calculator.ModeInt32, input: "1 << 30", output: "1073741824"},
+		{mode: calculator.ModeInt64, input: "9223372036854775807", output: "9223372036854775807"},
+		{mode: calculator.ModeInt64, input: "-9223372036854775808", output: "-9223372036854775808"},
+		{mode: calculator.ModeInt64, input: "3037000499 * 3037000499", output: "9223372030926249001"},
+		{mode: calculator.ModeUint8, input: "200 + 55", output: "255"},
+		{mode: calculator.ModeUint8, input: "~1", output: "254"},
+		{mode: calculator.ModeUint8, input: "255 >> 4", output: "15"},
+		{mode: calculator.ModeUint16, input: "0xFFFF", output: "65535"},
+		{mode: calculator.ModeUint32, input: "1 << 31", output: "2147483648"},
+		{mode: calculator.ModeUint64, input: "0xFFFFFFFFFFFFFFFF", output: "18446744073709551615"},
+		{mode: calculator.ModeUint64, input: "18446744073709551615 / 2 > 1 << 62", output: "true"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.mode.String()+" "+tc.input, func(t *testing.T) {
+			c, err := calculator.New(calculator.WithMode(tc.mode))
+			require.NoError(t, err)
+
+			output, err := c.Calculate(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.output, output)
+			assert.Equal(t, tc.mode, c.Mode())
+		})
+	}
+}
+
+func TestCalculate_IntegerOverflow(t *testing.T) {
+	tt := []struct {
+		mode  calculator.Mode
+		input string
+		err   error
+	}{
+		{mode: calculator.ModeInt8, input: "127 + 1", err: calculator.ErrOverflow},
+		{mode: calculator.ModeInt8, input: "-128 - 1", err: calculator.ErrOverflow},
+		{mode: calculator.ModeInt8, input: "16 * 8", err: calculator.ErrOverflow},
+		{mode: calculator.ModeInt8, input: "-(-128)", err: calculator.ErrOverflow},
+		{mode: calculator.ModeInt8, input: "-128 / -1", err: calculator.ErrOverflow},
+		{mode: calculator.ModeInt8, input: "abs(-128)", err: calculator.ErrOverflow},
+		{mode: calculator.ModeInt8, input: "2 ^ 7", err: calculator.ErrOverflow},
+		{mode: calculator.ModeInt8, input: "1 << 7", err: calculator.ErrOverflow},
+		{mode: calculator.ModeInt8, input: "1 << 8", err: calculator.ErrOverflow},
+		{mode: calculator.ModeInt8, input: "1 / 0", err: calculator.ErrDivisionByZero},
+		{mode: calculator.ModeInt8, input: "1 % 0", err: calculator.ErrDivisionByZero},
+		{mode: calculator.ModeInt8, input: "0 ^ -1", err: calculator.ErrDivisionByZero},
+		{mode: calculator.ModeInt8, input: "1 << -1", err: calculator.ErrDomain},
+		{mode: calculator.ModeInt8, input: "exp(5)", err: calculator.ErrOverflow},
+		{mode: calculator.ModeInt64, input: "exp(50)", err: calculator.ErrOverflow},
+		{mode: calculator.ModeInt64, input: "9223372036854775807 + 1", err: calculator.ErrOverflow},
+		{mode: calculator.ModeInt64, input: "-9223372036854775807 - 2", err: calculator.ErrOverflow},
+		{mode: calculator.ModeInt64, input: "3037000500 * 3037000500", err: calculator.ErrOverflow},
+		{mode: calculator.ModeInt64, input: "-1 * -9223372036854775808", err: calculator.ErrOverflow},
+		{mode: calculator.ModeInt64, input: "1 << 63", err: calculator.ErrOverflow},
+		{mode: calculator.ModeUint8, input: "255 + 1", err: calculator.ErrOverflow},
+		{mode: calculator.ModeUint8, input: "0 - 1", err: calculator.ErrOverflow},
+		{mode: calculator.ModeUint8, input: "-1", err: calculator.ErrOverflow},
+		{mode: calculator.ModeUint8, input: "1 << 8", err: calculator.ErrOverflow},
+		{mode: calculator.ModeUint64, input: "18446744073709551615 + 1", err: calculator.ErrOverflow},
+		{mode: calculator.ModeUint64, input: "4294967296 * 4294967296", err: calculator.ErrOverflow},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.mode.String()+" "+tc.input, func(t *testing.T) {
+			c, err := calculator.New(calculator.WithMode(tc.mode))
+			require.NoError(t, err)
+
+			_, err = c.Calculate(tc.input)
+			assert.True(t, errors.Is(err, tc.err), "got %v", err)
+		})
+	}
+}
+
+func TestCalculate_IntegerLiteralsOutOfRange(t *testing.T) {
+	c, err := calculator.New(calculator.WithMode(calculator.ModeInt8))
+	require.NoError(t, err)
+
+	for _, input := range []string{"128", "0x100", "1e10", "-129", "1 + 200"} {
+		_, err := c.Calculate(input)
+		assert.True(t, errors.Is(err, calculator.ErrOverflow), "%s: got %v", input, err)
+	}
+
+	_, err = c.Calculate("0xFFFFFFFFFFFFFFFF")
+	assert.Equal(t, "0xFFFFFFFFFFFFFFFF at position 0: number does not fit in int8: overflow", err.Error())
+
+	tt := []struct {
+		mode  calculator.Mode
+		input string
+	}{
+		{mode: calculator.ModeInt16, input: "40000"},
+		{mode: calculator.ModeInt64, input: "18446744073709551615"},
+		{mode: calculator.ModeUint8, input: "-1"},
+		{mode: calculator.ModeFloat, input: "1e999 + 1"},
+	}
+
+	for _, tc := range tt {
+		c, err := calculator.New(calculator.WithMode(tc.mode))
+		require.NoError(t, err)
+
+		_, err = c.Calculate(tc.input)
+		assert.True(t, errors.Is(err, calculator.ErrOverflow), "%s %s: got %v", tc.mode, tc.input, err)
+	}
+}
+
+func TestCalculate_IntegerFractions(t *testing.T) {
+	c, err := calculator.New(calculator.WithMode(calculator.ModeInt32))
+	require.NoError(t, err)
+
+	// non-integers are not truncated, so 0.5 + 0.5 is not 0
+	for _, input := range []string{"1.5", "0.5 + 0.5", "-2.5", "1e-1", "sqrt(2)", "exp(1)", "pi", "2 * e"} {
+		t.Run(input, func(t *testing.T) {
+			_, err := c.Calculate(input)
+			assert.True(t, errors.Is(err, calculator.ErrDomain), "got %v", err)
+		})
+	}
+
+	_, err = c.Calculate("1 + 1.5")
+	assert.Equal(t, "1.5 at position 4: number is not an integer: argument out of domain", err.Error())
+
+	half := calculator.Function{
+		MinArgs: 1,
+		MaxArgs: 1,
+		Call: func(args []calculator.Value) (calculator.Value, error) {
+			return calculator.Float(args[0].Float64() / 2), nil
+		},
+	}
+	require.NoError(t, c.RegisterFunction("half", half))
+
+	v, err := c.Evaluate("half(4)", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "2", v.String())
+
+	_, err = c.Evaluate("half(3)", nil)
+	assert.True(t, errors.Is(err, calculator.ErrDomain), "got %v", err)
+}
+
+func TestEvaluate_IntegerVariables(t *testing.T) {
+	c, err := calculator.New(calculator.WithMode(calculator.ModeInt16))
+	require.NoError(t, err)
+
+	v, err := c.Evaluate("x * 2 + y", map[string]calculator.Value{"x": calculator.Float(100), "y": calculator.Int(-1)})
+	require.NoError(t, err)
+	assert.Equal(t, "199", v.String())
+	assert.Equal(t, calculator.ModeInt16, v.Mode())
+	assert.Equal(t, 199.0, v.Float64())
+
+	_, err = c.Evaluate("x", map[string]calculator.Value{"x": calculator.Uint(1 << 40)})
+	assert.True(t, errors.Is(err, calculator.ErrDomain), "got %v", err)
+
+	_, err = c.Evaluate("x", map[string]calculator.Value{"x": calculator.Float(100.9)})
+	assert.True(t, errors.Is(err, calculator.ErrDomain), "got %v", err)
+
+	v, err = calculator.Evaluate("x + 1", map[string]calculator.Value{"x": calculator.Uint(1 << 40)})
+	require.NoError(t, err)
+	assert.Equal(t, float64(1<<40+1), v.Float64())
+}
+
+func TestCalculator_WithBase(t *testing.T) {
+	tt := []struct {
+		mode   calculator.Mode
+		base   int
+		input  string
+		output string
+	}{
+		{mode: calculator.ModeFloat, base: 16, input: "0xFF & (1 << 4) | 0b1010", output: "0x1a"},
+		{mode: calculator.ModeFloat, base: 2, input: "5", output: "0b101"},
+		{mode: calculator.ModeFloat, base: 8, input: "-8", output: "-0o10"},
+		{mode: calculator.ModeFloat, base: 16, input: "1 / 2", output: "0.5"},
+		{mode: calculator.ModeFloat, base: 16, input: "1 < 2", output: "true"},
+		{mode: calculator.ModeRational, base: 16, input: "2 ^ 64", output: "0x10000000000000000"},
+		{mode: calculator.ModeInt8, base: 16, input: "-1", output: "0xff"},
+		{mode: calculator.ModeInt8, base: 2, input: "-128", output: "0b10000000"},
+		{mode: calculator.ModeInt32, base: 16, input: "-2", output: "0xfffffffe"},
+		{mode: calculator.ModeUint16, base: 8, input: "0xFFFF", output: "0o177777"},
+		{mode: calculator.ModeUint64, base: 16, input: "~0", output: "0xffffffffffffffff"},
+		{mode: calculator.ModeInt8, base: 10, input: "0xFF", output: "-1"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.mode.String()+" "+tc.input, func(t *testing.T) {
+			c, err := calculator.New(calculator.WithMode(tc.mode), calculator.WithBase(tc.base))
+			require.NoError(t, err)
+
+			output, err := c.Calculate(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.output, output)
+		})
+	}
+
+	_, err := calculator.New(calculator.WithBase(3))
+	assert.True(t, errors.Is(err, calculator.ErrInvalidBase), "got %v", err)
+}
+
+func TestRPN_Complement(t *testing.T) {
+	tokens, err := calculator.ToRPN("~x & 0xF0")
+	require.NoError(t, err)
+	assert.Equal(t, "x compl 0xF0 &", tokens.String())
+
+	v, err := calculator.EvalRPNString("5 compl 12 ~", nil)
+	require.NoError(t, err)
+	assert.Equal(t, calculator.Float(-6^12), v)
+
+	formatted, err := calculator.FormatInfix("(a|b)&~c<<1")
+	require.NoError(t, err)
+	assert.Equal(t, "(a | b) & ~c << 1", formatted)
+}
+
+func TestParse_IntegerModes(t *testing.T) {
+	c, err := calculator.New(calculator.WithMode(calculator.ModeInt32))
+	require.NoError(t, err)
+
+	_, err = c.Parse("x / 2")
+	assert.True(t, errors.Is(err, calculator.ErrInvalidMode), "got %v", err)
+}
@@ -0,0 +1,156 @@
+package calculator
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// lex splits the input into tokens, whitespace between tokens is optional and ignored,
+// a semicolon or a new line separates statements
+func lex(input string) ([]token, error) {
+	var tokens []token
+
+	for pos := 0; pos < len(input); {
+		r, width := utf8.DecodeRuneInString(input[pos:])
+
+		switch {
+		case r == ';' || r == '\n':
+			tokens = append(tokens, token{value: string(r), kind: separator, pos: pos})
+			pos += width
+		case operatorAt(input, pos) != "":
+			op := operatorAt(input, pos)
+			tokens = append(tokens, token{value: op, kind: operator, pos: pos})
+			pos += len(op)
+		case r == '=':
+			tokens = append(tokens, token{value: "=", kind: assign, pos: pos})
+			pos += width
+		case r == ':':
+			tokens = append(tokens, token{value: ":", kind: colon, pos: pos})
+			pos += width
+		case unicode.IsSpace(r):
+			pos += width
+		case isDigit(r) || r == '.':
+			end, err := scanNumber(input, pos)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{value: input[pos:end], kind: number, pos: pos})
+			pos = end
+		case isLetter(r):
+			start := pos
+			for pos < len(input) && (isLetter(rune(input[pos])) || isDigit(rune(input[pos]))) {
+				pos++
+			}
+			word, kind := input[start:pos], identifier
+			if isKeyword(word) && len(tokens) > 0 && endsOperand(tokens[len(tokens)-1]) {
+				kind = operator
+			}
+			tokens = append(tokens, token{value: word, kind: kind, pos: start})
+		case r == ',':
+			tokens = append(tokens, token{value: ",", kind: comma, pos: pos})
+			pos += width
+		case r == '(' || r == ')':
+			tokens = append(tokens, token{value: string(r), kind: bracket, pos: pos})
+			pos += width
+		default:
+			return nil, &SyntaxError{Pos: pos, Msg: fmt.Sprintf("unknown token %q", r), Err: ErrUnknownToken}
+		}
+	}
+
+	return tokens, nil
+}
+
+// longOperators are checked before the single character ones, so that // is not read as two divisions
+var longOperators = []string{floorDiv, eq, ne, le, ge, and, or, shiftLeft, shiftRight}
+
+// operatorAt returns the operator that starts at pos or an empty string
+func operatorAt(input string, pos int) string {
+	for _, op := range longOperators {
+		if strings.HasPrefix(input[pos:], op) {
+			return op
+		}
+	}
+
+	if strings.IndexByte("+-*/%^<>!?&|~", input[pos]) >= 0 {
+		return input[pos : pos+1]
+	}
+
+	return ""
+}
+
+// isKeyword reports whether the word is an operator after a complete operand like in 3 km in m,
+// elsewhere it is a name, so in and to can still be variables
+func isKeyword(word string) bool {
+	return word == inUnit || word == toUnit
+}
+
+// endsOperand reports whether an operand can end with the token
+func endsOperand(t token) bool {
+	return t.kind == number || t.kind == identifier || t.value == rightBracket
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// scanNumber returns the end of a decimal literal like 12, 1.5, .5, 2. or 6.02e23
+// or an integer literal like 0xff, 0o17 or 0b101 that starts at pos
+func scanNumber(input string, pos int) (int, error) {
+	if integerBase(input[pos:]) != 0 {
+		return scanInteger(input, pos)
+	}
+
+	start := pos
+	digits := 0
+	for pos < len(input) && isDigit(rune(input[pos])) {
+		pos++
+		digits++
+	}
+
+	if pos < len(input) && input[pos] == '.' {
+		pos++
+		for pos < len(input) && isDigit(rune(input[pos])) {
+			pos++
+			digits++
+		}
+	}
+
+	if digits == 0 {
+		return 0, syntaxError(start, "malformed number %q", input[start:pos])
+	}
+
+	if pos < len(input) && (input[pos] == 'e' || input[pos] == 'E') {
+		pos++
+		if pos < len(input) && (input[pos] == '+' || input[pos] == '-') {
+			pos++
+		}
+
+		exponent := pos
+		for pos < len(input) && isDigit(rune(input[pos])) {
+			pos++
+		}
+
+		if pos == exponent {
+			return 0, syntaxError(start, "malformed number %q", input[start:pos])
+		}
+	}
+
+	return pos, nil
+}
+
+// scanInteger returns the end of a hexadecimal, octal or binary literal,
+// letters and digits right after it are a part of the malformed literal
+func scanInteger(input string, pos int) (int, error) {
+	end := pos + 2
+	for end < len(input) && (isLetter(rune(input[end])) || isDigit(rune(input[end]))) {
+		end++
+	}
+
+	if _, ok := parseInteger(input[pos:end]); !ok {
+		return 0, syntaxError(pos, "malformed number %q", input[pos:end])
+	}
+
+	return end, nil
+}
@@ -0,0 +1,199 @@
+package calculator
+
+import "math"
+
+type associativity int8
+
+const (
+	leftAssociative associativity = iota
+	rightAssociative
+)
+
+// operatorInfo describes how an operator is parsed and evaluated,
+// operators with a higher precedence bind tighter
+type operatorInfo struct {
+	precedence    int
+	associativity associativity
+	// operands is the type the operator works with, the operands of == and != can be
+	// of any type as long as both are the same
+	operands Type
+	equality bool
+	// ternary is the conditional operator, it has no function because it evaluates only one of its branches
+	ternary bool
+	unary   func(a arithmetic, x Value) (Value, error)
+	binary  func(a arithmetic, x, y Value) (Value, error)
+}
+
+func (oi operatorInfo) arity() int {
+	switch {
+	case oi.ternary:
+		return 3
+	case oi.unary != nil:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// typeError checks the type of an operand, first is the first operand of the operator
+func (oi operatorInfo) typeError(op string, pos int, first, v Value) error {
+	want := oi.operands
+	if oi.equality {
+		want = first.Type()
+	}
+
+	if v.Type() != want {
+		return &TypeError{Op: op, Pos: pos, Want: want, Got: v.Type()}
+	}
+
+	return nil
+}
+
+const (
+	pow      string = "^"
+	mod      string = "%"
+	floorDiv string = "//"
+	eq       string = "=="
+	ne       string = "!="
+	lt       string = "<"
+	le       string = "<="
+	gt       string = ">"
+	ge       string = ">="
+	and      string = "&&"
+	or       string = "||"
+	not      string = "!"
+	// the bitwise operators follow Lua, ^ is already taken by exponentiation,
+	// so ~ is the exclusive or as a binary operator and the complement as a prefix one
+	bitAnd     string = "&"
+	bitOr      string = "|"
+	bitXor     string = "~"
+	complement string = "~"
+	shiftLeft  string = "<<"
+	shiftRight string = ">>"
+	// inUnit and toUnit convert a quantity to the unit on the right like in 3 km in m,
+	// they are words, so they are lexed from identifiers
+	inUnit string = "in"
+	toUnit string = "to"
+	// question is the first half of the conditional operator, in reverse polish notation
+	// a ? b : c becomes a b c ?: with a single operator of three operands
+	question    string = "?"
+	conditional string = "?:"
+)
+
+// binaryOperators is the operator table consulted by the shunting-yard conversion and the evaluator.
+// The bitwise operators and the shifts are ordered like in Lua, which differs from C: they bind tighter
+// than comparisons, so x & 1 == 0 is (x & 1) == 0 rather than x & (1 == 0) that would fail on a bool operand.
+// The unit conversions bind looser than anything else, so the whole expression on their left is converted.
+var binaryOperators = map[string]operatorInfo{
+	inUnit:     {precedence: 0, associativity: leftAssociative, binary: convertUnit},
+	toUnit:     {precedence: 0, associativity: leftAssociative, binary: convertUnit},
+	or:         {precedence: 2, associativity: leftAssociative, operands: BoolType, binary: logical(or)},
+	and:        {precedence: 3, associativity: leftAssociative, operands: BoolType, binary: logical(and)},
+	eq:         {precedence: 4, associativity: leftAssociative, equality: true, binary: comparison(eq)},
+	ne:         {precedence: 4, associativity: leftAssociative, equality: true, binary: comparison(ne)},
+	lt:         {precedence: 5, associativity: leftAssociative, binary: comparison(lt)},
+	le:         {precedence: 5, associativity: leftAssociative, binary: comparison(le)},
+	gt:         {precedence: 5, associativity: leftAssociative, binary: comparison(gt)},
+	ge:         {precedence: 5, associativity: leftAssociative, binary: comparison(ge)},
+	bitOr:      {precedence: 6, associativity: leftAssociative, binary: bitwise(bitOr)},
+	bitXor:     {precedence: 7, associativity: leftAssociative, binary: bitwise(bitXor)},
+	bitAnd:     {precedence: 8, associativity: leftAssociative, binary: bitwise(bitAnd)},
+	shiftLeft:  {precedence: 9, associativity: leftAssociative, binary: bitwise(shiftLeft)},
+	shiftRight: {precedence: 9, associativity: leftAssociative, binary: bitwise(shiftRight)},
+	plus:       {precedence: 10, associativity: leftAssociative, binary: arithmetic.add},
+	minus:      {precedence: 10, associativity: leftAssociative, binary: arithmetic.sub},
+	mul:        {precedence: 20, associativity: leftAssociative, binary: arithmetic.mul},
+	div:        {precedence: 20, associativity: leftAssociative, binary: arithmetic.div},
+	mod:        {precedence: 20, associativity: leftAssociative, binary: arithmetic.mod},
+	floorDiv:   {precedence: 20, associativity: leftAssociative, binary: arithmetic.floorDiv},
+	pow:        {precedence: 40, associativity: rightAssociative, binary: arithmetic.pow},
+}
+
+// unaryOperators are prefix operators, they bind tighter than multiplication
+// but looser than exponentiation, so -2^2 is -4 and 2^-1 is 0.5
+var unaryOperators = map[string]operatorInfo{
+	plus:       {precedence: 30, associativity: rightAssociative, unary: func(_ arithmetic, x Value) (Value, error) { return x, nil }},
+	minus:      {precedence: 30, associativity: rightAssociative, unary: arithmetic.neg},
+	not:        {precedence: 30, associativity: rightAssociative, operands: BoolType, unary: func(_ arithmetic, x Value) (Value, error) { return Bool(!x.Bool()), nil }},
+	complement: {precedence: 30, associativity: rightAssociative, unary: arithmetic.complement},
+}
+
+// implicitMultiplication is the multiplication by a unit, it binds tighter than the prefix operators
+// and looser than exponentiation, so -3 m is -(3 m), 6 m / 2 s is 3 m/s and 2 m^2 is 2 (m^2)
+var implicitMultiplication = operatorInfo{precedence: 35, associativity: leftAssociative, binary: arithmetic.mul}
+
+// conditionalOperator binds looser than anything but the unit conversions and is right associative,
+// so a ? b : c ? d : e is a ? b : (c ? d : e)
+var conditionalOperator = operatorInfo{precedence: 1, associativity: rightAssociative, operands: BoolType, ternary: true}
+
+func bitwise(op string) func(a arithmetic, x, y Value) (Value, error) {
+	return func(a arithmetic, x, y Value) (Value, error) {
+		return a.bitwise(op, x, y)
+	}
+}
+
+// comparison compares numbers or, for == and !=, booleans,
+// NaN is not equal to anything including itself
+func comparison(op string) func(a arithmetic, x, y Value) (Value, error) {
+	return func(a arithmetic, x, y Value) (Value, error) {
+		if x.Type() == BoolType {
+			return Bool(x.Bool() == y.Bool() == (op == eq)), nil
+		}
+
+		if x.mode == ModeFloat && (math.IsNaN(x.f) || math.IsNaN(y.f)) {
+			return Bool(op == ne), nil
+		}
+
+		c := a.cmp(x, y)
+		switch op {
+		case eq:
+			return Bool(c == 0), nil
+		case ne:
+			return Bool(c != 0), nil
+		case lt:
+			return Bool(c < 0), nil
+		case le:
+			return Bool(c <= 0), nil
+		case gt:
+			return Bool(c > 0), nil
+		default:
+			return Bool(c >= 0), nil
+		}
+	}
+}
+
+// logical evaluates && and || when both operands are already known,
+// compiled programs skip the right operand when the left one decides the result
+func logical(op string) func(a arithmetic, x, y Value) (Value, error) {
+	return func(_ arithmetic, x, y Value) (Value, error) {
+		if op == and {
+			return Bool(x.Bool() && y.Bool()), nil
+		}
+
+		return Bool(x.Bool() || y.Bool()), nil
+	}
+}
+
+// convertUnit applies in and to when neither operand has a unit, so the number stays as it is,
+// quantities are converted by the calculator
+func convertUnit(_ arithmetic, x, _ Value) (Value, error) {
+	return x, nil
+}
+
+func lookupOperator(t token) (operatorInfo, bool) {
+	if t.value == question || t.value == conditional {
+		return conditionalOperator, true
+	}
+
+	if t.implicit {
+		return implicitMultiplication, true
+	}
+
+	if t.unary {
+		oi, ok := unaryOperators[t.value]
+		return oi, ok
+	}
+
+	oi, ok := binaryOperators[t.value]
+	return oi, ok
+}
@@ -0,0 +1,296 @@
+package calculator
+
+import (
+	"github.com/pkg/errors"
+	"sync"
+)
+
+type opcode int8
+
+const (
+	opConst opcode = iota
+	opLoad
+	// opLoadUnit loads a name in the position of a unit, it is looked up among the units before the variables
+	opLoadUnit
+	opUnary
+	opBinary
+	opCall
+	// opJumpUnless pops the condition of ?: and jumps to the second branch when it is false
+	opJumpUnless
+	opJump
+	// opShortCircuit jumps over the right operand of && and || when the left one decides the result,
+	// otherwise it pops the left operand and the right one becomes the result
+	opShortCircuit
+	// opAssertBool checks that the right operand of && and || is a bool
+	opAssertBool
+)
+
+// instruction is a step of a compiled program, the program is the syntax tree in postfix order
+// with operators and functions resolved, so evaluation needs no lookups except for variables.
+// Conditionals and logical operators are compiled to jumps, so the skipped operands are never evaluated.
+type instruction struct {
+	op     opcode
+	value  Value
+	name   string
+	pos    int
+	oi     operatorInfo
+	fn     callable
+	args   int
+	target int
+}
+
+// Program is a compiled expression that can be evaluated many times with different variables,
+// it is safe for concurrent use
+type Program struct {
+	calculator *Calculator
+	root       *node
+	code       []instruction
+	maxStack   int
+}
+
+var stackPool = sync.Pool{
+	New: func() interface{} {
+		stack := make([]Value, 0, 16)
+		return &stack
+	},
+}
+
+// Compile compiles an expression for float64 evaluation
+func Compile(expr string) (*Program, error) {
+	return defaultCalculator.Compile(expr)
+}
+
+// Compile parses the expression once, folds the parts that do not depend on variables
+// and returns a program bound to the mode and the functions of the calculator
+func (c *Calculator) Compile(expr string) (*Program, error) {
+	queue, err := reversePolishTokenizer(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.program(queue)
+}
+
+// program compiles an expression in reverse polish notation
+func (c *Calculator) program(queue tokenQueue) (*Program, error) {
+	root, err := parseTree(queue)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.bind(root); err != nil {
+		return nil, err
+	}
+
+	return c.compile(root), nil
+}
+
+// compile folds a bound tree and emits the instructions
+func (c *Calculator) compile(root *node) *Program {
+	p := Program{calculator: c, root: c.fold(root)}
+	p.emit(p.root, 0)
+	return &p
+}
+
+// Eval evaluates the program, identifiers are looked up in env first and among the constants after that,
+// names in the position of a unit are looked up among the units before env.
+// In ModeFloat it does not allocate unless it fails or calls a function that allocates.
+func (p *Program) Eval(env map[string]Value) (Value, error) {
+	return p.eval(env, nil)
+}
+
+// eval evaluates the program and appends every executed instruction to trace unless it is nil
+func (p *Program) eval(env map[string]Value, trace *[]ReductionStep) (Value, error) {
+	sp := stackPool.Get().(*[]Value)
+	stack := (*sp)[:0]
+	if cap(stack) < p.maxStack {
+		stack = make([]Value, 0, p.maxStack)
+	}
+
+	v, err := p.run(stack, env, trace)
+
+	*sp = stack[:0]
+	stackPool.Put(sp)
+	return v, err
+}
+
+func (p *Program) run(stack []Value, env map[string]Value, trace *[]ReductionStep) (Value, error) {
+	a := p.calculator.arithmetic
+
+	for pc := 0; pc < len(p.code); {
+		in := &p.code[pc]
+		pc++
+
+		var operands []Value
+		if trace != nil {
+			operands = in.operands(stack)
+		}
+
+		switch in.op {
+		case opConst:
+			stack = append(stack, in.value)
+		case opLoad, opLoadUnit:
+			v, err := p.calculator.lookup(token{value: in.name, pos: in.pos, unit: in.op == opLoadUnit}, env)
+			if err != nil {
+				return Value{}, err
+			}
+			stack = append(stack, v)
+		case opUnary:
+			last := len(stack) - 1
+			if err := in.oi.typeError(in.name, in.pos, stack[last], stack[last]); err != nil {
+				return Value{}, err
+			}
+
+			var v Value
+			var err error
+			if stack[last].mode == modeQuantity {
+				v, err = p.calculator.unaryQuantity(in.name, stack[last])
+			} else {
+				v, err = in.oi.unary(a, stack[last])
+			}
+			if err != nil {
+				return Value{}, errors.Wrapf(err, "%s%s at position %d", in.name, stack[last], in.pos)
+			}
+			stack[last] = v
+		case opBinary:
+			last := len(stack) - 1
+			x, y := stack[last-1], stack[last]
+			if err := in.oi.typeError(in.name, in.pos, x, x); err != nil {
+				return Value{}, err
+			}
+			if err := in.oi.typeError(in.name, in.pos, x, y); err != nil {
+				return Value{}, err
+			}
+
+			var v Value
+			var err error
+			if x.mode == modeQuantity || y.mode == modeQuantity {
+				v, err = p.calculator.binaryQuantity(in.name, x, y)
+			} else {
+				v, err = in.oi.binary(a, x, y)
+			}
+			if err != nil {
+				return Value{}, errors.Wrapf(err, "%s %s %s at position %d", x, in.name, y, in.pos)
+			}
+			stack = stack[:last]
+			stack[last-1] = v
+		case opCall:
+			first := len(stack) - in.args
+			if err := argumentsError(in.name, in.pos, stack[first:]); err != nil {
+				return Value{}, err
+			}
+
+			var v Value
+			var err error
+			if hasQuantity(stack[first:]) {
+				v, err = p.calculator.callQuantity(in.name, in.fn, stack[first:])
+			} else {
+				v, err = in.fn.call(a, stack[first:])
+			}
+			if err != nil {
+				return Value{}, errors.Wrapf(err, "%s at position %d", in.name, in.pos)
+			}
+			stack = append(stack[:first], v)
+		case opJumpUnless:
+			last := len(stack) - 1
+			if err := in.oi.typeError(in.name, in.pos, stack[last], stack[last]); err != nil {
+				return Value{}, err
+			}
+
+			if !stack[last].Bool() {
+				pc = in.target
+			}
+			stack = stack[:last]
+		case opJump:
+			pc = in.target
+		case opShortCircuit:
+			last := len(stack) - 1
+			if err := in.oi.typeError(in.name, in.pos, stack[last], stack[last]); err != nil {
+				return Value{}, err
+			}
+
+			// false decides && and true decides ||
+			if stack[last].Bool() == (in.name == or) {
+				pc = in.target
+			} else {
+				stack = stack[:last]
+			}
+		case opAssertBool:
+			last := len(stack) - 1
+			if err := in.oi.typeError(in.name, in.pos, stack[last], stack[last]); err != nil {
+				return Value{}, err
+			}
+		}
+
+		if trace != nil && in.op != opAssertBool {
+			*trace = append(*trace, in.step(operands, stack, pc == in.target))
+		}
+	}
+
+	// only the program of the zero Expr has no instructions
+	if len(stack) == 0 {
+		return Value{}, emptyExpression()
+	}
+
+	return stack[0], nil
+}
+
+// emit appends the instructions of the subtree and tracks the depth of the evaluation stack
+func (p *Program) emit(n *node, depth int) {
+	switch {
+	case n.kind == conditionalNode:
+		p.emit(n.args[0], depth)
+		jumpUnless := p.jump(opJumpUnless, n)
+		p.emit(n.args[1], depth)
+		jump := p.jump(opJump, n)
+		p.code[jumpUnless].target = len(p.code)
+		p.emit(n.args[2], depth)
+		p.code[jump].target = len(p.code)
+		return
+	case n.kind == binaryNode && (n.name == and || n.name == or):
+		p.emit(n.args[0], depth)
+		shortCircuit := p.jump(opShortCircuit, n)
+		p.emit(n.args[1], depth)
+		p.code = append(p.code, instruction{op: opAssertBool, name: n.name, pos: n.pos, oi: binaryOperators[n.name]})
+		p.code[shortCircuit].target = len(p.code)
+		return
+	}
+
+	for i, arg := range n.args {
+		p.emit(arg, depth+i)
+	}
+
+	in := instruction{name: n.name, pos: n.pos, args: len(n.args)}
+	switch n.kind {
+	case numberNode:
+		in.op, in.value = opConst, n.value
+	case variableNode:
+		in.op = opLoad
+		if n.unit {
+			in.op = opLoadUnit
+		}
+	case unaryNode:
+		in.op, in.oi = opUnary, unaryOperators[n.name]
+	case binaryNode:
+		in.op, in.oi = opBinary, binaryOperators[n.name]
+	case callNode:
+		in.op, in.fn = opCall, p.calculator.functions[n.name]
+	}
+
+	p.code = append(p.code, in)
+	if depth+1 > p.maxStack {
+		p.maxStack = depth + 1
+	}
+}
+
+// jump appends a jump of the node and returns its index, so the target can be set
+// once the instructions it jumps over are emitted
+func (p *Program) jump(op opcode, n *node) int {
+	oi := conditionalOperator
+	if n.kind == binaryNode {
+		oi = binaryOperators[n.name]
+	}
+
+	p.code = append(p.code, instruction{op: op, name: n.name, pos: n.pos, oi: oi})
+	return len(p.code) - 1
+}
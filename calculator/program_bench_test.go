@@ -0,0 +1,88 @@
+package calculator
+
+import "testing"
+
+const benchExpr = "(x * 2 + y ^ 2) / max(x, y, 1) - sqrt(16) * (3 - 1)"
+
+var benchEnv = map[string]Value{"x": Float(3), "y": Float(4)}
+
+func BenchmarkEvaluate(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := defaultCalculator.Evaluate(benchExpr, benchEnv); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkParseQueue evaluates the tokenized expression without keeping the program
+// the way every Evaluate did before Compile, compare it with BenchmarkProgram_Eval
+func BenchmarkParseQueue(b *testing.B) {
+	queue, err := reversePolishTokenizer(benchExpr)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p, err := defaultCalculator.program(queue)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		if _, err := p.Eval(benchEnv); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkProgram_Eval(b *testing.B) {
+	p, err := Compile(benchExpr)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := p.Eval(benchEnv); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkProgram_EvalRational(b *testing.B) {
+	c, err := New(WithMode(ModeRational))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	p, err := c.Compile(benchExpr)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := p.Eval(benchEnv); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkProgram_EvalConditional(b *testing.B) {
+	p, err := Compile("x > 2 && y <= 4 || !(x == y) ? x * 0.1 : 0")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := p.Eval(benchEnv); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
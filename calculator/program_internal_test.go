@@ -0,0 +1,38 @@
+package calculator
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCompile_FoldsConstants(t *testing.T) {
+	tt := []struct {
+		expr         string
+		instructions int
+	}{
+		{expr: "2 + 3 * 4", instructions: 1},
+		{expr: "x + 3 * 4", instructions: 3},
+		{expr: "sqrt(16) * x", instructions: 3},
+		{expr: "-(2 ^ 3) + max(x, 2 * 2)", instructions: 5},
+		{expr: "pi * 2", instructions: 3},
+		{expr: "1 / 0", instructions: 3},
+		{expr: "1 < 2 && 3 != 4", instructions: 1},
+		{expr: "1 < 2 ? x : y", instructions: 1},
+		{expr: "2 > 3 && x", instructions: 1},
+		{expr: "2 < 3 || x", instructions: 1},
+		{expr: "x && 2 < 3", instructions: 4},
+		{expr: "1 + (1 < 2)", instructions: 3},
+	}
+
+	c, err := New()
+	require.NoError(t, err)
+
+	for _, tc := range tt {
+		t.Run(tc.expr, func(t *testing.T) {
+			p, err := c.Compile(tc.expr)
+			require.NoError(t, err)
+			assert.Len(t, p.code, tc.instructions)
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package calculator_test
+
+import (
+	"github.com/denismitr/gds/calculator"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+)
+
+func TestProgram_MatchesEvaluate(t *testing.T) {
+	expressions := []string{
+		"2 + 2",
+		"9 + 24 / (7 - 3)",
+		"-2 ^ 2 + 2 ^ 3 ^ 2",
+		"7 % 3 + 7 // 2",
+		"x * (y - 1) / 2",
+		"max(x, y, 3) + sqrt(x * x + y * y)",
+		"round(sin(pi / 4) * 100) / 100",
+		"-x + abs(-y)",
+	}
+
+	env := map[string]calculator.Value{"x": calculator.Float(3), "y": calculator.Float(4.5)}
+
+	for _, mode := range []calculator.Mode{calculator.ModeFloat, calculator.ModeRational, calculator.ModeBigFloat} {
+		c, err := calculator.New(calculator.WithMode(mode))
+		require.NoError(t, err)
+
+		for _, expr := range expressions {
+			expected, err := c.Evaluate(expr, env)
+			require.NoError(t, err)
+
+			p, err := c.Compile(expr)
+			require.NoError(t, err)
+
+			actual, err := p.Eval(env)
+			require.NoError(t, err)
+			assert.Equal(t, c.Format(expected), c.Format(actual), "%s: %s", mode, expr)
+		}
+	}
+}
+
+func TestProgram_ReusedWithDifferentVariables(t *testing.T) {
+	p, err := calculator.Compile("price * quantity * (1 - discount)")
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 8; i++ {
+		wg.Add(1)
+		go func(quantity float64) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				v, err := p.Eval(map[string]calculator.Value{
+					"price":    calculator.Float(10),
+					"quantity": calculator.Float(quantity),
+					"discount": calculator.Float(0.5),
+				})
+				require.NoError(t, err)
+				assert.Equal(t, 5*quantity, v.Float64())
+			}
+		}(float64(i))
+	}
+
+	wg.Wait()
+}
+
+func TestProgram_Errors(t *testing.T) {
+	_, err := calculator.Compile("1 +")
+	assert.Error(t, err)
+
+	_, err = calculator.Compile("nope(1)")
+	assert.True(t, errors.Is(err, calculator.ErrUnknownToken), "functions are resolved at compile time, got %v", err)
+
+	_, err = calculator.Compile("sqrt(1, 2)")
+	assert.True(t, errors.Is(err, calculator.ErrWrongArgumentCount))
+
+	p, err := calculator.Compile("1 / (x - 1)")
+	require.NoError(t, err)
+
+	_, err = p.Eval(nil)
+	assert.True(t, errors.Is(err, calculator.ErrUndefinedVariable))
+
+	_, err = p.Eval(map[string]calculator.Value{"x": calculator.Float(1)})
+	assert.True(t, errors.Is(err, calculator.ErrDivisionByZero))
+
+	p, err = calculator.Compile("1 / 0 + x")
+	require.NoError(t, err, "failing constant parts are reported on evaluation")
+	_, err = p.Eval(map[string]calculator.Value{"x": calculator.Float(1)})
+	assert.True(t, errors.Is(err, calculator.ErrDivisionByZero))
+}
+
+func TestProgram_CallsRegisteredFunctionsOnEveryEval(t *testing.T) {
+	c, err := calculator.New()
+	require.NoError(t, err)
+
+	calls := 0
+	require.NoError(t, c.RegisterFunction("next", calculator.Function{
+		Call: func([]calculator.Value) (calculator.Value, error) {
+			calls++
+			return calculator.Float(float64(calls)), nil
+		},
+	}))
+
+	p, err := c.Compile("next() * 10")
+	require.NoError(t, err)
+
+	for i := 1; i <= 3; i++ {
+		v, err := p.Eval(nil)
+		require.NoError(t, err)
+		assert.Equal(t, float64(i*10), v.Float64())
+	}
+}
+
+func TestProgram_EvalDoesNotAllocateInFloatMode(t *testing.T) {
+	p, err := calculator.Compile("(x * 2 + y ^ 2) / max(x, y, 1) - -x % 3")
+	require.NoError(t, err)
+
+	env := map[string]calculator.Value{"x": calculator.Float(3), "y": calculator.Float(4)}
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := p.Eval(env); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	assert.Equal(t, 0.0, allocs)
+}
@@ -1,33 +1,60 @@
 package calculator
 
-import (
-	"fmt"
-	"strconv"
-	"strings"
-)
+import "fmt"
 
 type symbol string
 type kind int8
 
 type token struct {
 	value string
-	kind kind
+	kind  kind
+	pos   int
+	// unary marks prefix + and -, they share the symbol with the binary operators
+	unary bool
+	// args is the number of arguments of a function call
+	args int
+	// implicit marks the multiplication between a number and the unit after it like in 3 km,
+	// it binds tighter than the written operators
+	implicit bool
+	// unit marks a name in the position of a unit, only such names are looked up among the units
+	unit bool
 }
 
 func (t *token) getKind() kind {
 	if t.kind == unknown {
 		switch t.value {
-		case "+", "-", "/", "*":
-			t.kind = operator
-		case "(", ")":
+		case leftBracket, rightBracket:
 			t.kind = bracket
 		default:
-			t.kind = number
+			if _, ok := binaryOperators[t.value]; ok {
+				t.kind = operator
+			} else {
+				t.kind = number
+			}
 		}
 	}
 	return t.kind
 }
 
+// display is the token the way ToRPN renders it, functions on the operator stack
+// do not know the number of their arguments yet and are shown by name
+func (t token) display() string {
+	if t.kind == function && t.args == 0 {
+		return t.value
+	}
+
+	return publicToken(t).String()
+}
+
+func tokenStrings(tokens []token) []string {
+	s := make([]string, len(tokens))
+	for i, t := range tokens {
+		s[i] = t.display()
+	}
+
+	return s
+}
+
 func (t *token) isOperator() bool {
 	return t.getKind() == operator
 }
@@ -45,11 +72,11 @@ func (t *token) isEmpty() bool {
 }
 
 const (
-	plus  string = "+"
-	minus string = "-"
-	div   string = "/"
-	mul   string = "*"
-	leftBracket string = "("
+	plus         string = "+"
+	minus        string = "-"
+	div          string = "/"
+	mul          string = "*"
+	leftBracket  string = "("
 	rightBracket string = ")"
 )
 
@@ -59,6 +86,12 @@ const (
 	operator
 	bracket
 	number
+	identifier
+	function
+	comma
+	assign
+	separator
+	colon
 )
 
 type operatorStack []token
@@ -68,7 +101,7 @@ func (os operatorStack) peak() (token, bool) {
 		return token{}, false
 	}
 
-	return os[0], true
+	return os[len(os)-1], true
 }
 
 func (os *operatorStack) pop() token {
@@ -85,12 +118,20 @@ func (os *operatorStack) push(t token) {
 	*os = append(*os, t)
 }
 
+func (os operatorStack) strings() []string {
+	return tokenStrings(os)
+}
+
 type tokenQueue []token
 
 func (tq *tokenQueue) push(t token) {
 	*tq = append(*tq, t)
 }
 
+func (tq tokenQueue) strings() []string {
+	return tokenStrings(tq)
+}
+
 func (tq *tokenQueue) dequeue() (token, bool) {
 	if len(*tq) == 0 {
 		return token{kind: empty}, false
@@ -101,176 +142,303 @@ func (tq *tokenQueue) dequeue() (token, bool) {
 	return first, true
 }
 
+// call tracks an open bracket during the conversion,
+// brackets that follow a function name count the arguments of the call
+type call struct {
+	open   token
+	isCall bool
+	commas int
+}
+
+// reversePolishTokenizer converts a single expression to reverse polish notation
 func reversePolishTokenizer(input string) (tokenQueue, error) {
-	ss := strings.Split(input, " ")
+	tokens, err := expression(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return shuntingYard(tokens)
+}
+
+// shuntingYard converts the tokens of an expression to reverse polish notation
+func shuntingYard(tokens []token) (tokenQueue, error) {
+	return convert(tokens, nil)
+}
+
+// convert is the shunting-yard algorithm, every step is appended to steps unless it is nil
+func convert(tokens []token, steps *[]ConversionStep) (tokenQueue, error) {
 	var os operatorStack
 	var queue tokenQueue
+	var calls []call
+
+	record := func(t token, action string) {
+		if steps != nil {
+			*steps = append(*steps, ConversionStep{Token: t.value, Action: action, Stack: os.strings(), Output: queue.strings()})
+		}
+	}
+
+	// pushOperator pops the operators that bind at least as tight as next and pushes next,
+	// ? waits for its : and is never popped by an operator
+	pushOperator := func(next token) {
+		for {
+			top, ok := os.peak()
+			if !ok || top.value == question {
+				break
+			}
+
+			if operatorHasHigherPrecedence(top, next) {
+				top = os.pop()
+				queue.push(top)
+				record(next, fmt.Sprintf("pop %s to the output, it binds at least as tight", top.display()))
+			} else {
+				break
+			}
+		}
+		os.push(next)
+	}
 
-	for _, s := range ss {
-		next := token{value: strings.Trim(s, " ")}
+	// an operand is expected at the start, after an operator, a comma and a left bracket,
+	// + and - in that position are unary
+	expectOperand := true
+
+	for i, next := range tokens {
 		switch next.getKind() {
 		case number:
 			queue.push(next)
+			record(next, "add the number to the output")
+			expectOperand = false
+		case identifier:
+			if i+1 < len(tokens) && tokens[i+1].value == leftBracket {
+				next.kind = function
+				os.push(next)
+				record(next, "push the function")
+				continue
+			}
+
+			// a name right after a number or a closing bracket is a unit like in 3 km or (1 + 2) km
+			if !expectOperand && (tokens[i-1].kind == number || tokens[i-1].value == rightBracket) {
+				pushOperator(token{value: mul, kind: operator, pos: next.pos, implicit: true})
+				record(next, "push the implied multiplication")
+			}
+
+			next.unit = unitPosition(tokens, i)
+			queue.push(next)
+			if next.unit {
+				record(next, "add the unit to the output")
+			} else {
+				record(next, "add the variable to the output")
+			}
+			expectOperand = false
 		case operator:
+			if expectOperand {
+				if _, ok := unaryOperators[next.value]; !ok {
+					return nil, syntaxError(next.pos, "missing operand for %s", next.value)
+				}
+
+				// a prefix operator has nothing on its left, so it never pops other operators
+				next.unary = true
+				os.push(next)
+				record(next, "push the prefix operator")
+				continue
+			}
+
+			if _, ok := lookupOperator(next); !ok {
+				return nil, syntaxError(next.pos, "missing operator before %s", next.value)
+			}
+
+			pushOperator(next)
+			record(next, "push the operator")
+			expectOperand = true
+		case comma:
+			if len(calls) == 0 || !calls[len(calls)-1].isCall {
+				return nil, syntaxError(next.pos, "unexpected comma outside of a function call")
+			}
+
+			if expectOperand {
+				return nil, syntaxError(next.pos, "missing argument")
+			}
+
+			for top, _ := os.peak(); top.value != leftBracket; top, _ = os.peak() {
+				if top.value == question {
+					return nil, missingColon(top.pos)
+				}
+				top = os.pop()
+				queue.push(top)
+				record(next, fmt.Sprintf("pop %s to the output", top.display()))
+			}
+
+			calls[len(calls)-1].commas++
+			record(next, "start the next argument")
+			expectOperand = true
+		case colon:
+			if expectOperand {
+				return nil, syntaxError(next.pos, "missing operand before %s", next.value)
+			}
+
+			// the operators of the first branch are complete, ? on top of the stack becomes ?:
+			// that waits for the second branch, it can not be outside of the enclosing brackets
 			for {
 				top, ok := os.peak()
-				if !ok {
-					break
+				if !ok || top.value == leftBracket {
+					return nil, syntaxError(next.pos, "unexpected %s without ?", next.value)
 				}
 
-				if operatorHasHigherPrecedence(top.value, next.value) {
-					top = os.pop()
-					queue.push(top)
-				} else {
+				if top.value == question {
 					break
 				}
+
+				top = os.pop()
+				queue.push(top)
+				record(next, fmt.Sprintf("pop %s to the output", top.display()))
 			}
-			os.push(next)
+
+			top := os.pop()
+			top.value = conditional
+			os.push(top)
+			record(next, fmt.Sprintf("replace %s with %s", question, conditional))
+			expectOperand = true
 		case bracket:
 			if next.value == leftBracket {
+				top, _ := os.peak()
+				calls = append(calls, call{open: next, isCall: top.kind == function})
 				os.push(next)
-			} else {
-				for {
-					top := os.pop()
-					if top.value != leftBracket && top.kind != empty {
-						queue.push(top)
-					} else {
-						break
-					}
+				record(next, "push the bracket")
+				expectOperand = true
+				continue
+			}
+
+			if len(calls) == 0 {
+				return nil, mismatchedParentheses(next.pos)
+			}
+
+			c := calls[len(calls)-1]
+			calls = calls[:len(calls)-1]
+
+			args := c.commas + 1
+			if expectOperand {
+				switch {
+				case c.isCall && c.commas == 0 && tokens[i-1].value == leftBracket:
+					args = 0
+				case c.isCall || tokens[i-1].value != leftBracket:
+					return nil, syntaxError(next.pos, "missing operand before %s", next.value)
+				default:
+					return nil, syntaxError(c.open.pos, "empty parentheses")
 				}
 			}
+
+			for top := os.pop(); top.value != leftBracket; top = os.pop() {
+				if top.value == question {
+					return nil, missingColon(top.pos)
+				}
+				queue.push(top)
+				record(next, fmt.Sprintf("pop %s to the output", top.display()))
+			}
+			record(next, "discard the brackets")
+
+			if c.isCall {
+				fn := os.pop()
+				fn.args = args
+				queue.push(fn)
+				record(next, fmt.Sprintf("pop %s to the output", fn.display()))
+			}
+
+			expectOperand = false
+		case assign:
+			return nil, syntaxError(next.pos, "unexpected %s, only a variable can be assigned", next.value)
 		default:
-			panic(fmt.Sprintf("invalid token %s", next.value))
+			return nil, &SyntaxError{Pos: next.pos, Msg: fmt.Sprintf("unknown token %q", next.value), Err: ErrUnknownToken}
 		}
 	}
 
-	for {
-		top := os.pop()
-		if top.kind == empty {
-			break
-		}
-		queue.push(top)
+	if len(calls) > 0 {
+		return nil, mismatchedParentheses(calls[0].open.pos)
 	}
 
-	return queue, nil
-}
-
-func parseQueue(queue tokenQueue) (string, error) {
-	var stack operatorStack
+	if expectOperand && len(tokens) > 0 {
+		last := tokens[len(tokens)-1]
+		return nil, syntaxError(last.pos, "missing operand for %s", last.value)
+	}
 
 	for {
-		next, exists := queue.dequeue()
-		if !exists {
+		top := os.pop()
+		if top.kind == empty {
 			break
 		}
 
-		if next.getKind() == number {
-			stack.push(next)
+		if top.value == question {
+			return nil, missingColon(top.pos)
 		}
 
-		if next.getKind() == operator {
-			second, first := stack.pop(), stack.pop()
-			if first.isEmpty() || second.isEmpty() {
-				panic("how could first or second be empty")
-			}
-
-			switch next.value {
-			case plus:
-				s, err := add(first, second)
-				if err != nil {
-					return "", err
-				}
-
-				stack.push(s)
-			case minus:
-				s, err := sub(first, second)
-				if err != nil {
-					return "", err
-				}
-
-				stack.push(s)
-			case div:
-				s, err := divide(first, second)
-				if err != nil {
-					return "", err
-				}
-
-				stack.push(s)
-			case mul:
-				s, err := multiply(first, second)
-				if err != nil {
-					return "", err
-				}
-
-				stack.push(s)
-			default:
-				panic("invalid operator " + next.value)
-			}
-		}
+		queue.push(top)
+		record(token{}, fmt.Sprintf("pop %s to the output", top.display()))
 	}
 
-	return stack.pop().value, nil
+	return queue, nil
 }
 
-func add(first token, second token) (token, error) {
-	f, err := strconv.Atoi(first.value)
-	if err != nil {
-		panic(err)
+// resolve finds the function and checks that it accepts the number of arguments
+func (c *Calculator) resolve(fn token, args int) (callable, error) {
+	f, ok := c.functions[fn.value]
+	if !ok {
+		return callable{}, &SyntaxError{Pos: fn.pos, Msg: fmt.Sprintf("unknown function %s", fn.value), Err: ErrUnknownToken}
 	}
 
-	s, err := strconv.Atoi(second.value)
-	if err != nil {
-		panic(err)
+	if !f.accepts(args) {
+		return callable{}, &SyntaxError{
+			Pos: fn.pos,
+			Msg: fmt.Sprintf("%s does not accept %d arguments", fn.value, args),
+			Err: ErrWrongArgumentCount,
+		}
 	}
 
-	return token{value: strconv.Itoa(f + s), kind: number}, nil
+	return f, nil
 }
 
-func sub(first token, second token) (token, error) {
-	f, err := strconv.Atoi(first.value)
-	if err != nil {
-		panic(err)
-	}
-
-	s, err := strconv.Atoi(second.value)
-	if err != nil {
-		panic(err)
+// unitPosition reports whether the name at i is in the position of a unit: after a number or a closing bracket
+// like km in 3 km, after in or to like m in 3 km in m, or after * or / that follow a unit like s in 3 m/s^2
+func unitPosition(tokens []token, i int) bool {
+	if i == 0 {
+		return false
 	}
 
-	return token{value: strconv.Itoa(f - s), kind: number}, nil
-}
-
-func multiply(first token, second token) (token, error) {
-	f, err := strconv.Atoi(first.value)
-	if err != nil {
-		panic(err)
+	prev := tokens[i-1]
+	switch {
+	case prev.kind == number || prev.value == rightBracket:
+		return true
+	case prev.kind == operator && (prev.value == inUnit || prev.value == toUnit):
+		return true
+	case prev.kind != operator || prev.value != mul && prev.value != div:
+		return false
 	}
 
-	s, err := strconv.Atoi(second.value)
-	if err != nil {
-		panic(err)
+	// the unit before * or / can have an exponent like m^2 or s^-1
+	j := i - 2
+	switch {
+	case j >= 2 && tokens[j].kind == number && tokens[j-1].value == minus && tokens[j-2].value == pow:
+		j -= 3
+	case j >= 1 && tokens[j].kind == number && tokens[j-1].value == pow:
+		j -= 2
 	}
 
-	return token{value: strconv.Itoa(f * s), kind: number}, nil
+	return j >= 0 && tokens[j].kind == identifier && unitPosition(tokens, j)
 }
 
-func divide(first token, second token) (token, error) {
-	f, err := strconv.Atoi(first.value)
-	if err != nil {
-		panic(err)
+// operatorHasHigherPrecedence reports whether the operator on top of the stack
+// must be applied before the next one
+func operatorHasHigherPrecedence(top, next token) bool {
+	if !top.isOperator() {
+		return false
 	}
 
-	s, err := strconv.Atoi(second.value)
-	if err != nil {
-		panic(err)
-	}
+	a, _ := lookupOperator(top)
+	b, _ := lookupOperator(next)
+	return a.precedence > b.precedence || a.precedence == b.precedence && b.associativity == leftAssociative
+}
 
-	return token{value: strconv.Itoa(f / s), kind: number}, nil
+func mismatchedParentheses(pos int) *SyntaxError {
+	return &SyntaxError{Pos: pos, Msg: "mismatched parentheses", Err: ErrMismatchedParentheses}
 }
 
-func operatorHasHigherPrecedence(a, b string) bool {
-	if a == leftBracket || a == rightBracket {
-		return false
-	}
-	return (a == div || a == mul) && (b == plus || b == minus)
-}
\ No newline at end of file
+func missingColon(pos int) *SyntaxError {
+	return syntaxError(pos, "missing : for %s", question)
+}
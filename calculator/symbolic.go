@@ -0,0 +1,662 @@
+package calculator
+
+import (
+	"github.com/pkg/errors"
+	"sort"
+	"strconv"
+)
+
+var ErrNotDifferentiable = errors.New("not differentiable")
+
+// Expr is a parsed expression that can be transformed symbolically, expressions are immutable,
+// every transformation returns a new one that shares the unchanged parts of the tree.
+// The zero Expr is empty, it prints as an empty string and fails to evaluate like an empty input.
+type Expr struct {
+	calculator *Calculator
+	root       *node
+}
+
+// Parse parses an expression with float64 numbers
+func Parse(expr string) (*Expr, error) {
+	return defaultCalculator.Parse(expr)
+}
+
+// Parse parses an expression without folding the constants, so it prints the way it was written.
+// The integer modes are not supported because their division is not exact.
+func (c *Calculator) Parse(expr string) (*Expr, error) {
+	if c.Mode().integer() {
+		return nil, errors.Wrapf(ErrInvalidMode, "symbolic expressions are not supported in %s mode", c.Mode())
+	}
+
+	queue, err := reversePolishTokenizer(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := parseTree(queue)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.bind(root); err != nil {
+		return nil, err
+	}
+
+	return &Expr{calculator: c, root: root}, nil
+}
+
+// String renders the expression in infix notation with only the brackets the structure requires
+func (e *Expr) String() string {
+	if e.empty() {
+		return ""
+	}
+
+	return e.root.format(e.calculator.units)
+}
+
+// Compile compiles the expression for repeated evaluation
+func (e *Expr) Compile() *Program {
+	if e.empty() {
+		return &Program{calculator: defaultCalculator}
+	}
+
+	return e.calculator.compile(e.root)
+}
+
+// Eval compiles and evaluates the expression once, Compile is faster for repeated evaluation
+func (e *Expr) Eval(env map[string]Value) (Value, error) {
+	return e.Compile().Eval(env)
+}
+
+// Variables returns the sorted names the expression depends on, constants like pi and units like km in 3 km are not included
+func (e *Expr) Variables() []string {
+	if e.empty() {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+
+	var visit func(n *node)
+	visit = func(n *node) {
+		if n.kind == variableNode && !seen[n.name] {
+			_, constant := e.calculator.constants[n.name]
+			if !constant && !e.calculator.isUnit(n) {
+				names = append(names, n.name)
+			}
+			seen[n.name] = true
+		}
+
+		for _, arg := range n.args {
+			visit(arg)
+		}
+	}
+	visit(e.root)
+
+	sort.Strings(names)
+	return names
+}
+
+// Substitute replaces variables by expressions, the expressions may come from a calculator
+// with another mode, their numbers are converted and their functions must exist in this one
+func (e *Expr) Substitute(vars map[string]*Expr) (*Expr, error) {
+	if e.empty() {
+		return nil, emptyExpression()
+	}
+
+	c := e.calculator
+	roots := make(map[string]*node, len(vars))
+	for name, sub := range vars {
+		if sub.empty() {
+			return nil, errors.Wrapf(emptyExpression(), "substitution for %s", name)
+		}
+
+		root := sub.root
+		if sub.calculator != c {
+			root = root.copy()
+			if err := c.bind(root); err != nil {
+				return nil, errors.Wrapf(err, "substitution for %s", name)
+			}
+		}
+		roots[name] = root
+	}
+
+	var substitute func(n *node) *node
+	substitute = func(n *node) *node {
+		if n.kind == variableNode && !c.isUnit(n) {
+			if root, ok := roots[n.name]; ok {
+				return root
+			}
+			return n
+		}
+
+		if len(n.args) == 0 {
+			return n
+		}
+
+		args := make([]*node, len(n.args))
+		for i, arg := range n.args {
+			args[i] = substitute(arg)
+		}
+		return &node{kind: n.kind, name: n.name, pos: n.pos, args: args}
+	}
+
+	return &Expr{calculator: c, root: substitute(e.root)}, nil
+}
+
+// Simplify folds constants, removes identities like x * 1, x + 0 and --x,
+// and collects like terms and factors, so x + 2 * x is 3 * x and x * x / y is x ^ 2 / y.
+// Like everywhere in algebra x / x is 1 and 0 * x is 0 even though x could be 0.
+func (e *Expr) Simplify() *Expr {
+	if e.empty() {
+		return e
+	}
+
+	return &Expr{calculator: e.calculator, root: e.calculator.simplify(e.root)}
+}
+
+// Derivative differentiates the expression with respect to the variable and simplifies the result.
+// Piecewise functions like floor, min and max, and conditionals are differentiated piece by piece,
+// comparisons, logical operators, % and // and registered functions are not differentiable.
+func (e *Expr) Derivative(variable string) (*Expr, error) {
+	if e.empty() {
+		return nil, emptyExpression()
+	}
+
+	d, err := e.calculator.derivative(e.root, variable)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Expr{calculator: e.calculator, root: e.calculator.simplify(d)}, nil
+}
+
+// Gradient returns the derivatives with respect to the variables, all the variables by default
+func (e *Expr) Gradient(variables ...string) ([]*Expr, error) {
+	if len(variables) == 0 {
+		variables = e.Variables()
+	}
+
+	gradient := make([]*Expr, len(variables))
+	for i, variable := range variables {
+		d, err := e.Derivative(variable)
+		if err != nil {
+			return nil, err
+		}
+		gradient[i] = d
+	}
+
+	return gradient, nil
+}
+
+// empty reports whether the expression is nil or the zero Expr
+func (e *Expr) empty() bool {
+	return e == nil || e.root == nil
+}
+
+// isUnit reports whether the node is a unit, a name in the position of a unit is one
+// when the calculator has such a unit, like it is looked up
+func (c *Calculator) isUnit(n *node) bool {
+	if n.kind != variableNode || !n.unit {
+		return false
+	}
+
+	_, ok := c.units[n.name]
+	return ok
+}
+
+func emptyExpression() *SyntaxError {
+	return syntaxError(0, "empty expression")
+}
+
+func (c *Calculator) derivative(n *node, x string) (*node, error) {
+	switch n.kind {
+	case numberNode:
+		return c.integer(0, n.pos), nil
+	case variableNode:
+		if n.name == x && !c.isUnit(n) {
+			return c.integer(1, n.pos), nil
+		}
+		return c.integer(0, n.pos), nil
+	case callNode:
+		return c.derivativeOfCall(n, x)
+	case conditionalNode:
+		da, err := c.derivative(n.args[1], x)
+		if err != nil {
+			return nil, err
+		}
+
+		db, err := c.derivative(n.args[2], x)
+		if err != nil {
+			return nil, err
+		}
+
+		return &node{kind: conditionalNode, name: conditional, pos: n.pos, args: []*node{n.args[0], da, db}}, nil
+	case unaryNode:
+		if n.name != plus && n.name != minus {
+			return nil, notDifferentiable(n)
+		}
+
+		du, err := c.derivative(n.args[0], x)
+		if err != nil || n.name == plus {
+			return du, err
+		}
+		return newUnary(minus, n.pos, du), nil
+	}
+
+	u, v := n.args[0], n.args[1]
+	switch n.name {
+	case plus, minus, mul, div, pow:
+	default:
+		return nil, notDifferentiable(n)
+	}
+
+	du, err := c.derivative(u, x)
+	if err != nil {
+		return nil, err
+	}
+
+	dv, err := c.derivative(v, x)
+	if err != nil {
+		return nil, err
+	}
+
+	pos := n.pos
+	switch n.name {
+	case plus, minus:
+		return newBinary(n.name, pos, du, dv), nil
+	case mul:
+		return newBinary(plus, pos, newBinary(mul, pos, du, v), newBinary(mul, pos, u, dv)), nil
+	case div:
+		numerator := newBinary(minus, pos, newBinary(mul, pos, du, v), newBinary(mul, pos, u, dv))
+		return newBinary(div, pos, numerator, newBinary(pow, pos, v, c.integer(2, pos))), nil
+	}
+
+	// d(u ^ v) = v * u ^ (v - 1) * du when v is constant, u ^ v * ln(u) * dv when u is constant
+	// and u ^ v * (dv * ln(u) + v * du / u) otherwise
+	switch {
+	case !v.contains(x):
+		power := newBinary(pow, pos, u, newBinary(minus, pos, v, c.integer(1, pos)))
+		return newBinary(mul, pos, newBinary(mul, pos, v, power), du), nil
+	case !u.contains(x):
+		return newBinary(mul, pos, newBinary(mul, pos, n, newCall("ln", pos, u)), dv), nil
+	default:
+		inner := newBinary(plus, pos,
+			newBinary(mul, pos, dv, newCall("ln", pos, u)),
+			newBinary(div, pos, newBinary(mul, pos, v, du), u))
+		return newBinary(mul, pos, n, inner), nil
+	}
+}
+
+// derivativeOfCall applies the chain rule to the builtin functions
+func (c *Calculator) derivativeOfCall(n *node, x string) (*node, error) {
+	if !c.functions[n.name].pure {
+		return nil, notDifferentiable(n)
+	}
+
+	pos := n.pos
+	switch n.name {
+	case "floor", "ceil", "round":
+		// piecewise constant
+		return c.integer(0, pos), nil
+	case "min", "max":
+		return c.derivativeOfExtremum(n, x)
+	case "log":
+		if len(n.args) == 2 {
+			// the logarithm to base b is ln(u) / ln(b)
+			return c.derivative(newBinary(div, pos, newCall("ln", pos, n.args[0]), newCall("ln", pos, n.args[1])), x)
+		}
+	}
+
+	u := n.args[0]
+	du, err := c.derivative(u, x)
+	if err != nil {
+		return nil, err
+	}
+
+	one := c.integer(1, pos)
+	var outer *node
+	switch n.name {
+	case "sqrt":
+		outer = newBinary(div, pos, one, newBinary(mul, pos, c.integer(2, pos), n))
+	case "abs":
+		outer = newBinary(div, pos, u, n)
+	case "ln":
+		outer = newBinary(div, pos, one, u)
+	case "log":
+		outer = newBinary(div, pos, one, newBinary(mul, pos, u, newCall("ln", pos, c.integer(10, pos))))
+	case "exp":
+		outer = n
+	case "sin":
+		outer = newCall("cos", pos, u)
+	case "cos":
+		outer = newUnary(minus, pos, newCall("sin", pos, u))
+	case "tan":
+		outer = newBinary(div, pos, one, newBinary(pow, pos, newCall("cos", pos, u), c.integer(2, pos)))
+	case "asin", "acos":
+		square := newBinary(pow, pos, u, c.integer(2, pos))
+		outer = newBinary(div, pos, one, newCall("sqrt", pos, newBinary(minus, pos, one, square)))
+		if n.name == "acos" {
+			outer = newUnary(minus, pos, outer)
+		}
+	case "atan":
+		outer = newBinary(div, pos, one, newBinary(plus, pos, one, newBinary(pow, pos, u, c.integer(2, pos))))
+	default:
+		return nil, notDifferentiable(n)
+	}
+
+	return newBinary(mul, pos, outer, du), nil
+}
+
+// derivativeOfExtremum differentiates max(a, b, ...) as a >= max(b, ...) ? da : d(max(b, ...))
+func (c *Calculator) derivativeOfExtremum(n *node, x string) (*node, error) {
+	first := n.args[0]
+	if len(n.args) == 1 {
+		return c.derivative(first, x)
+	}
+
+	rest := newCall(n.name, n.pos, n.args[1:]...)
+	if len(n.args) == 2 {
+		rest = n.args[1]
+	}
+
+	da, err := c.derivative(first, x)
+	if err != nil {
+		return nil, err
+	}
+
+	dr, err := c.derivative(rest, x)
+	if err != nil {
+		return nil, err
+	}
+
+	op := ge
+	if n.name == "min" {
+		op = le
+	}
+
+	condition := newBinary(op, n.pos, first, rest)
+	return &node{kind: conditionalNode, name: conditional, pos: n.pos, args: []*node{condition, da, dr}}, nil
+}
+
+func (c *Calculator) simplify(n *node) *node {
+	if len(n.args) == 0 {
+		return n
+	}
+
+	args := make([]*node, len(n.args))
+	for i, arg := range n.args {
+		args[i] = c.simplify(arg)
+	}
+
+	folded := c.foldNode(&node{kind: n.kind, name: n.name, pos: n.pos, args: args})
+	switch {
+	case folded.kind == unaryNode && folded.name == plus:
+		return folded.args[0]
+	case folded.kind == unaryNode && folded.name == minus,
+		folded.kind == binaryNode && (folded.name == plus || folded.name == minus),
+		folded.kind == binaryNode && (folded.name == mul || folded.name == div),
+		folded.kind == binaryNode && folded.name == pow && folded.args[1].numeric():
+		return c.collect(folded)
+	default:
+		return folded
+	}
+}
+
+// term is a product of a coefficient and factors with numeric exponents,
+// 2 * x * y ^ 2 / x ^ 3 is the coefficient 2 with the factors x ^ -2 and y ^ 2
+type term struct {
+	coefficient Value
+	factors     []factor
+}
+
+type factor struct {
+	base     *node
+	exponent Value
+}
+
+// collect rebuilds a sum or a product adding up the coefficients of like terms
+// and the exponents of like factors
+func (c *Calculator) collect(n *node) *node {
+	var terms []term
+	c.terms(n, false, &terms)
+
+	var sum *node
+	for _, t := range terms {
+		if c.isZero(t.coefficient) {
+			continue
+		}
+
+		switch {
+		case sum == nil:
+			sum = c.termNode(t, n.pos)
+		case sign(c.arithmetic, t.coefficient) < 0:
+			t.coefficient = c.negate(t.coefficient)
+			sum = newBinary(minus, n.pos, sum, c.termNode(t, n.pos))
+		default:
+			sum = newBinary(plus, n.pos, sum, c.termNode(t, n.pos))
+		}
+	}
+
+	if sum == nil {
+		return c.integer(0, n.pos)
+	}
+
+	return sum
+}
+
+// terms flattens sums and differences, like terms are merged
+func (c *Calculator) terms(n *node, negative bool, terms *[]term) {
+	switch {
+	case n.kind == binaryNode && (n.name == plus || n.name == minus):
+		c.terms(n.args[0], negative, terms)
+		c.terms(n.args[1], negative != (n.name == minus), terms)
+		return
+	case n.kind == unaryNode && n.name == minus:
+		c.terms(n.args[0], !negative, terms)
+		return
+	}
+
+	t := term{coefficient: c.integer(1, 0).value}
+	c.factors(n, false, &t)
+	if negative {
+		t.coefficient = c.negate(t.coefficient)
+	}
+
+	key := t.key()
+	for i := range *terms {
+		if (*terms)[i].key() == key {
+			(*terms)[i].coefficient = c.sum((*terms)[i].coefficient, t.coefficient)
+			return
+		}
+	}
+
+	*terms = append(*terms, t)
+}
+
+// factors flattens products and quotients into the term, like factors are merged
+func (c *Calculator) factors(n *node, inverse bool, t *term) {
+	switch {
+	case n.kind == binaryNode && (n.name == mul || n.name == div):
+		c.factors(n.args[0], inverse, t)
+		c.factors(n.args[1], inverse != (n.name == div), t)
+		return
+	case n.kind == unaryNode && n.name == minus:
+		t.coefficient = c.negate(t.coefficient)
+		c.factors(n.args[0], inverse, t)
+		return
+	case n.numeric() && !inverse:
+		t.coefficient = c.times(t.coefficient, n.value)
+		return
+	case n.numeric() && !c.isZero(n.value):
+		if v, err := c.arithmetic.div(t.coefficient, n.value); err == nil {
+			t.coefficient = v
+			return
+		}
+	}
+
+	f := factor{base: n, exponent: c.integer(1, 0).value}
+	if n.kind == binaryNode && n.name == pow && n.args[1].numeric() {
+		f = factor{base: n.args[0], exponent: n.args[1].value}
+	}
+
+	if inverse {
+		f.exponent = c.negate(f.exponent)
+	}
+
+	key := f.base.String()
+	for i := range t.factors {
+		if t.factors[i].base.String() == key {
+			t.factors[i].exponent = c.sum(t.factors[i].exponent, f.exponent)
+			return
+		}
+	}
+
+	t.factors = append(t.factors, f)
+}
+
+// key identifies like terms, they differ only in the coefficient
+func (t term) key() string {
+	var key string
+	for _, f := range t.factors {
+		key += f.base.String() + "^" + f.exponent.String() + ";"
+	}
+
+	return key
+}
+
+// termNode renders a term as the coefficient times the factors with positive exponents
+// divided by the factors with negative exponents
+func (c *Calculator) termNode(t term, pos int) *node {
+	one := c.integer(1, pos).value
+	negate := c.arithmetic.cmp(t.coefficient, c.negate(one)) == 0
+
+	// a unit is recognized only after a number, so even the coefficient 1 is kept before it like in 1 km
+	leadingUnit := false
+	for _, f := range t.factors {
+		if sign(c.arithmetic, f.exponent) > 0 {
+			leadingUnit = f.base.startsWithUnit(c.units)
+			break
+		}
+	}
+
+	// the coefficient goes first, so the product is built left to right like 2 * x * y
+	var numerator, denominator *node
+	if leadingUnit || !negate && c.arithmetic.cmp(t.coefficient, one) != 0 {
+		numerator = &node{kind: numberNode, value: t.coefficient, pos: pos}
+		negate = false
+	}
+
+	for _, f := range t.factors {
+		switch sign(c.arithmetic, f.exponent) {
+		case 1:
+			numerator = product(numerator, c.power(f.base, f.exponent, pos), pos)
+		case -1:
+			denominator = product(denominator, c.power(f.base, c.negate(f.exponent), pos), pos)
+		}
+	}
+
+	switch {
+	case numerator == nil && negate:
+		numerator = &node{kind: numberNode, value: t.coefficient, pos: pos}
+	case numerator == nil:
+		numerator = &node{kind: numberNode, value: one, pos: pos}
+	case negate:
+		numerator = newUnary(minus, pos, numerator)
+	}
+
+	if denominator == nil {
+		return numerator
+	}
+
+	return newBinary(div, pos, numerator, denominator)
+}
+
+func (c *Calculator) power(base *node, exponent Value, pos int) *node {
+	if c.arithmetic.cmp(exponent, c.integer(1, pos).value) == 0 {
+		return base
+	}
+
+	return newBinary(pow, pos, base, &node{kind: numberNode, value: exponent, pos: pos})
+}
+
+func product(x, y *node, pos int) *node {
+	if x == nil {
+		return y
+	}
+
+	return newBinary(mul, pos, x, y)
+}
+
+// negate, sum and times never fail because symbolic expressions are never in an integer mode
+func (c *Calculator) negate(v Value) Value {
+	v, _ = c.arithmetic.neg(v)
+	return v
+}
+
+func (c *Calculator) sum(x, y Value) Value {
+	v, _ := c.arithmetic.add(x, y)
+	return v
+}
+
+func (c *Calculator) times(x, y Value) Value {
+	v, _ := c.arithmetic.mul(x, y)
+	return v
+}
+
+func (c *Calculator) isZero(v Value) bool {
+	return sign(c.arithmetic, v) == 0
+}
+
+// integer makes a number node in the mode of the calculator
+func (c *Calculator) integer(i int64, pos int) *node {
+	v, _ := c.arithmetic.parse(strconv.FormatInt(i, 10))
+	return &node{kind: numberNode, value: v, pos: pos}
+}
+
+// numeric reports whether the node is a number as opposed to a boolean or an expression
+func (n *node) numeric() bool {
+	return n.kind == numberNode && n.value.Type() == NumberType
+}
+
+func (n *node) contains(variable string) bool {
+	if n.kind == variableNode {
+		return n.name == variable
+	}
+
+	for _, arg := range n.args {
+		if arg.contains(variable) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (n *node) copy() *node {
+	cp := *n
+	if len(n.args) > 0 {
+		cp.args = make([]*node, len(n.args))
+		for i, arg := range n.args {
+			cp.args[i] = arg.copy()
+		}
+	}
+
+	return &cp
+}
+
+func newUnary(op string, pos int, x *node) *node {
+	return &node{kind: unaryNode, name: op, pos: pos, args: []*node{x}}
+}
+
+func newBinary(op string, pos int, x, y *node) *node {
+	return &node{kind: binaryNode, name: op, pos: pos, args: []*node{x, y}}
+}
+
+func newCall(name string, pos int, args ...*node) *node {
+	return &node{kind: callNode, name: name, pos: pos, args: args}
+}
+
+func notDifferentiable(n *node) error {
+	return errors.Wrapf(ErrNotDifferentiable, "%s at position %d", n.name, n.pos)
+}
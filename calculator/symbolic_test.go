@@ -0,0 +1,277 @@
+package calculator_test
+
+import (
+	"github.com/denismitr/gds/calculator"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	e, err := calculator.Parse("(2 * 3) + x ^ (1 + 1)")
+	require.NoError(t, err)
+	assert.Equal(t, "2 * 3 + x ^ (1 + 1)", e.String())
+	assert.Equal(t, []string{"x"}, e.Variables())
+
+	v, err := e.Eval(map[string]calculator.Value{"x": calculator.Float(3)})
+	require.NoError(t, err)
+	assert.Equal(t, 15.0, v.Float64())
+	assert.Equal(t, "2 * 3 + x ^ (1 + 1)", e.String(), "evaluation does not change the expression")
+
+	e, err = calculator.Parse("max(b, a) * pi + a")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, e.Variables())
+
+	_, err = calculator.Parse("f(x)")
+	assert.True(t, errors.Is(err, calculator.ErrUnknownToken))
+}
+
+func TestExpr_Simplify(t *testing.T) {
+	tt := []struct {
+		input  string
+		output string
+	}{
+		{input: "2 * 3 + 4", output: "10"},
+		{input: "x + 0", output: "x"},
+		{input: "0 + x * 1", output: "x"},
+		{input: "1 * x / 1 - 0", output: "x"},
+		{input: "x ^ 1", output: "x"},
+		{input: "x ^ 0", output: "1"},
+		{input: "0 * x + 0 / y", output: "0"},
+		{input: "--x", output: "x"},
+		{input: "+x", output: "x"},
+		{input: "x - x", output: "0"},
+		{input: "x + 2 * x", output: "3 * x"},
+		{input: "2 * x * 3 * x", output: "6 * x ^ 2"},
+		{input: "x * x / y", output: "x ^ 2 / y"},
+		{input: "x ^ 2 / x", output: "x"},
+		{input: "1 / x + 2 / x", output: "3 / x"},
+		{input: "-x * -y", output: "x * y"},
+		{input: "x + y - x", output: "y"},
+		{input: "a - (b + c)", output: "a - b - c"},
+		{input: "-3 * x + 2 * 1", output: "-3 * x + 2"},
+		{input: "sin(x) + sin(x) * 2", output: "3 * sin(x)"},
+		{input: "(x + 1) * (1 + x)", output: "(x + 1) * (1 + x)"},
+		{input: "(x + 1) * (x + 1)", output: "(x + 1) ^ 2"},
+		{input: "x ^ y * x ^ y", output: "(x ^ y) ^ 2"},
+		{input: "1 < 2 ? x + x : y", output: "2 * x"},
+		{input: "x > 1 && 2 < 1", output: "x > 1 && false"},
+		{input: "sqrt(16) * x + max(x, 1 + 1)", output: "4 * x + max(x, 2)"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.input, func(t *testing.T) {
+			e, err := calculator.Parse(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.output, e.Simplify().String())
+
+			original, err := calculator.FormatInfix(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, original, e.String(), "the original expression is kept")
+		})
+	}
+
+	rat, err := calculator.New(calculator.WithMode(calculator.ModeRational))
+	require.NoError(t, err)
+
+	e, err := rat.Parse("x / 3 + x / 6 - y * 0.25")
+	require.NoError(t, err)
+	assert.Equal(t, "1/2 * x - 1/4 * y", e.Simplify().String())
+}
+
+func TestExpr_Derivative(t *testing.T) {
+	tt := []struct {
+		input      string
+		derivative string
+	}{
+		{input: "5", derivative: "0"},
+		{input: "y", derivative: "0"},
+		{input: "x", derivative: "1"},
+		{input: "x ^ 3", derivative: "3 * x ^ 2"},
+		{input: "x ^ 2 + 3 * x + 5", derivative: "2 * x + 3"},
+		{input: "x * y", derivative: "y"},
+		{input: "-x ^ 2", derivative: "-2 * x"},
+		{input: "1 / x", derivative: "-1 / x ^ 2"},
+		{input: "(x + 1) / (x - 1)", derivative: "-2 / (x - 1) ^ 2"},
+		{input: "sin(x) * cos(x)", derivative: "cos(x) ^ 2 - sin(x) ^ 2"},
+		{input: "x ^ x", derivative: "x ^ x * (ln(x) + 1)"},
+		{input: "exp(2 * x)", derivative: "2 * exp(2 * x)"},
+		{input: "ln(x)", derivative: "1 / x"},
+		{input: "sqrt(x)", derivative: "0.5 / sqrt(x)"},
+		{input: "atan(x)", derivative: "1 / (1 + x ^ 2)"},
+		{input: "tan(x)", derivative: "1 / cos(x) ^ 2"},
+		{input: "acos(x)", derivative: "-1 / sqrt(1 - x ^ 2)"},
+		{input: "abs(x)", derivative: "x / abs(x)"},
+		{input: "floor(x) * x", derivative: "floor(x)"},
+		{input: "x > 0 ? x ^ 2 : -x", derivative: "x > 0 ? 2 * x : -1"},
+		{input: "max(x, 2 * x, 3)", derivative: "x >= max(2 * x, 3) ? 1 : 2 * x >= 3 ? 2 : 0"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.input, func(t *testing.T) {
+			e, err := calculator.Parse(tc.input)
+			require.NoError(t, err)
+
+			d, err := e.Derivative("x")
+			require.NoError(t, err)
+			assert.Equal(t, tc.derivative, d.String())
+		})
+	}
+}
+
+func TestExpr_Derivative_MatchesFiniteDifferences(t *testing.T) {
+	exprs := []string{
+		"x ^ 3 - 2 * x ^ 2 + x / 4",
+		"sin(x) * exp(x) / (1 + x ^ 2)",
+		"sqrt(x) * ln(x) + log(x) - log(x, 2)",
+		"x ^ x + 2 ^ x + x ^ 0.5",
+		"asin(x / 2) + acos(x / 3) + atan(x) * tan(x)",
+		"abs(x - 1) * min(x, 1 - x, 0.2)",
+	}
+
+	const h = 1e-6
+	for _, expr := range exprs {
+		t.Run(expr, func(t *testing.T) {
+			e, err := calculator.Parse(expr)
+			require.NoError(t, err)
+
+			d, err := e.Derivative("x")
+			require.NoError(t, err)
+
+			for _, x := range []float64{0.3, 0.7, 1.3} {
+				at := func(x float64) float64 {
+					v, err := e.Eval(map[string]calculator.Value{"x": calculator.Float(x)})
+					require.NoError(t, err)
+					return v.Float64()
+				}
+
+				v, err := d.Eval(map[string]calculator.Value{"x": calculator.Float(x)})
+				require.NoError(t, err)
+				assert.InDelta(t, (at(x+h)-at(x-h))/(2*h), v.Float64(), 1e-5, "at %v: %s", x, d)
+			}
+		})
+	}
+}
+
+func TestExpr_Derivative_Errors(t *testing.T) {
+	c, err := calculator.New()
+	require.NoError(t, err)
+	require.NoError(t, c.RegisterFunction("f", calculator.Function{MinArgs: 1, MaxArgs: 1, Call: func(args []calculator.Value) (calculator.Value, error) {
+		return args[0], nil
+	}}))
+
+	for _, expr := range []string{"x % 2", "x // 2", "x > 1", "!(x > 1)", "x > 1 && x < 2", "f(x)"} {
+		t.Run(expr, func(t *testing.T) {
+			e, err := c.Parse(expr)
+			require.NoError(t, err)
+
+			_, err = e.Derivative("x")
+			assert.True(t, errors.Is(err, calculator.ErrNotDifferentiable))
+		})
+	}
+}
+
+func TestExpr_Gradient(t *testing.T) {
+	e, err := calculator.Parse("x ^ 2 * y + 3 * y - z / x")
+	require.NoError(t, err)
+
+	gradient, err := e.Gradient()
+	require.NoError(t, err)
+	require.Len(t, gradient, 3)
+	assert.Equal(t, "2 * x * y + z / x ^ 2", gradient[0].String())
+	assert.Equal(t, "x ^ 2 + 3", gradient[1].String())
+	assert.Equal(t, "-1 / x", gradient[2].String())
+
+	gradient, err = e.Gradient("y")
+	require.NoError(t, err)
+	require.Len(t, gradient, 1)
+	assert.Equal(t, "x ^ 2 + 3", gradient[0].String())
+}
+
+func TestExpr_Substitute(t *testing.T) {
+	e, err := calculator.Parse("x ^ 2 + y")
+	require.NoError(t, err)
+
+	sum, err := calculator.Parse("a + 1")
+	require.NoError(t, err)
+
+	two, err := calculator.Parse("2")
+	require.NoError(t, err)
+
+	s, err := e.Substitute(map[string]*calculator.Expr{"x": sum, "y": two})
+	require.NoError(t, err)
+	assert.Equal(t, "(a + 1) ^ 2 + 2", s.String())
+	assert.Equal(t, "x ^ 2 + y", e.String())
+
+	v, err := s.Eval(map[string]calculator.Value{"a": calculator.Float(2)})
+	require.NoError(t, err)
+	assert.Equal(t, 11.0, v.Float64())
+
+	rat, err := calculator.New(calculator.WithMode(calculator.ModeRational))
+	require.NoError(t, err)
+
+	third, err := rat.Parse("x / 3")
+	require.NoError(t, err)
+
+	half, err := calculator.Parse("0.5 * z")
+	require.NoError(t, err)
+
+	s, err = third.Substitute(map[string]*calculator.Expr{"x": half.Simplify()})
+	require.NoError(t, err)
+	assert.Equal(t, "1/6 * z", s.Simplify().String())
+
+	c, err := calculator.New()
+	require.NoError(t, err)
+	require.NoError(t, c.RegisterFunction("f", calculator.Function{MinArgs: 1, MaxArgs: 1, Call: func(args []calculator.Value) (calculator.Value, error) {
+		return args[0], nil
+	}}))
+
+	custom, err := c.Parse("f(1)")
+	require.NoError(t, err)
+
+	_, err = e.Substitute(map[string]*calculator.Expr{"x": custom})
+	assert.True(t, errors.Is(err, calculator.ErrUnknownToken))
+}
+
+func TestExpr_Empty(t *testing.T) {
+	e, err := calculator.Parse("x + 1")
+	require.NoError(t, err)
+
+	var syntaxErr *calculator.SyntaxError
+	_, err = e.Substitute(map[string]*calculator.Expr{"x": nil})
+	require.True(t, errors.As(err, &syntaxErr), "got %v", err)
+	assert.Equal(t, "substitution for x: syntax error at position 0: empty expression", err.Error())
+
+	_, err = e.Substitute(map[string]*calculator.Expr{"x": {}})
+	assert.True(t, errors.As(err, &syntaxErr), "got %v", err)
+
+	empty := &calculator.Expr{}
+	assert.Equal(t, "", empty.String())
+	assert.Equal(t, "", empty.Compile().String())
+	assert.Empty(t, empty.Variables())
+	assert.Equal(t, empty, empty.Simplify())
+
+	_, err = empty.Eval(nil)
+	assert.True(t, errors.As(err, &syntaxErr), "got %v", err)
+
+	_, err = empty.Substitute(map[string]*calculator.Expr{"x": e})
+	assert.True(t, errors.As(err, &syntaxErr), "got %v", err)
+
+	_, err = empty.Derivative("x")
+	assert.True(t, errors.As(err, &syntaxErr), "got %v", err)
+}
+
+func TestExpr_Compile(t *testing.T) {
+	e, err := calculator.Parse("x * (2 + 3)")
+	require.NoError(t, err)
+
+	p := e.Compile()
+	assert.Equal(t, "x * 5", p.String())
+	assert.Equal(t, "x * (2 + 3)", e.String())
+
+	v, err := p.Eval(map[string]calculator.Value{"x": calculator.Float(math.Pi)})
+	require.NoError(t, err)
+	assert.Equal(t, 5*math.Pi, v.Float64())
+}
@@ -0,0 +1,281 @@
+package calculator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type TokenKind int8
+
+const (
+	NumberToken TokenKind = iota
+	VariableToken
+	OperatorToken
+	FunctionToken
+	// UnitToken is a name in the position of a unit like km in 3 km, it is looked up
+	// among the units of the calculator before the variables
+	UnitToken
+)
+
+// names of the prefix operators in postfix and prefix notations,
+// where they can not be told apart from the binary ones by position
+const (
+	negName   = "neg"
+	posName   = "pos"
+	complName = "compl"
+)
+
+// Token is an element of an expression in postfix (reverse polish) or prefix (polish) notation.
+// Args is the number of operands of an operator, 3 for the conditional ?:, or the number of arguments of a function call,
+// Pos is the byte offset of the token in the source it was parsed from.
+type Token struct {
+	Kind TokenKind
+	Text string
+	Args int
+	Pos  int
+}
+
+// String renders the token the way EvalRPNString reads it: prefix minus, plus and ~ are neg, pos and compl,
+// a function call with other than one argument is suffixed with the number of arguments like max:3
+func (t Token) String() string {
+	switch {
+	case t.Kind == OperatorToken && t.Args == 1 && t.Text == minus:
+		return negName
+	case t.Kind == OperatorToken && t.Args == 1 && t.Text == plus:
+		return posName
+	case t.Kind == OperatorToken && t.Args == 1 && t.Text == complement:
+		return complName
+	case t.Kind == FunctionToken && t.Args != 1:
+		return t.Text + ":" + strconv.Itoa(t.Args)
+	default:
+		return t.Text
+	}
+}
+
+type Tokens []Token
+
+// String joins the tokens with spaces
+func (ts Tokens) String() string {
+	parts := make([]string, len(ts))
+	for i, t := range ts {
+		parts[i] = t.String()
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// ToRPN converts an infix expression to postfix notation, "2 * (3 + 4)" becomes "2 3 4 + *"
+func ToRPN(expr string) (Tokens, error) {
+	queue, err := reversePolishTokenizer(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	// the tree is built only to report the same structural errors as evaluation does
+	if _, err := parseTree(queue); err != nil {
+		return nil, err
+	}
+
+	tokens := make(Tokens, len(queue))
+	for i, t := range queue {
+		tokens[i] = publicToken(t)
+	}
+
+	return tokens, nil
+}
+
+// ToPrefix converts an infix expression to prefix notation, "2 * (3 + 4)" becomes "* 2 + 3 4"
+func ToPrefix(expr string) (Tokens, error) {
+	queue, err := reversePolishTokenizer(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := parseTree(queue)
+	if err != nil {
+		return nil, err
+	}
+
+	var tokens Tokens
+	var visit func(n *node)
+	visit = func(n *node) {
+		tokens = append(tokens, nodeToken(n))
+		for _, arg := range n.args {
+			visit(arg)
+		}
+	}
+	visit(root)
+
+	return tokens, nil
+}
+
+// EvalRPN evaluates postfix tokens with float64 numbers
+func EvalRPN(tokens []Token, env map[string]Value) (Value, error) {
+	return defaultCalculator.EvalRPN(tokens, env)
+}
+
+// EvalRPNString evaluates a postfix expression with float64 numbers
+func EvalRPNString(expr string, env map[string]Value) (Value, error) {
+	return defaultCalculator.EvalRPNString(expr, env)
+}
+
+// EvalRPN evaluates postfix tokens, for example the ones returned by ToRPN
+func (c *Calculator) EvalRPN(tokens []Token, env map[string]Value) (Value, error) {
+	queue := make(tokenQueue, len(tokens))
+	for i, t := range tokens {
+		switch t.Kind {
+		case NumberToken:
+			queue[i] = token{value: t.Text, kind: number, pos: t.Pos}
+		case VariableToken, UnitToken:
+			queue[i] = token{value: t.Text, kind: identifier, pos: t.Pos, unit: t.Kind == UnitToken}
+		case FunctionToken:
+			if t.Args < 0 {
+				return Value{}, syntaxError(t.Pos, "invalid number of arguments in %q", t.String())
+			}
+			queue[i] = token{value: t.Text, kind: function, pos: t.Pos, args: t.Args}
+		case OperatorToken:
+			queue[i] = token{value: t.Text, kind: operator, pos: t.Pos, unary: t.Args == 1}
+		default:
+			return Value{}, &SyntaxError{Pos: t.Pos, Msg: fmt.Sprintf("unknown token %q", t.Text), Err: ErrUnknownToken}
+		}
+	}
+
+	p, err := c.program(queue)
+	if err != nil {
+		return Value{}, err
+	}
+
+	return p.Eval(env)
+}
+
+// EvalRPNString evaluates a postfix expression with tokens separated by whitespace like "3 4 neg max:2 2 ^".
+// A name is a call of a function with one argument when such a function exists, a unit when the calculator
+// has such a unit and a variable otherwise, in and to are always the conversion operators.
+func (c *Calculator) EvalRPNString(expr string, env map[string]Value) (Value, error) {
+	tokens, err := c.lexRPN(expr)
+	if err != nil {
+		return Value{}, err
+	}
+
+	return c.EvalRPN(tokens, env)
+}
+
+func (c *Calculator) lexRPN(expr string) ([]Token, error) {
+	var tokens []Token
+
+	for pos := 0; pos < len(expr); {
+		r, width := utf8.DecodeRuneInString(expr[pos:])
+		if unicode.IsSpace(r) {
+			pos += width
+			continue
+		}
+
+		start := pos
+		for pos < len(expr) {
+			r, width := utf8.DecodeRuneInString(expr[pos:])
+			if unicode.IsSpace(r) {
+				break
+			}
+			pos += width
+		}
+
+		t, err := c.rpnToken(expr[start:pos], start)
+		if err != nil {
+			return nil, err
+		}
+
+		tokens = append(tokens, t)
+	}
+
+	return tokens, nil
+}
+
+func (c *Calculator) rpnToken(text string, pos int) (Token, error) {
+	switch {
+	case text == negName:
+		return Token{Kind: OperatorToken, Text: minus, Args: 1, Pos: pos}, nil
+	case text == posName:
+		return Token{Kind: OperatorToken, Text: plus, Args: 1, Pos: pos}, nil
+	case text == complName:
+		return Token{Kind: OperatorToken, Text: complement, Args: 1, Pos: pos}, nil
+	case text == not:
+		return Token{Kind: OperatorToken, Text: not, Args: 1, Pos: pos}, nil
+	case text == conditional:
+		return Token{Kind: OperatorToken, Text: conditional, Args: 3, Pos: pos}, nil
+	case isOperator(text):
+		return Token{Kind: OperatorToken, Text: text, Args: 2, Pos: pos}, nil
+	case isDigit(rune(text[0])) || text[0] == '.':
+		if end, err := scanNumber(text, 0); err != nil || end != len(text) {
+			return Token{}, syntaxError(pos, "malformed number %q", text)
+		}
+		return Token{Kind: NumberToken, Text: text, Pos: pos}, nil
+	}
+
+	name, args := text, ""
+	if i := strings.IndexByte(text, ':'); i >= 0 {
+		name, args = text[:i], text[i+1:]
+	}
+
+	if !isIdentifier(name) {
+		return Token{}, &SyntaxError{Pos: pos, Msg: fmt.Sprintf("unknown token %q", text), Err: ErrUnknownToken}
+	}
+
+	if args != "" {
+		n, err := strconv.Atoi(args)
+		if err != nil || n < 0 {
+			return Token{}, syntaxError(pos, "invalid number of arguments in %q", text)
+		}
+		return Token{Kind: FunctionToken, Text: name, Args: n, Pos: pos}, nil
+	}
+
+	if _, ok := c.functions[name]; ok {
+		return Token{Kind: FunctionToken, Text: name, Args: 1, Pos: pos}, nil
+	}
+
+	if _, ok := c.units[name]; ok {
+		return Token{Kind: UnitToken, Text: name, Pos: pos}, nil
+	}
+
+	return Token{Kind: VariableToken, Text: name, Pos: pos}, nil
+}
+
+func isOperator(text string) bool {
+	_, ok := binaryOperators[text]
+	return ok
+}
+
+func publicToken(t token) Token {
+	switch t.kind {
+	case number:
+		return Token{Kind: NumberToken, Text: t.value, Pos: t.pos}
+	case identifier:
+		if t.unit {
+			return Token{Kind: UnitToken, Text: t.value, Pos: t.pos}
+		}
+		return Token{Kind: VariableToken, Text: t.value, Pos: t.pos}
+	case function:
+		return Token{Kind: FunctionToken, Text: t.value, Args: t.args, Pos: t.pos}
+	default:
+		oi, _ := lookupOperator(t)
+		return Token{Kind: OperatorToken, Text: t.value, Args: oi.arity(), Pos: t.pos}
+	}
+}
+
+func nodeToken(n *node) Token {
+	switch n.kind {
+	case numberNode:
+		return Token{Kind: NumberToken, Text: n.literal(), Pos: n.pos}
+	case variableNode:
+		if n.unit {
+			return Token{Kind: UnitToken, Text: n.name, Pos: n.pos}
+		}
+		return Token{Kind: VariableToken, Text: n.name, Pos: n.pos}
+	case callNode:
+		return Token{Kind: FunctionToken, Text: n.name, Args: len(n.args), Pos: n.pos}
+	default:
+		return Token{Kind: OperatorToken, Text: n.name, Args: len(n.args), Pos: n.pos}
+	}
+}
@@ -0,0 +1,155 @@
+package calculator_test
+
+import (
+	"github.com/denismitr/gds/calculator"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestToRPN(t *testing.T) {
+	tt := []struct {
+		input string
+		rpn   string
+	}{
+		{input: "2 + 2", rpn: "2 2 +"},
+		{input: "2 * (3 + 4)", rpn: "2 3 4 + *"},
+		{input: "8 - 3 - 2", rpn: "8 3 - 2 -"},
+		{input: "2 ^ 3 ^ 2", rpn: "2 3 2 ^ ^"},
+		{input: "-x ^ 2", rpn: "x 2 ^ neg"},
+		{input: "+x * -y", rpn: "x pos y neg *"},
+		{input: "max(a, b, 3) + sqrt(4)", rpn: "a b 3 max:3 4 sqrt +"},
+		{input: "f() // 2 % 1.5", rpn: "f:0 2 // 1.5 %"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.input, func(t *testing.T) {
+			tokens, err := calculator.ToRPN(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.rpn, tokens.String())
+		})
+	}
+
+	tokens, err := calculator.ToRPN("max(x, 2) * -1")
+	require.NoError(t, err)
+	assert.Equal(t, calculator.Tokens{
+		{Kind: calculator.VariableToken, Text: "x", Pos: 4},
+		{Kind: calculator.NumberToken, Text: "2", Pos: 7},
+		{Kind: calculator.FunctionToken, Text: "max", Args: 2, Pos: 0},
+		{Kind: calculator.NumberToken, Text: "1", Pos: 13},
+		{Kind: calculator.OperatorToken, Text: "-", Args: 1, Pos: 12},
+		{Kind: calculator.OperatorToken, Text: "*", Args: 2, Pos: 10},
+	}, tokens)
+
+	_, err = calculator.ToRPN("2 +")
+	var syntaxErr *calculator.SyntaxError
+	assert.True(t, errors.As(err, &syntaxErr))
+}
+
+func TestToPrefix(t *testing.T) {
+	tt := []struct {
+		input  string
+		prefix string
+	}{
+		{input: "2 + 2", prefix: "+ 2 2"},
+		{input: "2 * (3 + 4)", prefix: "* 2 + 3 4"},
+		{input: "8 - 3 - 2", prefix: "- - 8 3 2"},
+		{input: "2 ^ 3 ^ 2", prefix: "^ 2 ^ 3 2"},
+		{input: "-x ^ 2", prefix: "neg ^ x 2"},
+		{input: "max(a, b + 1) / 2", prefix: "/ max:2 a + b 1 2"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.input, func(t *testing.T) {
+			tokens, err := calculator.ToPrefix(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.prefix, tokens.String())
+		})
+	}
+}
+
+func TestEvalRPN(t *testing.T) {
+	env := map[string]calculator.Value{"x": calculator.Float(3)}
+
+	for _, expr := range []string{"2 * (3 + 4) - x", "-x ^ 2 + max(x, 10, 2)", "sqrt(16) // 3 % 2"} {
+		t.Run(expr, func(t *testing.T) {
+			expected, err := calculator.Evaluate(expr, env)
+			require.NoError(t, err)
+
+			tokens, err := calculator.ToRPN(expr)
+			require.NoError(t, err)
+
+			v, err := calculator.EvalRPN(tokens, env)
+			require.NoError(t, err)
+			assert.Equal(t, expected, v)
+
+			v, err = calculator.EvalRPNString(tokens.String(), env)
+			require.NoError(t, err)
+			assert.Equal(t, expected, v)
+		})
+	}
+}
+
+func TestEvalRPN_NegativeArgumentCount(t *testing.T) {
+	tokens := []calculator.Token{
+		{Kind: calculator.NumberToken, Text: "1"},
+		{Kind: calculator.FunctionToken, Text: "max", Args: -1, Pos: 2},
+	}
+
+	_, err := calculator.EvalRPN(tokens, nil)
+	var syntaxErr *calculator.SyntaxError
+	require.True(t, errors.As(err, &syntaxErr), "got %v", err)
+	assert.Equal(t, 2, syntaxErr.Pos)
+	assert.Equal(t, `invalid number of arguments in "max:-1"`, syntaxErr.Msg)
+}
+
+func TestEvalRPNString(t *testing.T) {
+	tt := []struct {
+		input  string
+		output float64
+	}{
+		{input: "3 4 +", output: 7},
+		{input: "5 1 2 + 4 * + 3 -", output: 14},
+		{input: "2 3 ^ neg", output: -8},
+		{input: "16 sqrt 1 pos +", output: 5},
+		{input: "1 5 3 max:3", output: 5},
+		{input: "100 log 8 2 log:2 *", output: 6},
+		{input: "  1.5e1\t2\n/ ", output: 7.5},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.input, func(t *testing.T) {
+			v, err := calculator.EvalRPNString(tc.input, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tc.output, v.Float64())
+		})
+	}
+
+	errs := []struct {
+		input string
+		pos   int
+	}{
+		{input: "1 +", pos: 2},
+		{input: "1 2", pos: 2},
+		{input: "1 2 3 max:x", pos: 6},
+		{input: "1 2..3 +", pos: 2},
+		{input: "1 $ +", pos: 2},
+		{input: "", pos: 0},
+	}
+
+	for _, tc := range errs {
+		t.Run(tc.input, func(t *testing.T) {
+			_, err := calculator.EvalRPNString(tc.input, nil)
+			var syntaxErr *calculator.SyntaxError
+			require.True(t, errors.As(err, &syntaxErr), "got %v", err)
+			assert.Equal(t, tc.pos, syntaxErr.Pos)
+		})
+	}
+
+	_, err := calculator.EvalRPNString("1 y +", nil)
+	assert.True(t, errors.Is(err, calculator.ErrUndefinedVariable))
+
+	_, err = calculator.EvalRPNString("1 2 sqrt:2", nil)
+	assert.True(t, errors.Is(err, calculator.ErrWrongArgumentCount))
+}
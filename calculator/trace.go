@@ -0,0 +1,206 @@
+package calculator
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// ConversionStep is a step of the shunting-yard conversion to postfix notation,
+// Stack and Output are the operator stack and the output queue after the step
+type ConversionStep struct {
+	Token  string
+	Action string
+	Stack  []string
+	Output []string
+}
+
+// ReductionStep is an instruction executed by the evaluation of the postfix expression,
+// Operands are the values it popped, Result is the value it pushed and Stack is the evaluation stack after it
+type ReductionStep struct {
+	Token    string
+	Action   string
+	Operands []string
+	Result   string
+	Stack    []string
+}
+
+// Trace records how an expression is converted to postfix notation and how that is evaluated
+type Trace struct {
+	Input      string
+	Conversion []ConversionStep
+	RPN        Tokens
+	Reduction  []ReductionStep
+	Result     Value
+	// Output is the result formatted like Format does
+	Output string
+}
+
+// Explain evaluates the expression with float64 numbers and records every step
+func Explain(expr string, env map[string]Value) (*Trace, error) {
+	return defaultCalculator.Explain(expr, env)
+}
+
+// Explain evaluates the expression like Evaluate and records every step. Constants are not folded,
+// so every operation of the expression is in the trace, and the operands of && and || and the branches of ?:
+// that are skipped are missing from it. On an error the trace has the steps up to the failure.
+func (c *Calculator) Explain(expr string, env map[string]Value) (*Trace, error) {
+	t := &Trace{Input: expr}
+
+	tokens, err := expression(expr)
+	if err != nil {
+		return t, err
+	}
+
+	queue, err := convert(tokens, &t.Conversion)
+	if err != nil {
+		return t, err
+	}
+
+	t.RPN = make(Tokens, len(queue))
+	for i, tok := range queue {
+		t.RPN[i] = publicToken(tok)
+	}
+
+	root, err := parseTree(queue)
+	if err != nil {
+		return t, err
+	}
+
+	if err := c.bind(root); err != nil {
+		return t, err
+	}
+
+	p := &Program{calculator: c, root: root}
+	p.emit(root, 0)
+
+	v, err := p.eval(env, &t.Reduction)
+	if err != nil {
+		return t, err
+	}
+
+	t.Result, t.Output = v, c.Format(v)
+	return t, nil
+}
+
+// String renders the trace as text tables
+func (t *Trace) String() string {
+	return t.Table()
+}
+
+// Table renders the conversion and the reduction steps as text tables followed by the result
+func (t *Trace) Table() string {
+	var sb strings.Builder
+
+	sb.WriteString("conversion of " + t.Input + "\n")
+	rows := make([][]string, len(t.Conversion))
+	for i, s := range t.Conversion {
+		token := s.Token
+		if token == "" {
+			token = "(end)"
+		}
+		rows[i] = []string{token, s.Action, strings.Join(s.Stack, " "), strings.Join(s.Output, " ")}
+	}
+	writeTable(&sb, []string{"token", "action", "operator stack", "output"}, rows)
+
+	sb.WriteString("\nreduction of " + t.RPN.String() + "\n")
+	rows = make([][]string, len(t.Reduction))
+	for i, s := range t.Reduction {
+		rows[i] = []string{s.Token, s.Action, strings.Join(s.Operands, " "), s.Result, strings.Join(s.Stack, " ")}
+	}
+	writeTable(&sb, []string{"token", "action", "operands", "result", "stack"}, rows)
+
+	if t.Output != "" {
+		sb.WriteString("\nresult: " + t.Output + "\n")
+	}
+
+	return sb.String()
+}
+
+// writeTable writes the rows with columns padded to the widest cell and a rule under the header
+func writeTable(sb *strings.Builder, header []string, rows [][]string) {
+	widths := make([]int, len(header))
+	for _, row := range append([][]string{header}, rows...) {
+		for i, cell := range row {
+			if n := utf8.RuneCountInString(cell); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	writeRow := func(row []string) {
+		for i, cell := range row {
+			sb.WriteString("| " + cell + strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)) + " ")
+		}
+		sb.WriteString("|\n")
+	}
+
+	writeRow(header)
+	for _, w := range widths {
+		sb.WriteString("|" + strings.Repeat("-", w+2))
+	}
+	sb.WriteString("|\n")
+
+	for _, row := range rows {
+		writeRow(row)
+	}
+}
+
+// operands are the values the instruction pops from the stack
+func (in *instruction) operands(stack []Value) []Value {
+	n := 0
+	switch in.op {
+	case opUnary, opJumpUnless, opShortCircuit:
+		n = 1
+	case opBinary:
+		n = 2
+	case opCall:
+		n = in.args
+	}
+
+	return append([]Value(nil), stack[len(stack)-n:]...)
+}
+
+// step describes the executed instruction, jumped tells whether a jump was taken
+func (in *instruction) step(operands, stack []Value, jumped bool) ReductionStep {
+	s := ReductionStep{Operands: valueStrings(operands), Stack: valueStrings(stack)}
+
+	switch in.op {
+	case opConst:
+		s.Token, s.Action = in.name, "push the number"
+	case opLoad:
+		s.Token, s.Action = in.name, "push the variable"
+	case opLoadUnit:
+		s.Token, s.Action = in.name, "push the unit"
+	case opUnary, opBinary:
+		s.Token, s.Action = Token{Kind: OperatorToken, Text: in.name, Args: in.args}.String(), "apply the operator"
+	case opCall:
+		s.Token, s.Action = Token{Kind: FunctionToken, Text: in.name, Args: in.args}.String(), "call the function"
+	case opJumpUnless:
+		s.Token, s.Action = in.name, "condition is true, evaluate the first branch"
+		if jumped {
+			s.Action = "condition is false, evaluate the second branch"
+		}
+	case opJump:
+		s.Token, s.Action = in.name, "skip the second branch"
+	case opShortCircuit:
+		s.Token, s.Action = in.name, "left operand does not decide, evaluate the right one"
+		if jumped {
+			s.Action = "left operand decides, skip the right one"
+		}
+	}
+
+	if in.op <= opCall {
+		s.Result = stack[len(stack)-1].String()
+	}
+
+	return s
+}
+
+func valueStrings(values []Value) []string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = v.String()
+	}
+
+	return s
+}
@@ -0,0 +1,159 @@
+package calculator_test
+
+import (
+	"github.com/denismitr/gds/calculator"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestExplain_Conversion(t *testing.T) {
+	trace, err := calculator.Explain("2 * (3 + 4)", nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []calculator.ConversionStep{
+		{Token: "2", Action: "add the number to the output", Stack: []string{}, Output: []string{"2"}},
+		{Token: "*", Action: "push the operator", Stack: []string{"*"}, Output: []string{"2"}},
+		{Token: "(", Action: "push the bracket", Stack: []string{"*", "("}, Output: []string{"2"}},
+		{Token: "3", Action: "add the number to the output", Stack: []string{"*", "("}, Output: []string{"2", "3"}},
+		{Token: "+", Action: "push the operator", Stack: []string{"*", "(", "+"}, Output: []string{"2", "3"}},
+		{Token: "4", Action: "add the number to the output", Stack: []string{"*", "(", "+"}, Output: []string{"2", "3", "4"}},
+		{Token: ")", Action: "pop + to the output", Stack: []string{"*", "("}, Output: []string{"2", "3", "4", "+"}},
+		{Token: ")", Action: "discard the brackets", Stack: []string{"*"}, Output: []string{"2", "3", "4", "+"}},
+		{Token: "", Action: "pop * to the output", Stack: []string{}, Output: []string{"2", "3", "4", "+", "*"}},
+	}, trace.Conversion)
+	assert.Equal(t, "2 3 4 + *", trace.RPN.String())
+}
+
+func TestExplain_Reduction(t *testing.T) {
+	trace, err := calculator.Explain("2 * (3 + 4)", nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []calculator.ReductionStep{
+		{Token: "2", Action: "push the number", Operands: []string{}, Result: "2", Stack: []string{"2"}},
+		{Token: "3", Action: "push the number", Operands: []string{}, Result: "3", Stack: []string{"2", "3"}},
+		{Token: "4", Action: "push the number", Operands: []string{}, Result: "4", Stack: []string{"2", "3", "4"}},
+		{Token: "+", Action: "apply the operator", Operands: []string{"3", "4"}, Result: "7", Stack: []string{"2", "7"}},
+		{Token: "*", Action: "apply the operator", Operands: []string{"2", "7"}, Result: "14", Stack: []string{"14"}},
+	}, trace.Reduction)
+	assert.Equal(t, calculator.Float(14), trace.Result)
+	assert.Equal(t, "14", trace.Output)
+}
+
+func TestExplain_Steps(t *testing.T) {
+	tt := []struct {
+		input     string
+		env       map[string]calculator.Value
+		rpn       string
+		reduction []string
+		output    string
+	}{
+		{
+			input:     "max(1, -x) ^ 2",
+			env:       map[string]calculator.Value{"x": calculator.Float(3)},
+			rpn:       "1 x neg max:2 2 ^",
+			reduction: []string{"1", "x", "neg", "max:2", "2", "^"},
+			output:    "1",
+		},
+		{
+			input:     "2 + 3 * 4",
+			rpn:       "2 3 4 * +",
+			reduction: []string{"2", "3", "4", "*", "+"},
+			output:    "14",
+		},
+		{
+			input:     "x > 1 && x < 5",
+			env:       map[string]calculator.Value{"x": calculator.Float(0)},
+			rpn:       "x 1 > x 5 < &&",
+			reduction: []string{"x", "1", ">", "&&"},
+			output:    "false",
+		},
+		{
+			input:     "x > 1 ? 10 : 20",
+			env:       map[string]calculator.Value{"x": calculator.Float(2)},
+			rpn:       "x 1 > 10 20 ?:",
+			reduction: []string{"x", "1", ">", "?:", "10", "?:"},
+			output:    "10",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.input, func(t *testing.T) {
+			trace, err := calculator.Explain(tc.input, tc.env)
+			require.NoError(t, err)
+
+			tokens := make([]string, len(trace.Reduction))
+			for i, s := range trace.Reduction {
+				tokens[i] = s.Token
+			}
+
+			assert.Equal(t, tc.rpn, trace.RPN.String())
+			assert.Equal(t, tc.reduction, tokens)
+			assert.Equal(t, tc.output, trace.Output)
+		})
+	}
+}
+
+func TestExplain_ShortCircuit(t *testing.T) {
+	trace, err := calculator.Explain("false && x", nil)
+	require.NoError(t, err)
+
+	require.Len(t, trace.Reduction, 2)
+	assert.Equal(t, "left operand decides, skip the right one", trace.Reduction[1].Action)
+	assert.Equal(t, []string{"false"}, trace.Reduction[1].Operands)
+	assert.Equal(t, "false", trace.Output)
+}
+
+func TestExplain_Errors(t *testing.T) {
+	t.Run("evaluation", func(t *testing.T) {
+		trace, err := calculator.Explain("1 + 2 / 0", nil)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, calculator.ErrDivisionByZero))
+
+		require.NotNil(t, trace)
+		assert.Len(t, trace.Conversion, 7)
+		assert.Len(t, trace.Reduction, 3)
+		assert.Equal(t, []string{"1", "2", "0"}, trace.Reduction[2].Stack)
+		assert.Equal(t, "", trace.Output)
+	})
+
+	t.Run("conversion", func(t *testing.T) {
+		trace, err := calculator.Explain("(1 + 2", nil)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, calculator.ErrMismatchedParentheses))
+
+		require.NotNil(t, trace)
+		assert.Len(t, trace.Conversion, 4)
+		assert.Empty(t, trace.Reduction)
+	})
+}
+
+func TestTrace_Table(t *testing.T) {
+	trace, err := calculator.Explain("1 + 2", nil)
+	require.NoError(t, err)
+
+	expected := strings.Join([]string{
+		"conversion of 1 + 2",
+		"| token | action                       | operator stack | output |",
+		"|-------|------------------------------|----------------|--------|",
+		"| 1     | add the number to the output |                | 1      |",
+		"| +     | push the operator            | +              | 1      |",
+		"| 2     | add the number to the output | +              | 1 2    |",
+		"| (end) | pop + to the output          |                | 1 2 +  |",
+		"",
+		"reduction of 1 2 +",
+		"| token | action             | operands | result | stack |",
+		"|-------|--------------------|----------|--------|-------|",
+		"| 1     | push the number    |          | 1      | 1     |",
+		"| 2     | push the number    |          | 2      | 1 2   |",
+		"| +     | apply the operator | 1 2      | 3      | 3     |",
+		"",
+		"result: 3",
+		"",
+	}, "\n")
+
+	assert.Equal(t, expected, trace.Table())
+	assert.Equal(t, expected, trace.String())
+}
@@ -0,0 +1,545 @@
+package calculator
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var ErrDimension = errors.New("incompatible dimensions")
+var ErrInvalidUnit = errors.New("invalid unit")
+
+// UnknownUnitError is returned when a name in the position of a unit like x in 2 x
+// is neither a unit nor a defined name
+type UnknownUnitError struct {
+	Name string
+	Pos  int
+	// Suggestion is the closest unit or defined name, it is empty when nothing is similar enough
+	Suggestion string
+}
+
+func (e *UnknownUnitError) Error() string {
+	msg := fmt.Sprintf("unknown unit %s at position %d", e.Name, e.Pos)
+	if e.Suggestion != "" {
+		msg += fmt.Sprintf(", did you mean %s?", e.Suggestion)
+	}
+
+	return msg
+}
+
+func (e *UnknownUnitError) Unwrap() error {
+	return ErrInvalidUnit
+}
+
+// power is a name raised to an integer exponent like s^-2
+type power struct {
+	name string
+	exp  int
+}
+
+func (p power) String() string {
+	if p.exp == 1 {
+		return p.name
+	}
+
+	return p.name + pow + strconv.Itoa(p.exp)
+}
+
+// namedUnit is an entry of the unit table like km, scale is its size in base units
+// and dimension is the product of the base units it is measured in
+type namedUnit struct {
+	name      string
+	scale     *big.Rat
+	dimension []power
+}
+
+// unitPower is a named unit raised to an integer exponent
+type unitPower struct {
+	unit *namedUnit
+	exp  int
+}
+
+// unit is a product of named units like km/h, the units of a quantity can be converted
+// into each other when their dimensions are equal
+type unit struct {
+	name      string
+	powers    []unitPower
+	scale     *big.Rat
+	dimension []power
+	// key is the rendered dimension, equal dimensions have equal keys
+	key string
+}
+
+// dimensionless is the unit of plain numbers
+var dimensionless = newUnit(nil)
+
+func newUnit(powers []unitPower) *unit {
+	u := unit{powers: powers, scale: big.NewRat(1, 1)}
+	names := make([]power, len(powers))
+	exps := make(map[string]int)
+	for i, p := range powers {
+		names[i] = power{name: p.unit.name, exp: p.exp}
+		u.scale.Mul(u.scale, ratPow(p.unit.scale, p.exp))
+		for _, d := range p.unit.dimension {
+			exps[d.name] += d.exp * p.exp
+		}
+	}
+
+	for name, exp := range exps {
+		if exp != 0 {
+			u.dimension = append(u.dimension, power{name: name, exp: exp})
+		}
+	}
+	sort.Slice(u.dimension, func(i, j int) bool { return u.dimension[i].name < u.dimension[j].name })
+
+	u.name = renderPowers(names)
+	u.key = renderPowers(u.dimension)
+	return &u
+}
+
+// times multiplies the units, the exponents of w are multiplied by sign, so -1 divides,
+// it fails when an exponent of the result is out of the range of maxUnitExponent
+func (u *unit) times(w *unit, sign int) (*unit, error) {
+	powers := append([]unitPower(nil), u.powers...)
+	for _, p := range w.powers {
+		powers = addPower(powers, unitPower{unit: p.unit, exp: p.exp * sign})
+	}
+
+	for _, p := range powers {
+		if p.exp > maxUnitExponent || p.exp < -maxUnitExponent {
+			return nil, errors.Wrapf(ErrOverflow, "exponent greater than %d", maxUnitExponent)
+		}
+	}
+
+	return newUnit(powers), nil
+}
+
+// maxUnitExponent limits the exponents of units, so that their scales stay small
+const maxUnitExponent = 100
+
+// power raises the unit to a rational exponent, it fails when an exponent of the result
+// is not an integer or is out of the range of maxUnitExponent
+func (u *unit) power(r *big.Rat) (*unit, error) {
+	limit := big.NewRat(maxUnitExponent, 1)
+	powers := make([]unitPower, 0, len(u.powers))
+	for _, p := range u.powers {
+		exp := new(big.Rat).Mul(r, big.NewRat(int64(p.exp), 1))
+		if !exp.IsInt() {
+			return nil, ErrDimension
+		}
+		if new(big.Rat).Abs(exp).Cmp(limit) > 0 {
+			return nil, errors.Wrapf(ErrOverflow, "exponent greater than %d", maxUnitExponent)
+		}
+		if exp.Sign() != 0 {
+			powers = append(powers, unitPower{unit: p.unit, exp: int(exp.Num().Int64())})
+		}
+	}
+
+	return newUnit(powers), nil
+}
+
+// dimensionName names the dimension in base units for error messages
+func (u *unit) dimensionName() string {
+	if u.key == "" {
+		return "a number"
+	}
+
+	return u.key
+}
+
+// addPower multiplies the powers by p, the powers of the same unit are merged
+// and the ones with a zero exponent are dropped
+func addPower(powers []unitPower, p unitPower) []unitPower {
+	for i := range powers {
+		if powers[i].unit == p.unit {
+			powers[i].exp += p.exp
+			if powers[i].exp == 0 {
+				return append(powers[:i], powers[i+1:]...)
+			}
+			return powers
+		}
+	}
+
+	return append(powers, p)
+}
+
+// renderPowers renders a unit the way it is parsed after a number: km/h, m^2, kg*m/s^2, m/(s*kg) or s^-1
+func renderPowers(powers []power) string {
+	var num, den []string
+	for _, p := range powers {
+		if p.exp > 0 {
+			num = append(num, p.String())
+		} else {
+			den = append(den, power{name: p.name, exp: -p.exp}.String())
+		}
+	}
+
+	switch {
+	case len(num) == 0 && len(den) == 0:
+		return ""
+	case len(num) == 0:
+		// there is nothing to divide, so the exponents stay negative
+		all := make([]string, len(powers))
+		for i, p := range powers {
+			all[i] = p.String()
+		}
+		return strings.Join(all, mul)
+	case len(den) == 0:
+		return strings.Join(num, mul)
+	case len(den) == 1:
+		return strings.Join(num, mul) + div + den[0]
+	default:
+		return strings.Join(num, mul) + div + leftBracket + strings.Join(den, mul) + rightBracket
+	}
+}
+
+func ratPow(r *big.Rat, exp int) *big.Rat {
+	abs := big.NewInt(int64(exp))
+	abs.Abs(abs)
+	num := new(big.Int).Exp(r.Num(), abs, nil)
+	den := new(big.Int).Exp(r.Denom(), abs, nil)
+	if exp < 0 {
+		num, den = den, num
+	}
+
+	return new(big.Rat).SetFrac(num, den)
+}
+
+// newQuantity makes a quantity of the magnitude in base units
+func newQuantity(magnitude *big.Rat, u *unit) Value {
+	return Value{mode: modeQuantity, r: magnitude, x: &extra{unit: u}}
+}
+
+// unit returns the unit of a quantity, numbers are dimensionless
+func (v Value) unit() *unit {
+	if v.mode != modeQuantity {
+		return dimensionless
+	}
+
+	return v.x.unit
+}
+
+// Unit is the unit of a quantity like km/h, it is empty for numbers and booleans
+func (v Value) Unit() string {
+	return v.unit().name
+}
+
+// magnitude is the number of units in a quantity, so it is 3 for 3 km
+func (v Value) magnitude() *big.Rat {
+	return new(big.Rat).Quo(v.r, v.unit().scale)
+}
+
+// base is the magnitude in base units, so it is 3000 for 3 km, it is nil for infinities and NaN
+func (v Value) base() *big.Rat {
+	if v.mode == modeQuantity {
+		return v.r
+	}
+
+	return v.Rat()
+}
+
+// formatMagnitude formats a magnitude so that it is parsed back before its unit,
+// fractions that are not floats are enclosed in brackets like (1/3) km
+func formatMagnitude(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+
+	if f, exact := r.Float64(); exact {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+
+	return leftBracket + r.RatString() + rightBracket
+}
+
+// measure makes a quantity of the magnitude in base units, a result without a dimension like km/m
+// is a number of the mode of the calculator
+func (c *Calculator) measure(magnitude *big.Rat, u *unit) (Value, error) {
+	if u.key != "" {
+		return c.quantity(magnitude, u)
+	}
+
+	v := c.arithmetic.convert(Rat(magnitude))
+	if v.mode != c.arithmetic.mode() {
+		return Value{}, inexact(v, c.arithmetic.mode())
+	}
+
+	return v, nil
+}
+
+// quantity makes a quantity of the magnitude in base units, the integer modes can not represent
+// fractions of units, so they fail instead of truncating the magnitude like 3.25 km to 3 km
+func (c *Calculator) quantity(magnitude *big.Rat, u *unit) (Value, error) {
+	v := newQuantity(magnitude, u)
+	if !c.represents(v) {
+		return Value{}, errors.Wrapf(ErrDomain, "%s %s cannot be used in %s mode", formatMagnitude(v.magnitude()), u.name, c.Mode())
+	}
+
+	return v, nil
+}
+
+// represents reports whether the magnitude of the quantity is a number of the mode of the calculator
+func (c *Calculator) represents(v Value) bool {
+	return !c.Mode().integer() || v.magnitude().IsInt()
+}
+
+// binaryQuantity applies a binary operator when one of the operands is a quantity. Magnitudes are exact
+// fractions in every mode, they are converted to the mode only when the units cancel out and when they are formatted.
+func (c *Calculator) binaryQuantity(op string, x, y Value) (Value, error) {
+	u, w := x.unit(), y.unit()
+	mx, my := x.base(), y.base()
+	if mx == nil || my == nil {
+		return Value{}, errors.Wrap(ErrDomain, "infinity and NaN can not have units")
+	}
+
+	exact := ratArithmetic{}
+	switch op {
+	case mul, div:
+		var m Value
+		var err error
+		if op == mul {
+			m, err = exact.mul(Rat(mx), Rat(my))
+		} else {
+			m, err = exact.div(Rat(mx), Rat(my))
+		}
+		if err != nil {
+			return Value{}, err
+		}
+
+		sign := 1
+		if op == div {
+			sign = -1
+		}
+
+		result, err := u.times(w, sign)
+		if err != nil {
+			return Value{}, errors.Wrapf(err, "%s %s %s is not a unit", u.name, op, w.name)
+		}
+		return c.measure(m.r, result)
+	case pow:
+		if y.mode == modeQuantity {
+			return Value{}, errors.Wrapf(ErrDimension, "exponent %s has a unit", y)
+		}
+
+		result, err := u.power(my)
+		if err != nil {
+			return Value{}, errors.Wrapf(err, "%s%s%s is not a unit", u.name, pow, y)
+		}
+
+		m, err := exact.pow(Rat(mx), Rat(my))
+		if err != nil {
+			return Value{}, err
+		}
+		if m.mode != ModeRational {
+			return Value{}, errors.Wrapf(ErrOverflow, "%s", m)
+		}
+		return c.measure(m.r, result)
+	}
+
+	if u.key != w.key {
+		return Value{}, errors.Wrapf(ErrDimension, "%s and %s", u.dimensionName(), w.dimensionName())
+	}
+
+	switch op {
+	case inUnit, toUnit:
+		if len(w.powers) == 0 {
+			return c.measure(mx, w)
+		}
+		return c.quantity(mx, w)
+	case plus, minus, mod:
+		var m Value
+		var err error
+		switch op {
+		case plus:
+			m, err = exact.add(Rat(mx), Rat(my))
+		case minus:
+			m, err = exact.sub(Rat(mx), Rat(my))
+		default:
+			m, err = exact.mod(Rat(mx), Rat(my))
+		}
+		if err != nil {
+			return Value{}, err
+		}
+		return c.measure(m.r, u)
+	case floorDiv:
+		m, err := exact.floorDiv(Rat(mx), Rat(my))
+		if err != nil {
+			return Value{}, err
+		}
+		return c.measure(m.r, dimensionless)
+	case eq, ne, lt, le, gt, ge:
+		return comparison(op)(exact, Rat(mx), Rat(my))
+	default:
+		return Value{}, errors.Wrapf(ErrDimension, "%s needs numbers without units", op)
+	}
+}
+
+// unaryQuantity applies a prefix operator to a quantity
+func (c *Calculator) unaryQuantity(op string, x Value) (Value, error) {
+	switch op {
+	case plus:
+		return x, nil
+	case minus:
+		return newQuantity(new(big.Rat).Neg(x.r), x.unit()), nil
+	default:
+		return Value{}, errors.Wrapf(ErrDimension, "%s needs a number without a unit", op)
+	}
+}
+
+// callQuantity calls a function with quantities among its arguments, only the builtin functions
+// that make sense for them accept quantities, the rounding ones round in the unit of the argument
+func (c *Calculator) callQuantity(name string, f callable, args []Value) (Value, error) {
+	if !f.pure {
+		return Value{}, errors.Wrapf(ErrDimension, "%s needs numbers without units", name)
+	}
+
+	switch name {
+	case "abs", "round", "floor", "ceil":
+		u := args[0].unit()
+		v, err := f.call(ratArithmetic{}, []Value{Rat(args[0].magnitude())})
+		if err != nil {
+			return Value{}, err
+		}
+		return c.measure(new(big.Rat).Mul(v.r, u.scale), u)
+	case "min", "max":
+		result := args[0]
+		for _, v := range args[1:] {
+			if v.unit().key != result.unit().key {
+				return Value{}, errors.Wrapf(ErrDimension, "%s and %s", result.unit().dimensionName(), v.unit().dimensionName())
+			}
+
+			// numbers among the arguments have no dimension, so they are not compared to quantities
+			if d := v.base().Cmp(result.base()); name == "min" && d < 0 || name == "max" && d > 0 {
+				result = v
+			}
+		}
+		return result, nil
+	case "sqrt":
+		return c.binaryQuantity(pow, args[0], Rat(big.NewRat(1, 2)))
+	default:
+		return Value{}, errors.Wrapf(ErrDimension, "%s needs numbers without units", name)
+	}
+}
+
+// hasQuantity reports whether any of the values has a unit
+func hasQuantity(values []Value) bool {
+	for _, v := range values {
+		if v.mode == modeQuantity {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RegisterUnit makes a unit usable after numbers like in 3 furlong, it replaces a unit with the same name.
+// The definition is the size of the unit like 201.168 m or 1 kg*m/s^2 and it is evaluated exactly,
+// an empty definition makes a base unit of a new dimension that can not be converted to other units.
+// Units must be registered before the calculator is used concurrently.
+func (c *Calculator) RegisterUnit(name, definition string) error {
+	if !isIdentifier(name) {
+		return errors.Wrapf(ErrInvalidUnit, "%q is not a valid name", name)
+	}
+
+	if _, ok := c.constants[name]; ok {
+		return errors.Wrapf(ErrInvalidUnit, "%s is a constant", name)
+	}
+
+	named := &namedUnit{name: name, scale: big.NewRat(1, 1), dimension: []power{{name: name, exp: 1}}}
+	if definition != "" {
+		exact := *c
+		exact.arithmetic = ratArithmetic{}
+		v, err := exact.Evaluate(definition, nil)
+		if err != nil {
+			return errors.Wrapf(err, "definition of %s", name)
+		}
+
+		if v.Type() != NumberType || v.base() == nil || v.base().Sign() <= 0 {
+			return errors.Wrapf(ErrInvalidUnit, "%s = %s is not a positive size", name, v)
+		}
+
+		named.scale, named.dimension = v.base(), v.unit().dimension
+	}
+
+	c.units[name] = newQuantity(named.scale, newUnit([]unitPower{{unit: named, exp: 1}}))
+	return nil
+}
+
+// metricPrefixes are combined with the units that accept them, u stands for micro
+var metricPrefixes = []struct{ prefix, factor string }{
+	{"n", "1e-9"}, {"u", "1e-6"}, {"m", "1e-3"}, {"c", "1e-2"},
+	{"k", "1e3"}, {"M", "1e6"}, {"G", "1e9"}, {"T", "1e12"},
+}
+
+// standardUnitDefinitions are defined in order, so the definitions can use the units above them,
+// prefixes lists the metric prefixes that the unit accepts
+var standardUnitDefinitions = []struct{ name, definition, prefixes string }{
+	// base units of the SI, the kilogram is the base of mass, and the byte is the base of information
+	{"m", "", "numck"},
+	{"kg", "", ""},
+	{"s", "", "num"},
+	{"A", "", "m"},
+	{"K", "", ""},
+	{"mol", "", "m"},
+	{"cd", "", ""},
+	{"B", "", "kMGT"},
+
+	{"g", "1e-3 kg", "um"},
+	{"min", "60 s", ""},
+	{"h", "60 min", ""},
+	{"day", "24 h", ""},
+	{"week", "7 day", ""},
+	{"Hz", "1 s^-1", "kMG"},
+	{"L", "1e-3 m^3", "m"},
+	{"N", "1 kg*m/s^2", "k"},
+	{"J", "1 N*m", "kM"},
+	{"W", "1 J/s", "mkMG"},
+	{"Wh", "3600 J", "kMG"},
+	{"Pa", "1 N/m^2", "k"},
+	{"bar", "1e5 Pa", ""},
+	{"atm", "101325 Pa", ""},
+	{"cal", "4.184 J", "k"},
+	{"V", "1 W/A", "mk"},
+	{"ohm", "1 V/A", "k"},
+
+	{"bit", "0.125 B", "kMG"},
+	{"KiB", "1024 B", ""},
+	{"MiB", "1024 KiB", ""},
+	{"GiB", "1024 MiB", ""},
+	{"TiB", "1024 GiB", ""},
+
+	{"inch", "0.0254 m", ""},
+	{"ft", "12 inch", ""},
+	{"yd", "3 ft", ""},
+	{"mi", "1760 yd", ""},
+	{"mph", "1 mi/h", ""},
+	{"lb", "0.45359237 kg", ""},
+	{"oz", "1 lb / 16", ""},
+
+	{"percent", "0.01", ""},
+}
+
+// standardUnits is the unit table every calculator starts with
+var standardUnits = defineStandardUnits()
+
+func defineStandardUnits() map[string]Value {
+	c := Calculator{arithmetic: ratArithmetic{}, base: 10, units: make(map[string]Value)}
+	for _, d := range standardUnitDefinitions {
+		if err := c.RegisterUnit(d.name, d.definition); err != nil {
+			panic(err)
+		}
+
+		for _, p := range metricPrefixes {
+			if strings.Contains(d.prefixes, p.prefix) {
+				if err := c.RegisterUnit(p.prefix+d.name, p.factor+" "+d.name); err != nil {
+					panic(err)
+				}
+			}
+		}
+	}
+
+	return c.units
+}
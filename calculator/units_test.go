@@ -0,0 +1,420 @@
+package calculator_test
+
+import (
+	"github.com/denismitr/gds/calculator"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"testing"
+)
+
+func TestCalculate_Units(t *testing.T) {
+	tt := []struct {
+		input  string
+		output string
+	}{
+		{input: "3 km + 250 m", output: "3.25 km"},
+		{input: "250 m + 3 km", output: "3250 m"},
+		{input: "10 MB / 2 s", output: "5 MB/s"},
+		{input: "6 m / 2 s", output: "3 m/s"},
+		{input: "-3 m", output: "-3 m"},
+		{input: "2 m^2", output: "2 m^2"},
+		{input: "(2 m)^2", output: "4 m^2"},
+		{input: "(1 + 2) km", output: "3 km"},
+		{input: "1 m / (2 s * kg)", output: "0.5 m/(s*kg)"},
+		{input: "1 / 1 s", output: "1 s^-1"},
+		{input: "2 kg * 3 m / s^2", output: "6 kg*m/s^2"},
+		{input: "3 km / 1 m", output: "3000"},
+		{input: "7 m // 2 m", output: "3"},
+		{input: "7 m % 2 m", output: "1 m"},
+		{input: "5 m > 3 ft", output: "true"},
+		{input: "1 km == 1000 m", output: "true"},
+		{input: "sqrt(9 m^2)", output: "3 m"},
+		{input: "abs(-3 km)", output: "3 km"},
+		{input: "round(2.5 km)", output: "3 km"},
+		{input: "max(1 m, 2 ft)", output: "1 m"},
+		{input: "min(1 m, 2 ft)", output: "2 ft"},
+		{input: "2 pi", output: "6.283185307179586"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.input, func(t *testing.T) {
+			output, err := calculator.Calculate(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.output, output)
+		})
+	}
+}
+
+func TestCalculate_UnitConversion(t *testing.T) {
+	tt := []struct {
+		input  string
+		output string
+	}{
+		{input: "3 km in m", output: "3000 m"},
+		{input: "1 mi to km", output: "1.609344 km"},
+		{input: "60 mph in km/h", output: "96.56064 km/h"},
+		{input: "100 km / 2 h in m/s", output: "13.88888888888889 m/s"},
+		{input: "1 kg * 9.81 m/s^2 in N", output: "9.81 N"},
+		{input: "1 kWh in J", output: "3.6e+06 J"},
+		{input: "1 GiB in MB", output: "1073.741824 MB"},
+		{input: "1 h in s", output: "3600 s"},
+		{input: "1 L in cm^3", output: "1000 cm^3"},
+		{input: "0.5 in percent", output: "50 percent"},
+		{input: "2 in 3", output: "2"},
+		{input: "true ? 1 m : 2 m in cm", output: "100 cm"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.input, func(t *testing.T) {
+			output, err := calculator.Calculate(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.output, output)
+		})
+	}
+}
+
+func TestCalculate_UnitErrors(t *testing.T) {
+	tt := []struct {
+		input string
+		err   error
+		msg   string
+	}{
+		{input: "3 km + 2 s", err: calculator.ErrDimension, msg: "3 km + 2 s at position 5: m and s: incompatible dimensions"},
+		{input: "3 km in s", err: calculator.ErrDimension, msg: "3 km in 1 s at position 5: m and s: incompatible dimensions"},
+		{input: "3 in km", err: calculator.ErrDimension},
+		{input: "1 m < 1", err: calculator.ErrDimension},
+		{input: "2 ^ (1 m)", err: calculator.ErrDimension},
+		{input: "sqrt(2 m)", err: calculator.ErrDimension},
+		{input: "sin(3 m)", err: calculator.ErrDimension},
+		{input: "2 m & 1 m", err: calculator.ErrDimension},
+		{input: "~1 m", err: calculator.ErrDimension},
+		{input: "1 m / (0 s)", err: calculator.ErrDivisionByZero},
+		{input: "1 km ^ 10000000", err: calculator.ErrOverflow, msg: "1 km ^ 1e+07 at position 5: km^1e+07 is not a unit: exponent greater than 100: overflow"},
+		{input: "(1 m ^ 60) ^ 2", err: calculator.ErrOverflow},
+		{input: "1 m ^ 60 * 1 m ^ 60", err: calculator.ErrOverflow},
+		{input: "sqrt(1 m ^ -3)", err: calculator.ErrDimension},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.input, func(t *testing.T) {
+			_, err := calculator.Calculate(tc.input)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, tc.err), "got %v", err)
+			if tc.msg != "" {
+				assert.Equal(t, tc.msg, err.Error())
+			}
+		})
+	}
+}
+
+func TestCalculate_UnitKeywords(t *testing.T) {
+	for _, input := range []string{"1 to", "in in", "2 3"} {
+		t.Run(input, func(t *testing.T) {
+			_, err := calculator.Execute(input, nil)
+			var syntaxErr *calculator.SyntaxError
+			assert.True(t, errors.As(err, &syntaxErr), "got %v", err)
+		})
+	}
+
+	// in and to convert only after a complete operand, elsewhere they are names
+	tt := []struct {
+		input  string
+		output string
+	}{
+		{input: "to = 3; to", output: "3"},
+		{input: "in = 2; to = in * 3; in + to", output: "8"},
+		{input: "to = 1; (to) km to m", output: "1000 m"},
+		{input: "in = 5; (in) in in", output: "5"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.input, func(t *testing.T) {
+			v, err := calculator.Execute(tc.input, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tc.output, v.String())
+		})
+	}
+
+	c, err := calculator.New()
+	require.NoError(t, err)
+	assert.True(t, errors.Is(c.RegisterFunction("in", calculator.Function{MaxArgs: 1, Call: nil}), calculator.ErrInvalidFunction))
+	assert.True(t, errors.Is(c.RegisterUnit("to", ""), calculator.ErrInvalidUnit))
+}
+
+func TestCalculator_UnitModes(t *testing.T) {
+	tt := []struct {
+		mode   calculator.Mode
+		input  string
+		output string
+	}{
+		{mode: calculator.ModeRational, input: "3 km + 250 m", output: "(13/4) km"},
+		{mode: calculator.ModeRational, input: "1 mi in km", output: "(25146/15625) km"},
+		{mode: calculator.ModeRational, input: "0.1 m + 0.2 m", output: "(3/10) m"},
+		{mode: calculator.ModeBigFloat, input: "1 ft in m", output: "0.3048 m"},
+		{mode: calculator.ModeInt32, input: "250 m + 3 km", output: "3250 m"},
+		{mode: calculator.ModeInt32, input: "10000 kB / 4 s in B/s", output: "2500000 B/s"},
+		{mode: calculator.ModeInt32, input: "8 m / 2 m", output: "4"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.mode.String()+" "+tc.input, func(t *testing.T) {
+			c, err := calculator.New(calculator.WithMode(tc.mode))
+			require.NoError(t, err)
+
+			output, err := c.Calculate(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.output, output)
+		})
+	}
+}
+
+func TestCalculator_UnitIntegerModes(t *testing.T) {
+	c, err := calculator.New(calculator.WithMode(calculator.ModeInt64))
+	require.NoError(t, err)
+
+	// fractions of units are not truncated, so 3 km + 250 m is not 3 km
+	for _, input := range []string{"3 km + 250 m", "1 mi in km", "10 MB / 4 s", "3250 m to km", "-7 m / 2", "7 m / 2 m"} {
+		t.Run(input, func(t *testing.T) {
+			_, err := c.Calculate(input)
+			assert.True(t, errors.Is(err, calculator.ErrDomain), "got %v", err)
+		})
+	}
+
+	_, err = c.Calculate("3 km + 250 m")
+	assert.Equal(t, "3 km + 250 m at position 5: 3.25 km cannot be used in int64 mode: argument out of domain", err.Error())
+
+	_, err = c.Evaluate("d", map[string]calculator.Value{"d": mustEvaluate(t, "3.25 km")})
+	assert.True(t, errors.Is(err, calculator.ErrDomain), "got %v", err)
+}
+
+func TestEvaluate_Quantities(t *testing.T) {
+	v, err := calculator.Evaluate("d / 30 min", map[string]calculator.Value{"d": mustEvaluate(t, "5 km")})
+	require.NoError(t, err)
+	assert.Equal(t, "km/min", v.Unit())
+	assert.Equal(t, "(1/6) km/min", v.String())
+	assert.InDelta(t, 1.0/6, v.Float64(), 1e-15)
+
+	// the printed form is parsed back to the same quantity by an exact calculator
+	rational, err := calculator.New(calculator.WithMode(calculator.ModeRational))
+	require.NoError(t, err)
+	back, err := rational.Evaluate(v.String(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, v.String(), back.String())
+
+	// variables do not shadow units in their position
+	env := map[string]calculator.Value{"m": calculator.Float(5)}
+	v, err = calculator.Evaluate("3 km in m", env)
+	require.NoError(t, err)
+	assert.Equal(t, "3000 m", v.String())
+	v, err = calculator.Evaluate("m * 2 m", env)
+	require.NoError(t, err)
+	assert.Equal(t, "10 m", v.String())
+
+	v, err = calculator.Execute("h = 2; 1 h in s", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "3600 s", v.String())
+
+	e, err := calculator.Parse("x * 1 km + 2 m / s + y")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"x", "y"}, e.Variables())
+
+	e, err = calculator.Parse("x * km")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"km", "x"}, e.Variables())
+
+	// the units are neither substituted nor differentiated like the variables of the same name
+	e, err = calculator.Parse("m * 2 m")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"m"}, e.Variables())
+	five, err := calculator.Parse("5")
+	require.NoError(t, err)
+	s, err := e.Substitute(map[string]*calculator.Expr{"m": five})
+	require.NoError(t, err)
+	assert.Equal(t, "5 * 2 m", s.String())
+	d, err := e.Derivative("m")
+	require.NoError(t, err)
+	assert.Equal(t, "2 m", d.String())
+}
+
+func TestEvaluate_UnitNamesAsVariables(t *testing.T) {
+	// units are only looked up after a number or a bracket, after in and to,
+	// and after * and / that follow a unit
+	env := map[string]calculator.Value{"price": calculator.Float(3)}
+	for _, input := range []string{"m * 2", "price * h", "1 / s", "2 * km", "3 m + s", "km in m"} {
+		t.Run(input, func(t *testing.T) {
+			_, err := calculator.Evaluate(input, env)
+			assert.True(t, errors.Is(err, calculator.ErrUndefinedVariable), "got %v", err)
+		})
+	}
+
+	v, err := calculator.Evaluate("price * h", map[string]calculator.Value{"price": calculator.Float(3), "h": calculator.Float(2)})
+	require.NoError(t, err)
+	assert.Equal(t, "6", v.String())
+
+	// names in the position of a unit that are not units can still be variables
+	v, err = calculator.Evaluate("2 x", map[string]calculator.Value{"x": calculator.Float(3)})
+	require.NoError(t, err)
+	assert.Equal(t, "6", v.String())
+}
+
+func TestEvaluate_UnknownUnits(t *testing.T) {
+	tt := []struct {
+		input string
+		msg   string
+	}{
+		{input: "2 x", msg: "unknown unit x at position 2"},
+		{input: "5 kgs", msg: "unknown unit kgs at position 2, did you mean kg?"},
+		{input: "3 ft in inchs", msg: "unknown unit inchs at position 8, did you mean inch?"},
+		{input: "1 m / hr", msg: "unknown unit hr at position 6, did you mean h?"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.input, func(t *testing.T) {
+			_, err := calculator.Evaluate(tc.input, nil)
+			assert.True(t, errors.Is(err, calculator.ErrInvalidUnit), "got %v", err)
+
+			var unknown *calculator.UnknownUnitError
+			require.True(t, errors.As(err, &unknown), "got %v", err)
+			assert.Equal(t, tc.msg, unknown.Error())
+		})
+	}
+}
+
+func TestFormatInfix_Units(t *testing.T) {
+	tt := []struct {
+		input  string
+		output string
+	}{
+		{input: "3 km+250 m", output: "3 km + 250 m"},
+		{input: "-3 km", output: "-3 km"},
+		{input: "(1 + 2) km", output: "(1 + 2) km"},
+		{input: "(x) km", output: "(x) km"},
+		{input: "2 m^2", output: "2 m ^ 2"},
+		{input: "2 kg*m/s^2", output: "2 kg * m / s ^ 2"},
+		{input: "1 m / (2 s * kg)", output: "1 m / (2 s * kg)"},
+		{input: "60 mph in km/h", output: "60 mph in km / h"},
+		{input: "3 km in m * 2", output: "3 km in m * 2"},
+		{input: "-(2 km) * x", output: "-2 km * x"},
+		{input: "-(2 km) * h", output: "-2 km * h"},
+		{input: "2 x", output: "2 * x"},
+		{input: "(2 km) * x * h", output: "2 km * x * h"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.input, func(t *testing.T) {
+			output, err := calculator.FormatInfix(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.output, output)
+
+			// the output is parsed back to the same expression
+			again, err := calculator.FormatInfix(output)
+			require.NoError(t, err)
+			assert.Equal(t, output, again)
+		})
+	}
+
+	p, err := calculator.Compile("(1 + 2) km in m")
+	require.NoError(t, err)
+	assert.Equal(t, "3 km in m", p.String())
+
+	e, err := calculator.Parse("x * 1 km")
+	require.NoError(t, err)
+	d, err := e.Derivative("x")
+	require.NoError(t, err)
+	assert.Equal(t, "1 km", d.String())
+}
+
+func TestCalculator_RegisterUnit(t *testing.T) {
+	c, err := calculator.New()
+	require.NoError(t, err)
+
+	require.NoError(t, c.RegisterUnit("furlong", "201.168 m"))
+	require.NoError(t, c.RegisterUnit("fortnight", "14 day"))
+	require.NoError(t, c.RegisterUnit("dozen", "12"))
+	require.NoError(t, c.RegisterUnit("px", ""))
+	require.NoError(t, c.RegisterUnit("dpi", "1 px/inch"))
+
+	tt := []struct {
+		input  string
+		output string
+	}{
+		{input: "1 furlong / fortnight in mm/h", output: "598.7142857142857 mm/h"},
+		{input: "2 dozen", output: "24"},
+		{input: "24 in dozen", output: "2 dozen"},
+		{input: "300 dpi * 2 inch in px", output: "600 px"},
+		{input: "300 dpi in px/cm", output: "118.11023622047244 px/cm"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.input, func(t *testing.T) {
+			output, err := c.Calculate(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.output, output)
+		})
+	}
+
+	// the units of the calculator are rendered right after the number
+	e, err := c.Parse("-(2 furlong) * fortnight * x")
+	require.NoError(t, err)
+	assert.Equal(t, "-2 furlong * fortnight * x", e.String())
+	p, err := c.Compile("x * (2 furlong) h")
+	require.NoError(t, err)
+	assert.Equal(t, "x * (2 furlong * h)", p.String())
+
+	_, err = c.Calculate("1 px in m")
+	assert.True(t, errors.Is(err, calculator.ErrDimension), "got %v", err)
+
+	// the default calculator does not know the units of another one
+	_, err = calculator.Calculate("1 furlong")
+	assert.True(t, errors.Is(err, calculator.ErrInvalidUnit), "got %v", err)
+
+	assert.True(t, errors.Is(c.RegisterUnit("1x", "1 m"), calculator.ErrInvalidUnit))
+	assert.True(t, errors.Is(c.RegisterUnit("nothing", "0 m"), calculator.ErrInvalidUnit))
+	assert.True(t, errors.Is(c.RegisterUnit("yes", "true"), calculator.ErrInvalidUnit))
+	assert.True(t, errors.Is(c.RegisterUnit("pi", "3 m"), calculator.ErrInvalidUnit))
+	assert.True(t, errors.Is(c.RegisterUnit("true", ""), calculator.ErrInvalidUnit))
+
+	var syntaxErr *calculator.SyntaxError
+	assert.True(t, errors.As(c.RegisterUnit("broken", "1 +"), &syntaxErr))
+}
+
+func TestCalculator_ManyUnits(t *testing.T) {
+	c, err := calculator.New()
+	require.NoError(t, err)
+
+	// units are kept by the quantities, there is no table of them to fill up
+	for i := 0; i < 1<<17; i++ {
+		require.NoError(t, c.RegisterUnit("unit"+strconv.Itoa(i), ""))
+	}
+
+	output, err := calculator.Calculate("10 MB / 2 s")
+	require.NoError(t, err)
+	assert.Equal(t, "5 MB/s", output)
+}
+
+func TestRPN_Units(t *testing.T) {
+	tokens, err := calculator.ToRPN("-3 km + 2 m^2 in m")
+	require.NoError(t, err)
+	assert.Equal(t, "3 km * neg 2 m 2 ^ * + m in", tokens.String())
+	assert.Equal(t, calculator.Token{Kind: calculator.UnitToken, Text: "km", Pos: 3}, tokens[1])
+
+	tokens, err = calculator.ToRPN("-3 km + 2 m in m")
+	require.NoError(t, err)
+	v, err := calculator.EvalRPN(tokens, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "-2998 m", v.String())
+
+	v, err = calculator.EvalRPNString("3 km * m in", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "3000 m", v.String())
+}
+
+func mustEvaluate(t *testing.T, expr string) calculator.Value {
+	t.Helper()
+
+	v, err := calculator.Evaluate(expr, nil)
+	require.NoError(t, err)
+	return v
+}
@@ -0,0 +1,296 @@
+package calculator
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// Mode selects the representation of numbers used for evaluation
+type Mode int8
+
+const (
+	// ModeFloat evaluates with float64, it is the fastest mode and the default one
+	ModeFloat Mode = iota
+	// ModeRational evaluates with exact fractions (big.Rat)
+	ModeRational
+	// ModeBigFloat evaluates with arbitrary precision floating point numbers (big.Float)
+	ModeBigFloat
+	// ModeInt8 to ModeUint64 evaluate with fixed-width integers, results that do not fit fail with ErrOverflow,
+	// division truncates toward zero like in C and non-integers fail with ErrDomain
+	ModeInt8
+	ModeInt16
+	ModeInt32
+	ModeInt64
+	ModeUint8
+	ModeUint16
+	ModeUint32
+	ModeUint64
+)
+
+// modeBool and modeQuantity mark booleans and numbers with units, a separate type field would make Value
+// too big to be passed to the operators in registers
+const (
+	modeBool     Mode = -1
+	modeQuantity Mode = -2
+)
+
+func (m Mode) String() string {
+	switch m {
+	case ModeFloat:
+		return "float"
+	case ModeRational:
+		return "rational"
+	case ModeBigFloat:
+		return "bigfloat"
+	case ModeInt8, ModeInt16, ModeInt32, ModeInt64:
+		return "int" + strconv.Itoa(int(m.bits()))
+	case ModeUint8, ModeUint16, ModeUint32, ModeUint64:
+		return "uint" + strconv.Itoa(int(m.bits()))
+	case modeBool:
+		return "bool"
+	case modeQuantity:
+		return "quantity"
+	default:
+		return "unknown"
+	}
+}
+
+func (m Mode) integer() bool {
+	return m >= ModeInt8 && m <= ModeUint64
+}
+
+func (m Mode) signed() bool {
+	return m >= ModeInt8 && m <= ModeInt64
+}
+
+// bits is the width of an integer mode
+func (m Mode) bits() uint {
+	switch m {
+	case ModeInt8, ModeUint8:
+		return 8
+	case ModeInt16, ModeUint16:
+		return 16
+	case ModeInt32, ModeUint32:
+		return 32
+	default:
+		return 64
+	}
+}
+
+// Type tells numbers from booleans, numbers are further divided by Mode
+type Type int8
+
+const (
+	NumberType Type = iota
+	BoolType
+)
+
+func (t Type) String() string {
+	switch t {
+	case NumberType:
+		return "number"
+	case BoolType:
+		return "bool"
+	default:
+		return "unknown"
+	}
+}
+
+// Value is a number or a boolean produced or consumed by the calculator,
+// only the field that matches its mode is set, so float values never allocate,
+// booleans are stored in f as 1 and 0 and integers as the bits of an int64 or a uint64.
+// A quantity like 3 km keeps its exact magnitude in base units in r and its unit in x.
+type Value struct {
+	mode Mode
+	f    float64
+	r    *big.Rat
+	x    *extra
+}
+
+// extra is the number of ModeBigFloat or the unit of a quantity, they share a field
+// since a fifth one would make Value too big for the compiler to keep it in registers
+type extra struct {
+	bf   *big.Float
+	unit *unit
+}
+
+func Float(f float64) Value {
+	return Value{mode: ModeFloat, f: f}
+}
+
+// Int makes a ModeInt64 value
+func Int(i int64) Value {
+	return intValue(ModeInt64, i)
+}
+
+// Uint makes a ModeUint64 value
+func Uint(u uint64) Value {
+	return intValue(ModeUint64, int64(u))
+}
+
+// intValue makes a value of an integer mode, unsigned values are passed as their bits
+func intValue(m Mode, i int64) Value {
+	return Value{mode: m, f: math.Float64frombits(uint64(i))}
+}
+
+// Bool makes a boolean value, comparisons and logical operators produce them
+func Bool(b bool) Value {
+	v := Value{mode: modeBool}
+	if b {
+		v.f = 1
+	}
+
+	return v
+}
+
+// Rat makes a rational value, r must not be modified afterwards
+func Rat(r *big.Rat) Value {
+	return Value{mode: ModeRational, r: r}
+}
+
+// BigFloat makes an arbitrary precision value, f must not be modified afterwards
+func BigFloat(f *big.Float) Value {
+	return Value{mode: ModeBigFloat, x: &extra{bf: f}}
+}
+
+func (v Value) Type() Type {
+	if v.mode == modeBool {
+		return BoolType
+	}
+
+	return NumberType
+}
+
+// Mode is the numeric mode of a number, it is meaningless for booleans and quantities
+func (v Value) Mode() Mode {
+	return v.mode
+}
+
+// Bool returns the boolean value, it is false for numbers
+func (v Value) Bool() bool {
+	return v.mode == modeBool && v.f != 0
+}
+
+// int returns a signed integer, it is meaningful only for the signed integer modes
+func (v Value) int() int64 {
+	return int64(math.Float64bits(v.f))
+}
+
+// uint returns an unsigned integer, it is meaningful only for the unsigned integer modes
+func (v Value) uint() uint64 {
+	return math.Float64bits(v.f)
+}
+
+// bigFloat returns the arbitrary precision number, it is meaningful only for ModeBigFloat
+func (v Value) bigFloat() *big.Float {
+	return v.x.bf
+}
+
+// Float64 returns the nearest float64 to the value, true is 1 and false is 0,
+// a quantity is measured in its own unit, so 3 km is 3
+func (v Value) Float64() float64 {
+	switch {
+	case v.mode == modeQuantity:
+		f, _ := v.magnitude().Float64()
+		return f
+	case v.mode.signed():
+		return float64(v.int())
+	case v.mode.integer():
+		return float64(v.uint())
+	}
+
+	switch v.mode {
+	case ModeRational:
+		f, _ := v.r.Float64()
+		return f
+	case ModeBigFloat:
+		f, _ := v.bigFloat().Float64()
+		return f
+	default:
+		return v.f
+	}
+}
+
+// Rat returns the value as an exact fraction, it is nil for infinities and NaN
+func (v Value) Rat() *big.Rat {
+	if v.mode.integer() {
+		return new(big.Rat).SetInt(v.bigInt())
+	}
+
+	if v.mode == modeQuantity {
+		return v.magnitude()
+	}
+
+	switch v.mode {
+	case ModeRational:
+		return new(big.Rat).Set(v.r)
+	case ModeBigFloat:
+		if v.bigFloat().IsInf() {
+			return nil
+		}
+		r, _ := v.bigFloat().Rat(nil)
+		return r
+	default:
+		return new(big.Rat).SetFloat64(v.f)
+	}
+}
+
+// BigFloat returns the value as a big.Float with the given precision in bits, it is nil for NaN
+func (v Value) BigFloat(precision uint) *big.Float {
+	if v.mode.integer() {
+		return new(big.Float).SetPrec(precision).SetInt(v.bigInt())
+	}
+
+	if v.mode == modeQuantity {
+		return new(big.Float).SetPrec(precision).SetRat(v.magnitude())
+	}
+
+	switch v.mode {
+	case ModeRational:
+		return new(big.Float).SetPrec(precision).SetRat(v.r)
+	case ModeBigFloat:
+		return new(big.Float).SetPrec(precision).Set(v.bigFloat())
+	default:
+		if math.IsNaN(v.f) {
+			return nil
+		}
+		return new(big.Float).SetPrec(precision).SetFloat64(v.f)
+	}
+}
+
+// String formats the value the way a calculator with default options does
+func (v Value) String() string {
+	if v.mode == modeBool {
+		return strconv.FormatBool(v.Bool())
+	}
+
+	if v.mode == modeQuantity {
+		return formatMagnitude(v.magnitude()) + " " + v.Unit()
+	}
+
+	switch {
+	case v.mode.signed():
+		return strconv.FormatInt(v.int(), 10)
+	case v.mode.integer():
+		return strconv.FormatUint(v.uint(), 10)
+	}
+
+	switch v.mode {
+	case ModeRational:
+		return v.r.RatString()
+	case ModeBigFloat:
+		return v.bigFloat().Text('g', -1)
+	default:
+		return strconv.FormatFloat(v.f, 'g', -1, 64)
+	}
+}
+
+// bigInt returns the value of an integer mode as a big.Int
+func (v Value) bigInt() *big.Int {
+	if v.mode.signed() {
+		return big.NewInt(v.int())
+	}
+
+	return new(big.Int).SetUint64(v.uint())
+}
@@ -0,0 +1,370 @@
+// Command gdscalc evaluates calculator expressions given as arguments, read from the standard input
+// line by line or typed in an interactive session.
+//
+// Usage:
+//
+//	gdscalc [flags] [expression ...]
+//
+// Expressions given as arguments are evaluated in order and share variables. Without arguments
+// the lines of the standard input are evaluated, an interactive session is started when the input
+// is a terminal or -i is set. The exit code is 1 when any expression fails and 2 on invalid flags.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"github.com/denismitr/gds/calculator"
+	"github.com/pkg/errors"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+const (
+	prompt             = "> "
+	continuationPrompt = "... "
+)
+
+const help = `Enter expressions or assignments like x = 2 * pi, variables are kept until the session ends.
+A line that ends with an operator or has unclosed brackets continues on the next line.
+Numbers can have units like 3 km + 250 m, in and to convert them like 60 mph in km/h.
+
+Commands:
+  :rpn <expression>           show the expression in reverse polish notation
+  :mode [name [precision]]    show or switch the numeric mode: float, rational, bigfloat,
+                              int8, int16, int32, int64, uint8, uint16, uint32 or uint64
+  :vars                       list the variables
+  :history                    list the entered lines
+  :help                       show this help
+  :quit                       end the session
+`
+
+var errUnknownCommand = errors.New("unknown command")
+
+var modes = []calculator.Mode{
+	calculator.ModeFloat, calculator.ModeRational, calculator.ModeBigFloat,
+	calculator.ModeInt8, calculator.ModeInt16, calculator.ModeInt32, calculator.ModeInt64,
+	calculator.ModeUint8, calculator.ModeUint16, calculator.ModeUint32, calculator.ModeUint64,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("gdscalc", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	mode := flags.String("mode", calculator.ModeFloat.String(), "numeric mode: float, rational, bigfloat, int8 to int64 or uint8 to uint64")
+	precision := flags.Uint("precision", calculator.DefaultPrecision, "mantissa bits of the bigfloat mode")
+	digits := flags.Int("digits", -1, "digits after the decimal point, by default the shortest exact form is printed")
+	base := flags.Int("base", 10, "base of integer results: 2, 8, 10 or 16")
+	interactive := flags.Bool("i", false, "start an interactive session even when the input is not a terminal")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: gdscalc [flags] [expression ...]")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	s := session{env: make(map[string]calculator.Value), digits: *digits, base: *base, stdout: stdout, stderr: stderr}
+	if err := s.setMode(*mode, *precision); err != nil {
+		fmt.Fprintf(stderr, "gdscalc: %v\n", err)
+		return exitUsage
+	}
+
+	switch {
+	case flags.NArg() > 0:
+		return s.evalArgs(flags.Args())
+	case *interactive || isTerminal(stdin):
+		return s.repl(stdin)
+	default:
+		return s.evalLines(stdin)
+	}
+}
+
+// session keeps the variables and the history between the evaluated inputs
+type session struct {
+	calculator *calculator.Calculator
+	env        map[string]calculator.Value
+	history    []string
+	digits     int
+	base       int
+	precision  uint
+	stdout     io.Writer
+	stderr     io.Writer
+}
+
+func (s *session) evalArgs(args []string) int {
+	code := exitOK
+	for _, arg := range args {
+		if err := s.execute(arg); err != nil {
+			fmt.Fprintf(s.stderr, "gdscalc: %v\n", err)
+			code = exitError
+		}
+	}
+
+	return code
+}
+
+// evalLines evaluates the input line by line, the lines of an incomplete statement are joined,
+// evaluation goes on after an error
+func (s *session) evalLines(r io.Reader) int {
+	code := exitOK
+	err := s.readStatements(r, nil, func(input string, line int) bool {
+		if err := s.execute(input); err != nil {
+			fmt.Fprintf(s.stderr, "gdscalc: line %d: %v\n", line, err)
+			code = exitError
+		}
+		return true
+	})
+
+	if err != nil {
+		fmt.Fprintf(s.stderr, "gdscalc: %v\n", err)
+		return exitError
+	}
+
+	return code
+}
+
+func (s *session) repl(r io.Reader) int {
+	fmt.Fprintf(s.stdout, "gdscalc in %s mode, :help lists the commands\n", s.calculator.Mode())
+
+	quit := false
+	err := s.readStatements(r, func(continued bool) {
+		if continued {
+			fmt.Fprint(s.stdout, continuationPrompt)
+		} else {
+			fmt.Fprint(s.stdout, prompt)
+		}
+	}, func(input string, _ int) bool {
+		s.history = append(s.history, input)
+
+		if strings.HasPrefix(input, ":") {
+			var err error
+			if quit, err = s.command(input); err != nil {
+				fmt.Fprintf(s.stderr, "error: %v\n", err)
+			}
+			return !quit
+		}
+
+		if err := s.execute(input); err != nil {
+			fmt.Fprintf(s.stderr, "error: %v\n", err)
+		}
+		return true
+	})
+
+	if err != nil {
+		fmt.Fprintf(s.stderr, "gdscalc: %v\n", err)
+		return exitError
+	}
+
+	// the input ended at the prompt
+	if !quit {
+		fmt.Fprintln(s.stdout)
+	}
+
+	return exitOK
+}
+
+// readStatements calls eval with every complete statement and the number of the line it starts on,
+// blank lines are skipped and reading stops when eval returns false
+func (s *session) readStatements(r io.Reader, prompt func(continued bool), eval func(input string, line int) bool) error {
+	scanner := bufio.NewScanner(r)
+	var pending []string
+	start := 0
+
+	for line := 1; ; line++ {
+		if prompt != nil {
+			prompt(len(pending) > 0)
+		}
+
+		if !scanner.Scan() {
+			break
+		}
+
+		text := scanner.Text()
+		if len(pending) == 0 {
+			if strings.TrimSpace(text) == "" {
+				continue
+			}
+			start = line
+		}
+
+		pending = append(pending, text)
+		input := strings.Join(pending, "\n")
+		// commands are never continued
+		if !strings.HasPrefix(strings.TrimSpace(input), ":") && !calculator.Complete(input) {
+			continue
+		}
+
+		pending = nil
+		if !eval(strings.TrimSpace(input), start) {
+			return nil
+		}
+	}
+
+	if len(pending) > 0 {
+		eval(strings.Join(pending, "\n"), start)
+	}
+
+	return scanner.Err()
+}
+
+func (s *session) execute(input string) error {
+	v, err := s.calculator.Execute(input, s.env)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(s.stdout, s.calculator.Format(v))
+	return nil
+}
+
+// command runs a REPL command and reports whether the session is over
+func (s *session) command(input string) (bool, error) {
+	name, arg := input, ""
+	if i := strings.IndexAny(input, " \t"); i >= 0 {
+		name, arg = input[:i], strings.TrimSpace(input[i+1:])
+	}
+
+	switch name {
+	case ":quit", ":q", ":exit":
+		return true, nil
+	case ":help":
+		fmt.Fprint(s.stdout, help)
+	case ":rpn":
+		tokens, err := calculator.ToRPN(arg)
+		if err != nil {
+			return false, err
+		}
+		fmt.Fprintln(s.stdout, tokens)
+	case ":mode":
+		return false, s.mode(arg)
+	case ":vars":
+		names := make([]string, 0, len(s.env))
+		for name := range s.env {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			fmt.Fprintf(s.stdout, "%s = %s\n", name, s.calculator.Format(s.env[name]))
+		}
+	case ":history":
+		for i, input := range s.history {
+			fmt.Fprintf(s.stdout, "%4d  %s\n", i+1, strings.ReplaceAll(input, "\n", "\n      "))
+		}
+	default:
+		return false, errors.Wrapf(errUnknownCommand, "%s, :help lists the commands", name)
+	}
+
+	return false, nil
+}
+
+// mode shows the current mode or switches to the one named in arg, the variables that fit in the new mode are kept
+func (s *session) mode(arg string) error {
+	fields := strings.Fields(arg)
+	if len(fields) == 0 {
+		if s.calculator.Mode() == calculator.ModeBigFloat {
+			fmt.Fprintf(s.stdout, "%s %d\n", s.calculator.Mode(), s.precision)
+		} else {
+			fmt.Fprintln(s.stdout, s.calculator.Mode())
+		}
+		return nil
+	}
+
+	precision := s.precision
+	if len(fields) > 1 {
+		bits, err := strconv.ParseUint(fields[1], 10, 32)
+		if err != nil {
+			return errors.Wrapf(calculator.ErrInvalidPrecision, "%q", fields[1])
+		}
+		precision = uint(bits)
+	}
+
+	if err := s.setMode(fields[0], precision); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(s.stdout, s.calculator.Mode())
+	return nil
+}
+
+func (s *session) setMode(name string, precision uint) error {
+	mode, err := parseMode(name)
+	if err != nil {
+		return err
+	}
+
+	c, err := calculator.New(calculator.WithMode(mode), calculator.WithPrecision(precision), calculator.WithDigits(s.digits), calculator.WithBase(s.base))
+	if err != nil {
+		return err
+	}
+
+	// variables are carried over through their printed form, so that 0.2 becomes 1/5
+	// in rational mode rather than the binary fraction nearest to it,
+	// the ones that can not be written in the new mode are dropped with a warning
+	names := make([]string, 0, len(s.env))
+	for name := range s.env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		v := s.env[name]
+		if v.Type() != calculator.NumberType {
+			continue
+		}
+
+		converted, err := c.Evaluate(v.String(), nil)
+		if err == nil && v.Unit() != "" {
+			// the printed magnitude like (13/4) km is divided in the integer modes like 13 / 4 is,
+			// the lookup checks that the quantity is a whole number of its unit instead
+			_, err = c.Evaluate(name, map[string]calculator.Value{name: v})
+		}
+		if err != nil {
+			delete(s.env, name)
+			fmt.Fprintf(s.stderr, "warning: %s = %s is dropped, it can not be carried over to %s mode: %v\n", name, v, mode, err)
+			continue
+		}
+
+		s.env[name] = converted
+	}
+
+	s.calculator, s.precision = c, precision
+	return nil
+}
+
+func parseMode(name string) (calculator.Mode, error) {
+	for _, mode := range modes {
+		if mode.String() == name {
+			return mode, nil
+		}
+	}
+
+	return 0, errors.Wrapf(calculator.ErrInvalidMode, "%q, expected float, rational, bigfloat, int8 to int64 or uint8 to uint64", name)
+}
+
+func isTerminal(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
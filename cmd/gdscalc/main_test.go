@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func runWith(args []string, stdin string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun_Arguments(t *testing.T) {
+	tt := []struct {
+		name   string
+		args   []string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{name: "single", args: []string{"2 * (3 + 4)"}, code: exitOK, stdout: "14\n"},
+		{name: "shared variables", args: []string{"x = 3", "y = x ^ 2", "x + y"}, code: exitOK, stdout: "3\n9\n12\n"},
+		{name: "statements", args: []string{"a = 2; b = 5; a < b ? b : a"}, code: exitOK, stdout: "5\n"},
+		{name: "rational", args: []string{"-mode", "rational", "1/3 + 1/6"}, code: exitOK, stdout: "1/2\n"},
+		{name: "digits", args: []string{"-digits", "3", "2 / 3"}, code: exitOK, stdout: "0.667\n"},
+		{name: "integer mode", args: []string{"-mode", "uint8", "-base", "16", "0xF0 | 0b1010", "~1"}, code: exitOK, stdout: "0xfa\n0xfe\n"},
+		{name: "units", args: []string{"d = 3 km + 250 m", "d / 30 min in km/h"}, code: exitOK, stdout: "3.25 km\n6.5 km/h\n"},
+		{name: "overflow", args: []string{"-mode", "int8", "100 + 28"}, code: exitError, stderr: "gdscalc: 100 + 28 at position 4: result does not fit in int8: overflow\n"},
+		{
+			name:   "error does not stop evaluation",
+			args:   []string{"1 / 0", "2 + 2"},
+			code:   exitError,
+			stdout: "4\n",
+			stderr: "gdscalc: 1 / 0 at position 2: division by zero\n",
+		},
+		{name: "invalid mode", args: []string{"-mode", "decimal", "1"}, code: exitUsage, stderr: "gdscalc: \"decimal\", expected float, rational, bigfloat, int8 to int64 or uint8 to uint64: invalid mode\n"},
+		{name: "invalid base", args: []string{"-base", "3", "1"}, code: exitUsage, stderr: "gdscalc: 3: base must be 2, 8, 10 or 16\n"},
+		{name: "invalid precision", args: []string{"-mode", "bigfloat", "-precision", "0", "1"}, code: exitUsage, stderr: "gdscalc: precision must be greater than 0\n"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			code, stdout, stderr := runWith(tc.args, "")
+			assert.Equal(t, tc.code, code)
+			assert.Equal(t, tc.stdout, stdout)
+			assert.Equal(t, tc.stderr, stderr)
+		})
+	}
+}
+
+func TestRun_Flags(t *testing.T) {
+	code, _, stderr := runWith([]string{"-unknown"}, "")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "usage: gdscalc")
+
+	code, _, stderr = runWith([]string{"-h"}, "")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stderr, "-mode")
+}
+
+func TestRun_Lines(t *testing.T) {
+	input := "x = 3\n\n  y = x *\n    (4 + 1)\nz / 2\nmax(x,\n y)\n"
+
+	code, stdout, stderr := runWith(nil, input)
+	assert.Equal(t, exitError, code)
+	assert.Equal(t, "3\n15\n15\n", stdout)
+	assert.Equal(t, "gdscalc: line 5: undefined variable z at position 0\n", stderr)
+
+	code, stdout, stderr = runWith(nil, "1 +\n")
+	assert.Equal(t, exitError, code)
+	assert.Empty(t, stdout)
+	assert.Equal(t, "gdscalc: line 1: syntax error at position 2: missing operand for +\n", stderr)
+
+	code, stdout, stderr = runWith([]string{"-mode", "bigfloat", "-precision", "64"}, "2 ^ 0.5 > 1.414\n")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "true\n", stdout)
+	assert.Empty(t, stderr)
+}
+
+func TestRun_REPL(t *testing.T) {
+	input := strings.Join([]string{
+		"rate = 0.2",
+		"amount = 150",
+		"amount > 100 ?",
+		"  amount * rate :",
+		"  0",
+		":rpn amount > 100 ? amount * rate : 0",
+		":mode rational",
+		"1/3 + rate",
+		":mode",
+		":vars",
+		"oops(1)",
+		":history",
+		":what",
+		":quit",
+		"1 + 1",
+	}, "\n")
+
+	code, stdout, stderr := runWith([]string{"-i"}, input)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, strings.Join([]string{
+		"gdscalc in float mode, :help lists the commands",
+		"> 0.2",
+		"> 150",
+		"> ... ... 30",
+		"> amount 100 > amount rate * 0 ?:",
+		"> rational",
+		"> 8/15",
+		"> rational",
+		"> amount = 150",
+		"rate = 1/5",
+		"> > " +
+			"   1  rate = 0.2",
+		"   2  amount = 150",
+		"   3  amount > 100 ?",
+		"        amount * rate :",
+		"        0",
+		"   4  :rpn amount > 100 ? amount * rate : 0",
+		"   5  :mode rational",
+		"   6  1/3 + rate",
+		"   7  :mode",
+		"   8  :vars",
+		"   9  oops(1)",
+		"  10  :history",
+		"> > ",
+	}, "\n"), stdout)
+	assert.Equal(t, "error: syntax error at position 0: unknown function oops\n"+
+		"error: :what, :help lists the commands: unknown command\n", stderr)
+}
+
+func TestRun_REPLModes(t *testing.T) {
+	code, stdout, stderr := runWith([]string{"-i"}, ":mode bigfloat 32\n:mode\n:mode decimal\n:mode bigfloat x\n:help\n")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "> bigfloat\n> bigfloat 32\n> > > ")
+	assert.Contains(t, stdout, ":rpn <expression>")
+	assert.True(t, strings.HasSuffix(stdout, "> \n"), "a new line ends the session at the end of the input")
+	assert.Equal(t, "error: \"decimal\", expected float, rational, bigfloat, int8 to int64 or uint8 to uint64: invalid mode\n"+
+		"error: \"x\": precision must be greater than 0\n", stderr)
+}
+
+func TestRun_REPLModeDropsVariables(t *testing.T) {
+	code, stdout, stderr := runWith([]string{"-i"}, "x = 1000\ny = 100\nok = x > y\n:mode int8\n:vars\n")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "> int8\n> ok = true\ny = 100\n")
+	assert.Equal(t, "warning: x = 1000 is dropped, it can not be carried over to int8 mode: 1000 at position 0: number does not fit in int8: overflow\n", stderr)
+
+	code, _, stderr = runWith([]string{"-i"}, "x = 2.5\n:mode int8\n")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "warning: x = 2.5 is dropped, it can not be carried over to int8 mode: 2.5 at position 0: number is not an integer: argument out of domain\n", stderr)
+}
+
+func TestRun_REPLModeQuantities(t *testing.T) {
+	code, stdout, stderr := runWith([]string{"-i"}, "a = 3.25 km\nb = 3 km\n:mode rational\n:vars\n:mode int64\n:vars\n")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "> rational\n> a = (13/4) km\nb = 3 km\n> int64\n> b = 3 km\n")
+	assert.Equal(t, "warning: a = 3.25 km is dropped, it can not be carried over to int64 mode: "+
+		"3.25 at position 0: number is not an integer: argument out of domain\n", stderr)
+}
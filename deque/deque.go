@@ -0,0 +1,255 @@
+package deque
+
+import (
+	"context"
+	"github.com/denismitr/gds/internal/utils"
+)
+
+const minCapacity = 8
+
+// Deque is a double-ended queue backed by a growable circular array,
+// all the push and pop operations are amortized O(1) with no per-element allocation.
+type Deque[T any] struct {
+	locker utils.Locker
+	signal utils.Signal
+	buf    []T
+	head   int
+	size   int
+}
+
+type options struct {
+	useMutex bool
+}
+
+type OptionFunc func(*options)
+
+// WithMutex makes the deque safe for concurrent use,
+// it is required for the blocking Take operations to be useful
+func WithMutex() OptionFunc {
+	return func(o *options) {
+		o.useMutex = true
+	}
+}
+
+// New creates an empty deque
+func New[T any](ofs ...OptionFunc) *Deque[T] {
+	var opts options
+	for _, opt := range ofs {
+		opt(&opts)
+	}
+
+	d := &Deque[T]{}
+
+	if opts.useMutex {
+		d.locker = &utils.MutexLock{}
+	} else {
+		d.locker = utils.NullLocker{}
+	}
+
+	return d
+}
+
+// PushBack adds value to the back of the deque
+func (d *Deque[T]) PushBack(value T) {
+	d.locker.WriteLock()
+	defer d.locker.WriteUnlock()
+
+	d.grow()
+	d.buf[d.index(d.size)] = value
+	d.size++
+	d.signal.Broadcast()
+}
+
+// PushFront adds value to the front of the deque
+func (d *Deque[T]) PushFront(value T) {
+	d.locker.WriteLock()
+	defer d.locker.WriteUnlock()
+
+	d.grow()
+	d.head = d.index(len(d.buf) - 1)
+	d.buf[d.head] = value
+	d.size++
+	d.signal.Broadcast()
+}
+
+// PopFront removes and returns the value at the front
+func (d *Deque[T]) PopFront() (T, bool) {
+	d.locker.WriteLock()
+	defer d.locker.WriteUnlock()
+
+	return d.popFront()
+}
+
+// PopBack removes and returns the value at the back
+func (d *Deque[T]) PopBack() (T, bool) {
+	d.locker.WriteLock()
+	defer d.locker.WriteUnlock()
+
+	return d.popBack()
+}
+
+// TakeFront removes and returns the value at the front,
+// waiting for one to be pushed if the deque is empty
+func (d *Deque[T]) TakeFront(ctx context.Context) (T, error) {
+	return d.take(ctx, d.popFront)
+}
+
+// TakeBack removes and returns the value at the back,
+// waiting for one to be pushed if the deque is empty
+func (d *Deque[T]) TakeBack(ctx context.Context) (T, error) {
+	return d.take(ctx, d.popBack)
+}
+
+// Front returns the value at the front without removing it
+func (d *Deque[T]) Front() (T, bool) {
+	return d.At(0)
+}
+
+// Back returns the value at the back without removing it
+func (d *Deque[T]) Back() (T, bool) {
+	d.locker.ReadLock()
+	defer d.locker.ReadUnlock()
+
+	return d.at(d.size - 1)
+}
+
+// At returns the value at index counting from the front
+func (d *Deque[T]) At(index int) (T, bool) {
+	d.locker.ReadLock()
+	defer d.locker.ReadUnlock()
+
+	return d.at(index)
+}
+
+// Slice returns the values from front to back
+func (d *Deque[T]) Slice() []T {
+	d.locker.ReadLock()
+	defer d.locker.ReadUnlock()
+
+	result := make([]T, d.size)
+	for i := range result {
+		result[i] = d.buf[d.index(i)]
+	}
+
+	return result
+}
+
+// Clear removes all the values and releases the memory
+func (d *Deque[T]) Clear() {
+	d.locker.WriteLock()
+	defer d.locker.WriteUnlock()
+
+	d.buf, d.head, d.size = nil, 0, 0
+}
+
+func (d *Deque[T]) Size() int {
+	d.locker.ReadLock()
+	defer d.locker.ReadUnlock()
+	return d.size
+}
+
+func (d *Deque[T]) Empty() bool {
+	d.locker.ReadLock()
+	defer d.locker.ReadUnlock()
+	return d.size == 0
+}
+
+func (d *Deque[T]) take(ctx context.Context, pop func() (T, bool)) (T, error) {
+	for {
+		d.locker.WriteLock()
+		value, ok := pop()
+		var wait <-chan struct{}
+		if !ok {
+			wait = d.signal.Wait()
+		}
+		d.locker.WriteUnlock()
+
+		if ok {
+			return value, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		case <-wait:
+		}
+	}
+}
+
+func (d *Deque[T]) popFront() (T, bool) {
+	var zero T
+	if d.size == 0 {
+		return zero, false
+	}
+
+	value := d.buf[d.head]
+	d.buf[d.head] = zero
+	d.head = d.index(1)
+	d.size--
+	d.shrink()
+	return value, true
+}
+
+func (d *Deque[T]) popBack() (T, bool) {
+	var zero T
+	if d.size == 0 {
+		return zero, false
+	}
+
+	last := d.index(d.size - 1)
+	value := d.buf[last]
+	d.buf[last] = zero
+	d.size--
+	d.shrink()
+	return value, true
+}
+
+func (d *Deque[T]) at(index int) (T, bool) {
+	if index < 0 || index >= d.size {
+		var zero T
+		return zero, false
+	}
+
+	return d.buf[d.index(index)], true
+}
+
+// index converts a position relative to the head into an index in the buffer
+func (d *Deque[T]) index(i int) int {
+	return (d.head + i) % len(d.buf)
+}
+
+func (d *Deque[T]) grow() {
+	if d.size < len(d.buf) {
+		return
+	}
+
+	newCap := len(d.buf) * 2
+	if newCap < minCapacity {
+		newCap = minCapacity
+	}
+
+	d.resize(newCap)
+}
+
+// shrink halves the buffer when it is only a quarter full
+func (d *Deque[T]) shrink() {
+	if len(d.buf) > minCapacity && d.size <= len(d.buf)/4 {
+		d.resize(len(d.buf) / 2)
+	}
+}
+
+func (d *Deque[T]) resize(newCap int) {
+	buf := make([]T, newCap)
+	if d.size > 0 {
+		if d.head+d.size <= len(d.buf) {
+			copy(buf, d.buf[d.head:d.head+d.size])
+		} else {
+			n := copy(buf, d.buf[d.head:])
+			copy(buf[n:], d.buf[:d.size-n])
+		}
+	}
+
+	d.buf = buf
+	d.head = 0
+}
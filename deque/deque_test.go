@@ -0,0 +1,175 @@
+package deque_test
+
+import (
+	"context"
+	"github.com/denismitr/gds/deque"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDeque_PushPop(t *testing.T) {
+	d := deque.New[int]()
+	assert.True(t, d.Empty())
+
+	_, ok := d.PopFront()
+	assert.False(t, ok)
+	_, ok = d.PopBack()
+	assert.False(t, ok)
+	_, ok = d.Back()
+	assert.False(t, ok)
+
+	d.PushBack(2)
+	d.PushBack(3)
+	d.PushFront(1)
+	d.PushFront(0)
+
+	assert.Equal(t, 4, d.Size())
+	assert.Equal(t, []int{0, 1, 2, 3}, d.Slice())
+
+	front, ok := d.Front()
+	require.True(t, ok)
+	assert.Equal(t, 0, front)
+
+	back, ok := d.Back()
+	require.True(t, ok)
+	assert.Equal(t, 3, back)
+
+	v, ok := d.At(2)
+	require.True(t, ok)
+	assert.Equal(t, 2, v)
+	_, ok = d.At(4)
+	assert.False(t, ok)
+
+	v, ok = d.PopBack()
+	require.True(t, ok)
+	assert.Equal(t, 3, v)
+
+	v, ok = d.PopFront()
+	require.True(t, ok)
+	assert.Equal(t, 0, v)
+
+	assert.Equal(t, []int{1, 2}, d.Slice())
+
+	d.Clear()
+	assert.True(t, d.Empty())
+	assert.Equal(t, []int{}, d.Slice())
+}
+
+func TestDeque_AgainstSlice(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	d := deque.New[int]()
+	var expected []int
+
+	for i := 0; i < 100000; i++ {
+		switch rnd.Intn(4) {
+		case 0:
+			d.PushBack(i)
+			expected = append(expected, i)
+		case 1:
+			d.PushFront(i)
+			expected = append([]int{i}, expected...)
+		case 2:
+			v, ok := d.PopFront()
+			if len(expected) == 0 {
+				require.False(t, ok)
+				continue
+			}
+			require.True(t, ok)
+			require.Equal(t, expected[0], v)
+			expected = expected[1:]
+		case 3:
+			v, ok := d.PopBack()
+			if len(expected) == 0 {
+				require.False(t, ok)
+				continue
+			}
+			require.True(t, ok)
+			require.Equal(t, expected[len(expected)-1], v)
+			expected = expected[:len(expected)-1]
+		}
+
+		require.Equal(t, len(expected), d.Size())
+	}
+
+	assert.Equal(t, append([]int{}, expected...), d.Slice())
+}
+
+func TestDeque_Take(t *testing.T) {
+	t.Run("take waits for a value", func(t *testing.T) {
+		d := deque.New[string](deque.WithMutex())
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			d.PushBack("foo")
+		}()
+
+		v, err := d.TakeFront(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "foo", v)
+	})
+
+	t.Run("take is cancelled with context", func(t *testing.T) {
+		d := deque.New[string](deque.WithMutex())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err := d.TakeBack(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("concurrent producers and consumers", func(t *testing.T) {
+		const workers = 8
+		const perWorker = 1000
+
+		d := deque.New[int](deque.WithMutex())
+
+		var wg sync.WaitGroup
+		results := make(chan int, workers*perWorker)
+		for w := 0; w < workers; w++ {
+			wg.Add(2)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < perWorker; i++ {
+					if i%2 == 0 {
+						d.PushBack(w*perWorker + i)
+					} else {
+						d.PushFront(w*perWorker + i)
+					}
+				}
+			}(w)
+
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < perWorker; i++ {
+					take := d.TakeFront
+					if w%2 == 0 {
+						take = d.TakeBack
+					}
+
+					v, err := take(context.Background())
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					results <- v
+				}
+			}(w)
+		}
+
+		wg.Wait()
+		close(results)
+
+		seen := make(map[int]bool)
+		for v := range results {
+			assert.False(t, seen[v])
+			seen[v] = true
+		}
+		assert.Len(t, seen, workers*perWorker)
+		assert.True(t, d.Empty())
+	})
+}
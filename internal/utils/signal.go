@@ -0,0 +1,25 @@
+package utils
+
+// Signal wakes up goroutines waiting for a change of a state guarded by a Locker.
+// Both Wait and Broadcast must be called while holding the write lock,
+// that way a waiter can not miss a change that happens after it checked the state.
+type Signal struct {
+	ch chan struct{}
+}
+
+// Wait returns a channel that gets closed on the next Broadcast
+func (s *Signal) Wait() <-chan struct{} {
+	if s.ch == nil {
+		s.ch = make(chan struct{})
+	}
+
+	return s.ch
+}
+
+// Broadcast wakes up all the current waiters
+func (s *Signal) Broadcast() {
+	if s.ch != nil {
+		close(s.ch)
+		s.ch = nil
+	}
+}
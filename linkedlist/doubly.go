@@ -0,0 +1,307 @@
+package linkedlist
+
+import "github.com/denismitr/gds/internal/utils"
+
+// Element is a node of a doubly linked list, it is handed out to callers
+// so that they can remove or move elements in O(1).
+// Next and Prev are not synchronized with the list locker.
+type Element[T any] struct {
+	value T
+	next  *Element[T]
+	prev  *Element[T]
+	list  *DoublyLinkedList[T]
+}
+
+// Value stored in the element
+func (e *Element[T]) Value() T {
+	return e.value
+}
+
+// Next element or nil if e is the last one
+func (e *Element[T]) Next() *Element[T] {
+	return e.next
+}
+
+// Prev element or nil if e is the first one
+func (e *Element[T]) Prev() *Element[T] {
+	return e.prev
+}
+
+// DoublyLinkedList keeps links in both directions and a pointer to the tail,
+// so that it can be walked backwards and elements can be added or removed at both ends in O(1)
+type DoublyLinkedList[T any] struct {
+	locker  utils.Locker
+	head    *Element[T]
+	tail    *Element[T]
+	size    int
+	version uint64
+}
+
+func NewDoubly[T any](locks bool) *DoublyLinkedList[T] {
+	ll := &DoublyLinkedList[T]{}
+
+	if locks {
+		ll.locker = &utils.MutexLock{}
+	} else {
+		ll.locker = utils.NullLocker{}
+	}
+
+	return ll
+}
+
+// PushFront inserts value at the head
+func (ll *DoublyLinkedList[T]) PushFront(value T) *Element[T] {
+	ll.locker.WriteLock()
+	defer ll.locker.WriteUnlock()
+
+	return ll.insert(value, nil, ll.head)
+}
+
+// PushBack inserts value at the tail
+func (ll *DoublyLinkedList[T]) PushBack(value T) *Element[T] {
+	ll.locker.WriteLock()
+	defer ll.locker.WriteUnlock()
+
+	return ll.insert(value, ll.tail, nil)
+}
+
+// InsertBefore inserts value right before mark, mark must belong to the list
+func (ll *DoublyLinkedList[T]) InsertBefore(value T, mark *Element[T]) *Element[T] {
+	ll.locker.WriteLock()
+	defer ll.locker.WriteUnlock()
+
+	if mark.list != ll {
+		return nil
+	}
+
+	return ll.insert(value, mark.prev, mark)
+}
+
+// InsertAfter inserts value right after mark, mark must belong to the list
+func (ll *DoublyLinkedList[T]) InsertAfter(value T, mark *Element[T]) *Element[T] {
+	ll.locker.WriteLock()
+	defer ll.locker.WriteUnlock()
+
+	if mark.list != ll {
+		return nil
+	}
+
+	return ll.insert(value, mark, mark.next)
+}
+
+// Remove unlinks e from the list and returns its value,
+// false is returned when e does not belong to the list
+func (ll *DoublyLinkedList[T]) Remove(e *Element[T]) (T, bool) {
+	ll.locker.WriteLock()
+	defer ll.locker.WriteUnlock()
+
+	if e.list != ll {
+		var zero T
+		return zero, false
+	}
+
+	ll.unlink(e)
+	return e.value, true
+}
+
+// PopFront removes the head and returns its value
+func (ll *DoublyLinkedList[T]) PopFront() (T, bool) {
+	ll.locker.WriteLock()
+	defer ll.locker.WriteUnlock()
+
+	if ll.head == nil {
+		var zero T
+		return zero, false
+	}
+
+	e := ll.head
+	ll.unlink(e)
+	return e.value, true
+}
+
+// PopBack removes the tail and returns its value
+func (ll *DoublyLinkedList[T]) PopBack() (T, bool) {
+	ll.locker.WriteLock()
+	defer ll.locker.WriteUnlock()
+
+	if ll.tail == nil {
+		var zero T
+		return zero, false
+	}
+
+	e := ll.tail
+	ll.unlink(e)
+	return e.value, true
+}
+
+// MoveToFront moves e to the head of the list
+func (ll *DoublyLinkedList[T]) MoveToFront(e *Element[T]) {
+	ll.locker.WriteLock()
+	defer ll.locker.WriteUnlock()
+
+	if e.list != ll || ll.head == e {
+		return
+	}
+
+	ll.unlink(e)
+	ll.link(e, nil, ll.head)
+}
+
+// MoveToBack moves e to the tail of the list
+func (ll *DoublyLinkedList[T]) MoveToBack(e *Element[T]) {
+	ll.locker.WriteLock()
+	defer ll.locker.WriteUnlock()
+
+	if e.list != ll || ll.tail == e {
+		return
+	}
+
+	ll.unlink(e)
+	ll.link(e, ll.tail, nil)
+}
+
+// Front returns the first element or nil
+func (ll *DoublyLinkedList[T]) Front() *Element[T] {
+	ll.locker.ReadLock()
+	defer ll.locker.ReadUnlock()
+	return ll.head
+}
+
+// Back returns the last element or nil
+func (ll *DoublyLinkedList[T]) Back() *Element[T] {
+	ll.locker.ReadLock()
+	defer ll.locker.ReadUnlock()
+	return ll.tail
+}
+
+// Slice returns values from head to tail
+func (ll *DoublyLinkedList[T]) Slice() []T {
+	ll.locker.ReadLock()
+	defer ll.locker.ReadUnlock()
+
+	result := make([]T, 0, ll.size)
+	for curr := ll.head; curr != nil; curr = curr.next {
+		result = append(result, curr.value)
+	}
+	return result
+}
+
+func (ll *DoublyLinkedList[T]) Size() int {
+	ll.locker.ReadLock()
+	defer ll.locker.ReadUnlock()
+	return ll.size
+}
+
+func (ll *DoublyLinkedList[T]) Empty() bool {
+	ll.locker.ReadLock()
+	defer ll.locker.ReadUnlock()
+	return ll.size == 0
+}
+
+// Iterator returns a fail-fast iterator walking from head to tail
+func (ll *DoublyLinkedList[T]) Iterator() *DoublyIterator[T] {
+	ll.locker.ReadLock()
+	defer ll.locker.ReadUnlock()
+
+	return &DoublyIterator[T]{ll: ll, next: ll.head, version: ll.version}
+}
+
+// ReverseIterator returns a fail-fast iterator walking from tail to head
+func (ll *DoublyLinkedList[T]) ReverseIterator() *DoublyIterator[T] {
+	ll.locker.ReadLock()
+	defer ll.locker.ReadUnlock()
+
+	return &DoublyIterator[T]{ll: ll, next: ll.tail, backward: true, version: ll.version}
+}
+
+func (ll *DoublyLinkedList[T]) insert(value T, prev, next *Element[T]) *Element[T] {
+	e := &Element[T]{value: value}
+	ll.link(e, prev, next)
+	return e
+}
+
+func (ll *DoublyLinkedList[T]) link(e, prev, next *Element[T]) {
+	e.prev, e.next, e.list = prev, next, ll
+
+	if prev == nil {
+		ll.head = e
+	} else {
+		prev.next = e
+	}
+
+	if next == nil {
+		ll.tail = e
+	} else {
+		next.prev = e
+	}
+
+	ll.size++
+	ll.version++
+}
+
+func (ll *DoublyLinkedList[T]) unlink(e *Element[T]) {
+	if e.prev == nil {
+		ll.head = e.next
+	} else {
+		e.prev.next = e.next
+	}
+
+	if e.next == nil {
+		ll.tail = e.prev
+	} else {
+		e.next.prev = e.prev
+	}
+
+	e.prev, e.next, e.list = nil, nil, nil
+	ll.size--
+	ll.version++
+}
+
+// DoublyIterator walks a doubly linked list in either direction,
+// it fails fast with ErrConcurrentModification like Iterator does
+type DoublyIterator[T any] struct {
+	ll       *DoublyLinkedList[T]
+	next     *Element[T]
+	value    T
+	backward bool
+	version  uint64
+	err      error
+}
+
+// Next advances the iterator and reports whether there is a value to read
+func (it *DoublyIterator[T]) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	it.ll.locker.ReadLock()
+	defer it.ll.locker.ReadUnlock()
+
+	if it.version != it.ll.version {
+		it.err = ErrConcurrentModification
+		return false
+	}
+
+	if it.next == nil {
+		return false
+	}
+
+	it.value = it.next.value
+	if it.backward {
+		it.next = it.next.prev
+	} else {
+		it.next = it.next.next
+	}
+
+	return true
+}
+
+// Value returns data at the current iterator position
+func (it *DoublyIterator[T]) Value() T {
+	return it.value
+}
+
+// Err returns ErrConcurrentModification if the iteration was interrupted
+func (it *DoublyIterator[T]) Err() error {
+	return it.err
+}
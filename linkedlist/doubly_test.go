@@ -0,0 +1,108 @@
+package linkedlist_test
+
+import (
+	"github.com/denismitr/gds/linkedlist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func collectBackward[T any](ll *linkedlist.DoublyLinkedList[T]) []T {
+	result := []T{}
+	it := ll.ReverseIterator()
+	for it.Next() {
+		result = append(result, it.Value())
+	}
+	return result
+}
+
+func TestDoublyLinkedList(t *testing.T) {
+	t.Run("push and pop at both ends", func(t *testing.T) {
+		ll := linkedlist.NewDoubly[int](true)
+		assert.True(t, ll.Empty())
+		assert.Nil(t, ll.Front())
+		assert.Nil(t, ll.Back())
+
+		ll.PushBack(2)
+		ll.PushBack(3)
+		ll.PushFront(1)
+
+		assert.Equal(t, 3, ll.Size())
+		assert.Equal(t, []int{1, 2, 3}, ll.Slice())
+		assert.Equal(t, []int{3, 2, 1}, collectBackward(ll))
+		assert.Equal(t, 1, ll.Front().Value())
+		assert.Equal(t, 3, ll.Back().Value())
+
+		v, ok := ll.PopBack()
+		require.True(t, ok)
+		assert.Equal(t, 3, v)
+
+		v, ok = ll.PopFront()
+		require.True(t, ok)
+		assert.Equal(t, 1, v)
+
+		v, ok = ll.PopFront()
+		require.True(t, ok)
+		assert.Equal(t, 2, v)
+
+		_, ok = ll.PopFront()
+		assert.False(t, ok)
+		_, ok = ll.PopBack()
+		assert.False(t, ok)
+		assert.True(t, ll.Empty())
+	})
+
+	t.Run("element handles", func(t *testing.T) {
+		ll := linkedlist.NewDoubly[string](false)
+		b := ll.PushBack("b")
+		d := ll.PushBack("d")
+		a := ll.InsertBefore("a", b)
+		c := ll.InsertAfter("c", b)
+
+		assert.Equal(t, []string{"a", "b", "c", "d"}, ll.Slice())
+		assert.Equal(t, c, b.Next())
+		assert.Equal(t, a, b.Prev())
+		assert.Nil(t, a.Prev())
+		assert.Nil(t, d.Next())
+
+		ll.MoveToFront(d)
+		assert.Equal(t, []string{"d", "a", "b", "c"}, ll.Slice())
+
+		ll.MoveToBack(a)
+		assert.Equal(t, []string{"d", "b", "c", "a"}, ll.Slice())
+		assert.Equal(t, []string{"a", "c", "b", "d"}, collectBackward(ll))
+
+		v, ok := ll.Remove(b)
+		require.True(t, ok)
+		assert.Equal(t, "b", v)
+		assert.Equal(t, []string{"d", "c", "a"}, ll.Slice())
+		assert.Equal(t, 3, ll.Size())
+
+		// removing twice or removing a foreign element is a no-op
+		_, ok = ll.Remove(b)
+		assert.False(t, ok)
+
+		other := linkedlist.NewDoubly[string](false)
+		foreign := other.PushBack("x")
+		_, ok = ll.Remove(foreign)
+		assert.False(t, ok)
+		assert.Nil(t, ll.InsertAfter("y", foreign))
+		ll.MoveToFront(foreign)
+		assert.Equal(t, []string{"d", "c", "a"}, ll.Slice())
+		assert.Equal(t, []string{"x"}, other.Slice())
+	})
+
+	t.Run("iterators fail fast", func(t *testing.T) {
+		ll := linkedlist.NewDoubly[int](true)
+		ll.PushBack(1)
+		ll.PushBack(2)
+
+		it := ll.ReverseIterator()
+		require.True(t, it.Next())
+		assert.Equal(t, 2, it.Value())
+
+		ll.PushFront(0)
+		assert.False(t, it.Next())
+		assert.ErrorIs(t, it.Err(), linkedlist.ErrConcurrentModification)
+	})
+}
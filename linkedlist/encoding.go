@@ -0,0 +1,268 @@
+package linkedlist
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"github.com/denismitr/gds/internal/utils"
+	"github.com/pkg/errors"
+	"io"
+	"sync/atomic"
+)
+
+var ErrInvalidEncoding = errors.New("invalid linked list encoding")
+
+// encodingVersion is the first byte of the binary format, the rest is
+// the number of elements as uvarint followed by every element as uvarint length and bytes
+const encodingVersion byte = 1
+
+// Codec converts a single element to bytes and back for the binary and gob encodings,
+// the bytes passed to Unmarshal are reused afterwards, so they must not be retained
+type Codec[T any] interface {
+	Marshal(data T) ([]byte, error)
+	Unmarshal(b []byte) (T, error)
+}
+
+// JSONCodec encodes elements with encoding/json, it is the default codec
+type JSONCodec[T any] struct{}
+
+func (JSONCodec[T]) Marshal(data T) ([]byte, error) {
+	return json.Marshal(data)
+}
+
+func (JSONCodec[T]) Unmarshal(b []byte) (T, error) {
+	var data T
+	err := json.Unmarshal(b, &data)
+	return data, err
+}
+
+// GobCodec encodes elements with encoding/gob, every element carries its own type information
+type GobCodec[T any] struct{}
+
+func (GobCodec[T]) Marshal(data T) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(data); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (GobCodec[T]) Unmarshal(b []byte) (T, error) {
+	var data T
+	err := gob.NewDecoder(bytes.NewReader(b)).Decode(&data)
+	return data, err
+}
+
+// SetCodec changes the codec used by MarshalBinary, UnmarshalBinary, GobEncode and GobDecode
+func (ll *LinkedList[T]) SetCodec(codec Codec[T]) {
+	ll.init()
+	ll.locker.WriteLock()
+	defer ll.locker.WriteUnlock()
+
+	ll.codec = codec
+}
+
+// MarshalJSON encodes the list as a JSON array
+func (ll *LinkedList[T]) MarshalJSON() ([]byte, error) {
+	ll.init()
+	return json.Marshal(ll.Slice())
+}
+
+// UnmarshalJSON replaces the contents of the list with the elements of a JSON array
+func (ll *LinkedList[T]) UnmarshalJSON(b []byte) error {
+	var items []T
+	if err := json.Unmarshal(b, &items); err != nil {
+		return err
+	}
+
+	var bd builder[T]
+	for _, data := range items {
+		bd.append(data)
+	}
+
+	ll.init()
+	ll.replace(&bd)
+	return nil
+}
+
+func (ll *LinkedList[T]) MarshalBinary() ([]byte, error) {
+	ll.init()
+
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf, ll.elementCodec()).Encode(ll); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (ll *LinkedList[T]) UnmarshalBinary(b []byte) error {
+	ll.init()
+	return NewDecoder(bytes.NewReader(b), ll.elementCodec()).Decode(ll)
+}
+
+func (ll *LinkedList[T]) GobEncode() ([]byte, error) {
+	return ll.MarshalBinary()
+}
+
+func (ll *LinkedList[T]) GobDecode(b []byte) error {
+	return ll.UnmarshalBinary(b)
+}
+
+// Encoder writes lists in the binary format element by element,
+// so a long list never has to be copied into a slice or a single buffer
+type Encoder[T any] struct {
+	w       *bufio.Writer
+	codec   Codec[T]
+	scratch [binary.MaxVarintLen64]byte
+}
+
+func NewEncoder[T any](w io.Writer, codec Codec[T]) *Encoder[T] {
+	return &Encoder[T]{w: bufio.NewWriter(w), codec: codec}
+}
+
+// Encode writes the list, it is read locked until the whole list is written
+func (e *Encoder[T]) Encode(ll *LinkedList[T]) error {
+	ll.init()
+	ll.locker.ReadLock()
+	defer ll.locker.ReadUnlock()
+
+	if err := e.w.WriteByte(encodingVersion); err != nil {
+		return err
+	}
+
+	if err := e.writeUvarint(uint64(ll.size)); err != nil {
+		return err
+	}
+
+	written := 0
+	for curr := ll.head; curr != nil && written < ll.size; curr = curr.next {
+		b, err := e.codec.Marshal(curr.data)
+		if err != nil {
+			return errors.Wrapf(err, "could not marshal element %d", written)
+		}
+
+		if err := e.writeUvarint(uint64(len(b))); err != nil {
+			return err
+		}
+
+		if _, err := e.w.Write(b); err != nil {
+			return err
+		}
+
+		written++
+	}
+
+	return e.w.Flush()
+}
+
+func (e *Encoder[T]) writeUvarint(n uint64) error {
+	l := binary.PutUvarint(e.scratch[:], n)
+	_, err := e.w.Write(e.scratch[:l])
+	return err
+}
+
+// Decoder reads lists written by Encoder, when the reader is not an io.ByteReader
+// it is buffered, so the decoder may read past the end of the encoded list
+type Decoder[T any] struct {
+	r     byteReader
+	codec Codec[T]
+	buf   bytes.Buffer
+}
+
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+func NewDecoder[T any](r io.Reader, codec Codec[T]) *Decoder[T] {
+	br, ok := r.(byteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+
+	return &Decoder[T]{r: br, codec: codec}
+}
+
+// Decode replaces the contents of the list with the next encoded list,
+// the list is not modified when decoding fails
+func (d *Decoder[T]) Decode(ll *LinkedList[T]) error {
+	version, err := d.r.ReadByte()
+	if err != nil {
+		return err
+	}
+
+	if version != encodingVersion {
+		return errors.Wrapf(ErrInvalidEncoding, "unsupported version %d", version)
+	}
+
+	size, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		return errors.Wrapf(ErrInvalidEncoding, "could not read size: %v", err)
+	}
+
+	var bd builder[T]
+	for i := uint64(0); i < size; i++ {
+		data, err := d.decodeElement()
+		if err != nil {
+			return errors.Wrapf(err, "element %d of %d", i, size)
+		}
+
+		bd.append(data)
+	}
+
+	ll.init()
+	ll.replace(&bd)
+	return nil
+}
+
+func (d *Decoder[T]) decodeElement() (T, error) {
+	var zero T
+
+	l, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		return zero, errors.Wrapf(ErrInvalidEncoding, "could not read element length: %v", err)
+	}
+
+	if int64(l) < 0 {
+		return zero, errors.Wrapf(ErrInvalidEncoding, "element length %d is too big", l)
+	}
+
+	// copying instead of allocating l bytes up front keeps a corrupted length from exhausting memory
+	d.buf.Reset()
+	if _, err := io.CopyN(&d.buf, d.r, int64(l)); err != nil {
+		return zero, errors.Wrapf(ErrInvalidEncoding, "could not read element: %v", err)
+	}
+
+	return d.codec.Unmarshal(d.buf.Bytes())
+}
+
+// init makes a zero value LinkedList usable, so that it can be a target of json.Unmarshal or gob.Decode
+func (ll *LinkedList[T]) init() {
+	if ll.locker == nil {
+		ll.id = atomic.AddUint64(&lastID, 1)
+		ll.locker = utils.NullLocker{}
+	}
+}
+
+func (ll *LinkedList[T]) elementCodec() Codec[T] {
+	ll.locker.ReadLock()
+	defer ll.locker.ReadUnlock()
+
+	if ll.codec == nil {
+		return JSONCodec[T]{}
+	}
+
+	return ll.codec
+}
+
+func (ll *LinkedList[T]) replace(bd *builder[T]) {
+	ll.locker.WriteLock()
+	defer ll.locker.WriteUnlock()
+
+	ll.head, ll.size = bd.head, bd.size
+	ll.version++
+}
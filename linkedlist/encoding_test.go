@@ -0,0 +1,170 @@
+package linkedlist_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"github.com/denismitr/gds/linkedlist"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"testing"
+)
+
+type point struct {
+	X, Y int
+}
+
+// decimalCodec is a codec that stores ints as decimal text
+type decimalCodec struct{}
+
+func (decimalCodec) Marshal(n int) ([]byte, error) {
+	return []byte(strconv.Itoa(n)), nil
+}
+
+func (decimalCodec) Unmarshal(b []byte) (int, error) {
+	return strconv.Atoi(string(b))
+}
+
+func TestLinkedList_JSON(t *testing.T) {
+	ll := linkedlist.NewOf[point](true)
+	ll.Append(point{1, 2})
+	ll.Append(point{3, 4})
+
+	b, err := json.Marshal(ll)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"X":1,"Y":2},{"X":3,"Y":4}]`, string(b))
+
+	restored := linkedlist.NewOf[point](true)
+	restored.Append(point{9, 9})
+	require.NoError(t, json.Unmarshal(b, restored))
+	assert.Equal(t, ll.Slice(), restored.Slice())
+	assert.Equal(t, 2, restored.Size())
+
+	empty, err := json.Marshal(linkedlist.NewOf[int](false))
+	require.NoError(t, err)
+	assert.Equal(t, `[]`, string(empty))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"a":1}`), restored))
+	assert.Equal(t, ll.Slice(), restored.Slice(), "failed unmarshal keeps the contents")
+}
+
+func TestLinkedList_JSONAsField(t *testing.T) {
+	type payload struct {
+		Name  string
+		Items linkedlist.LinkedList[string]
+	}
+
+	var p payload
+	require.NoError(t, json.Unmarshal([]byte(`{"Name":"abc","Items":["a","b","c"]}`), &p))
+	assert.Equal(t, []string{"a", "b", "c"}, p.Items.Slice())
+
+	p.Items.Append("d")
+	b, err := json.Marshal(&p)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"Name":"abc","Items":["a","b","c","d"]}`, string(b))
+}
+
+func TestLinkedList_Binary(t *testing.T) {
+	ll := linkedlist.NewOf[int](true)
+	for i := -5; i < 300; i++ {
+		ll.Append(i * 1000)
+	}
+
+	b, err := ll.MarshalBinary()
+	require.NoError(t, err)
+
+	restored := linkedlist.NewOf[int](true)
+	require.NoError(t, restored.UnmarshalBinary(b))
+	assert.Equal(t, ll.Slice(), restored.Slice())
+	assert.Equal(t, ll.Size(), restored.Size())
+
+	t.Run("custom codec", func(t *testing.T) {
+		ll := linkedlist.NewOf[int](false)
+		ll.SetCodec(decimalCodec{})
+		ll.Append(42)
+		ll.Append(-7)
+
+		b, err := ll.MarshalBinary()
+		require.NoError(t, err)
+		assert.Equal(t, []byte{1, 2, 2, '4', '2', 2, '-', '7'}, b)
+
+		restored := linkedlist.NewOf[int](false)
+		restored.SetCodec(decimalCodec{})
+		require.NoError(t, restored.UnmarshalBinary(b))
+		assert.Equal(t, []int{42, -7}, restored.Slice())
+	})
+
+	t.Run("corrupted data", func(t *testing.T) {
+		tt := []struct {
+			name string
+			data []byte
+		}{
+			{"unknown version", []byte{9, 0}},
+			{"missing size", []byte{1}},
+			{"missing element", []byte{1, 2, 1, '1'}},
+			{"short element", []byte{1, 1, 5, '1'}},
+		}
+
+		for _, tc := range tt {
+			t.Run(tc.name, func(t *testing.T) {
+				target := linkedlist.NewOf[int](false)
+				target.Append(1)
+
+				err := target.UnmarshalBinary(tc.data)
+				assert.True(t, errors.Is(err, linkedlist.ErrInvalidEncoding), "got %v", err)
+				assert.Equal(t, []int{1}, target.Slice())
+			})
+		}
+	})
+}
+
+func TestLinkedList_Gob(t *testing.T) {
+	type payload struct {
+		Points *linkedlist.LinkedList[point]
+		Names  *linkedlist.LinkedList[string]
+	}
+
+	in := payload{Points: linkedlist.NewOf[point](true), Names: linkedlist.NewOf[string](false)}
+	in.Points.Append(point{1, 1})
+	in.Points.Append(point{2, 4})
+	in.Names.Append("x")
+
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(&in))
+
+	var out payload
+	require.NoError(t, gob.NewDecoder(&buf).Decode(&out))
+	assert.Equal(t, []point{{1, 1}, {2, 4}}, out.Points.Slice())
+	assert.Equal(t, []string{"x"}, out.Names.Slice())
+}
+
+func TestEncoder_StreamsSeveralLists(t *testing.T) {
+	var buf bytes.Buffer
+	enc := linkedlist.NewEncoder[string](&buf, linkedlist.GobCodec[string]{})
+
+	first := linkedlist.NewOf[string](false)
+	for i := 0; i < 10000; i++ {
+		first.Append(strconv.Itoa(i))
+	}
+
+	second := linkedlist.NewOf[string](false)
+	second.Append("only")
+
+	require.NoError(t, enc.Encode(first))
+	require.NoError(t, enc.Encode(second))
+	require.NoError(t, enc.Encode(linkedlist.NewOf[string](false)))
+
+	dec := linkedlist.NewDecoder[string](&buf, linkedlist.GobCodec[string]{})
+
+	ll := linkedlist.NewOf[string](false)
+	require.NoError(t, dec.Decode(ll))
+	assert.Equal(t, first.Slice(), ll.Slice())
+
+	require.NoError(t, dec.Decode(ll))
+	assert.Equal(t, []string{"only"}, ll.Slice())
+
+	require.NoError(t, dec.Decode(ll))
+	assert.True(t, ll.Empty())
+}
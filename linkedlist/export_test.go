@@ -0,0 +1,16 @@
+package linkedlist
+
+// MakeCycle links the node at index from back to the node at index to,
+// it is only available to tests to emulate a corrupted list
+func MakeCycle[T any](ll *LinkedList[T], from, to int) {
+	ll.nodeAt(from).next = ll.nodeAt(to)
+}
+
+// ChunkSizes returns the number of elements in every chunk of an unrolled list
+func ChunkSizes[T any](ul *Unrolled[T]) []int {
+	var sizes []int
+	for c := ul.head; c != nil; c = c.next {
+		sizes = append(sizes, len(c.items))
+	}
+	return sizes
+}
@@ -0,0 +1,297 @@
+package linkedlist
+
+import "github.com/pkg/errors"
+
+var ErrIndexOutOfRange = errors.New("index out of range")
+
+// All the callbacks below are invoked while the list is locked,
+// so they must not call methods of the same list.
+
+// Map returns a new list with fn applied to every element
+func (ll *LinkedList[T]) Map(fn func(data T) T) *LinkedList[T] {
+	ll.locker.ReadLock()
+	defer ll.locker.ReadUnlock()
+
+	var b builder[T]
+	for curr := ll.head; curr != nil; curr = curr.next {
+		b.append(fn(curr.data))
+	}
+
+	return b.build(ll.locks)
+}
+
+// MapTo returns a new list of U values with fn applied to every element of ll
+func MapTo[T, U any](ll *LinkedList[T], fn func(data T) U) *LinkedList[U] {
+	ll.locker.ReadLock()
+	defer ll.locker.ReadUnlock()
+
+	var b builder[U]
+	for curr := ll.head; curr != nil; curr = curr.next {
+		b.append(fn(curr.data))
+	}
+
+	return b.build(ll.locks)
+}
+
+// Filter returns a new list with only the elements that satisfy the predicate
+func (ll *LinkedList[T]) Filter(predicate func(data T) bool) *LinkedList[T] {
+	ll.locker.ReadLock()
+	defer ll.locker.ReadUnlock()
+
+	var b builder[T]
+	for curr := ll.head; curr != nil; curr = curr.next {
+		if predicate(curr.data) {
+			b.append(curr.data)
+		}
+	}
+
+	return b.build(ll.locks)
+}
+
+// Reduce folds the list from head to tail into a single value
+func (ll *LinkedList[T]) Reduce(initial T, fn func(acc, data T) T) T {
+	ll.locker.ReadLock()
+	defer ll.locker.ReadUnlock()
+
+	acc := initial
+	for curr := ll.head; curr != nil; curr = curr.next {
+		acc = fn(acc, curr.data)
+	}
+
+	return acc
+}
+
+// Fold reduces the list from head to tail into an accumulator of any type
+func Fold[T, A any](ll *LinkedList[T], initial A, fn func(acc A, data T) A) A {
+	ll.locker.ReadLock()
+	defer ll.locker.ReadUnlock()
+
+	acc := initial
+	for curr := ll.head; curr != nil; curr = curr.next {
+		acc = fn(acc, curr.data)
+	}
+
+	return acc
+}
+
+// ForEach calls fn for every element from head to tail
+func (ll *LinkedList[T]) ForEach(fn func(index int, data T)) {
+	ll.locker.ReadLock()
+	defer ll.locker.ReadUnlock()
+
+	i := 0
+	for curr := ll.head; curr != nil; curr = curr.next {
+		fn(i, curr.data)
+		i++
+	}
+}
+
+// Any reports whether at least one element satisfies the predicate
+func (ll *LinkedList[T]) Any(predicate func(data T) bool) bool {
+	ll.locker.ReadLock()
+	defer ll.locker.ReadUnlock()
+
+	for curr := ll.head; curr != nil; curr = curr.next {
+		if predicate(curr.data) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// All reports whether every element satisfies the predicate,
+// it is true for an empty list
+func (ll *LinkedList[T]) All(predicate func(data T) bool) bool {
+	ll.locker.ReadLock()
+	defer ll.locker.ReadUnlock()
+
+	for curr := ll.head; curr != nil; curr = curr.next {
+		if !predicate(curr.data) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Clone returns a shallow copy of the list with the same locking mode
+func (ll *LinkedList[T]) Clone() *LinkedList[T] {
+	return ll.Map(func(data T) T { return data })
+}
+
+// Equal reports whether both lists have the same size
+// and equal elements at the same positions
+func (ll *LinkedList[T]) Equal(other *LinkedList[T], equal func(a, b T) bool) bool {
+	if ll == other {
+		return true
+	}
+
+	unlock := readLockBoth(ll, other)
+	defer unlock()
+
+	if ll.size != other.size {
+		return false
+	}
+
+	for a, b := ll.head, other.head; a != nil && b != nil; a, b = a.next, b.next {
+		if !equal(a.data, b.data) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Concat returns a new list with the elements of the list followed by the elements of other
+func (ll *LinkedList[T]) Concat(other *LinkedList[T]) *LinkedList[T] {
+	unlock := readLockBoth(ll, other)
+	defer unlock()
+
+	var b builder[T]
+	for curr := ll.head; curr != nil; curr = curr.next {
+		b.append(curr.data)
+	}
+
+	for curr := other.head; curr != nil; curr = curr.next {
+		b.append(curr.data)
+	}
+
+	return b.build(ll.locks)
+}
+
+// Split cuts the list at index, the list keeps the first `at` elements
+// and the rest are moved to the returned list
+func (ll *LinkedList[T]) Split(at int) (*LinkedList[T], error) {
+	ll.locker.WriteLock()
+	defer ll.locker.WriteUnlock()
+
+	if at < 0 || at > ll.size {
+		return nil, errors.Wrapf(ErrIndexOutOfRange, "cannot split list of size %d at %d", ll.size, at)
+	}
+
+	tail := NewOf[T](ll.locks)
+	if at == ll.size {
+		return tail, nil
+	}
+
+	if at == 0 {
+		tail.head, tail.size = ll.head, ll.size
+		ll.head, ll.size = nil, 0
+	} else {
+		prev := ll.nodeAt(at - 1)
+		tail.head, tail.size = prev.next, ll.size-at
+		prev.next = nil
+		ll.size = at
+	}
+
+	ll.version++
+	return tail, nil
+}
+
+// Splice moves all the elements of other into the list starting at index,
+// other becomes empty
+func (ll *LinkedList[T]) Splice(at int, other *LinkedList[T]) error {
+	if ll == other {
+		return errors.New("cannot splice linked list into itself")
+	}
+
+	unlock := writeLockBoth(ll, other)
+	defer unlock()
+
+	if at < 0 || at > ll.size {
+		return errors.Wrapf(ErrIndexOutOfRange, "cannot splice into list of size %d at %d", ll.size, at)
+	}
+
+	if other.head == nil {
+		return nil
+	}
+
+	last := other.head
+	for last.next != nil {
+		last = last.next
+	}
+
+	if at == 0 {
+		last.next = ll.head
+		ll.head = other.head
+	} else {
+		prev := ll.nodeAt(at - 1)
+		last.next = prev.next
+		prev.next = other.head
+	}
+
+	ll.size += other.size
+	ll.version++
+	other.head, other.size = nil, 0
+	other.version++
+	return nil
+}
+
+func (ll *LinkedList[T]) nodeAt(index int) *Node[T] {
+	curr := ll.head
+	for i := 0; i < index; i++ {
+		curr = curr.next
+	}
+
+	return curr
+}
+
+// builder collects nodes in O(1) per element by keeping track of the tail
+type builder[T any] struct {
+	head *Node[T]
+	tail *Node[T]
+	size int
+}
+
+func (b *builder[T]) append(data T) {
+	n := &Node[T]{data: data}
+	if b.tail == nil {
+		b.head = n
+	} else {
+		b.tail.next = n
+	}
+
+	b.tail = n
+	b.size++
+}
+
+func (b *builder[T]) build(locks bool) *LinkedList[T] {
+	ll := NewOf[T](locks)
+	ll.head, ll.size = b.head, b.size
+	return ll
+}
+
+// readLockBoth locks two lists in the order of their ids to avoid deadlocks
+func readLockBoth[T any](a, b *LinkedList[T]) func() {
+	if a == b {
+		a.locker.ReadLock()
+		return a.locker.ReadUnlock
+	}
+
+	if a.id > b.id {
+		a, b = b, a
+	}
+
+	a.locker.ReadLock()
+	b.locker.ReadLock()
+
+	return func() {
+		b.locker.ReadUnlock()
+		a.locker.ReadUnlock()
+	}
+}
+
+func writeLockBoth[T any](a, b *LinkedList[T]) func() {
+	if a.id > b.id {
+		a, b = b, a
+	}
+
+	a.locker.WriteLock()
+	b.locker.WriteLock()
+
+	return func() {
+		b.locker.WriteUnlock()
+		a.locker.WriteUnlock()
+	}
+}
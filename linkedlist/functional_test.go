@@ -0,0 +1,188 @@
+package linkedlist_test
+
+import (
+	"fmt"
+	"github.com/denismitr/gds/linkedlist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+)
+
+func seedList(locks bool, items ...interface{}) *linkedlist.Untyped {
+	ll := linkedlist.New(locks)
+	for _, d := range items {
+		ll.Append(d)
+	}
+
+	return ll
+}
+
+func intsEqual(a, b interface{}) bool {
+	return a.(int) == b.(int)
+}
+
+func TestLinkedList_MapFilterReduce(t *testing.T) {
+	ll := seedList(true, 1, 2, 3, 4, 5)
+
+	squares := ll.Map(func(d interface{}) interface{} { return d.(int) * d.(int) })
+	assertSlicesEqual(t, []interface{}{1, 4, 9, 16, 25}, squares.Slice())
+	assert.Equal(t, 5, squares.Size())
+
+	odds := ll.Filter(func(d interface{}) bool { return d.(int)%2 == 1 })
+	assertSlicesEqual(t, []interface{}{1, 3, 5}, odds.Slice())
+	assert.Equal(t, 3, odds.Size())
+
+	sum := ll.Reduce(0, func(acc, d interface{}) interface{} { return acc.(int) + d.(int) })
+	assert.Equal(t, 15, sum)
+
+	concatenated := ll.Reduce("", func(acc, d interface{}) interface{} { return fmt.Sprintf("%s%d", acc, d) })
+	assert.Equal(t, "12345", concatenated)
+
+	// source list stays intact
+	assertSlicesEqual(t, []interface{}{1, 2, 3, 4, 5}, ll.Slice())
+
+	empty := linkedlist.New(false)
+	assert.True(t, empty.Map(func(d interface{}) interface{} { return d }).Empty())
+	assert.Equal(t, 7, empty.Reduce(7, func(acc, d interface{}) interface{} { return nil }))
+}
+
+func TestLinkedList_ForEachAnyAll(t *testing.T) {
+	ll := seedList(false, "a", "b", "c")
+
+	var visited []interface{}
+	ll.ForEach(func(i int, d interface{}) {
+		assert.Equal(t, len(visited), i)
+		visited = append(visited, d)
+	})
+	assertSlicesEqual(t, []interface{}{"a", "b", "c"}, visited)
+
+	assert.True(t, ll.Any(func(d interface{}) bool { return d == "b" }))
+	assert.False(t, ll.Any(func(d interface{}) bool { return d == "z" }))
+	assert.True(t, ll.All(func(d interface{}) bool { return len(d.(string)) == 1 }))
+	assert.False(t, ll.All(func(d interface{}) bool { return d != "c" }))
+
+	empty := linkedlist.New(false)
+	assert.False(t, empty.Any(func(interface{}) bool { return true }))
+	assert.True(t, empty.All(func(interface{}) bool { return false }))
+}
+
+func TestLinkedList_CloneEqual(t *testing.T) {
+	ll := seedList(true, 1, 2, 3)
+	clone := ll.Clone()
+
+	assert.True(t, ll.Equal(clone, intsEqual))
+	assert.True(t, clone.Equal(ll, intsEqual))
+
+	clone.Append(4)
+	assert.False(t, ll.Equal(clone, intsEqual))
+	assert.Equal(t, 3, ll.Size())
+
+	other := seedList(false, 1, 2, 5)
+	assert.False(t, ll.Equal(other, intsEqual))
+	assert.True(t, ll.Equal(ll, intsEqual))
+	assert.True(t, linkedlist.New(false).Equal(linkedlist.New(true), intsEqual))
+}
+
+func TestLinkedList_Concat(t *testing.T) {
+	a := seedList(true, 1, 2)
+	b := seedList(true, 3, 4, 5)
+
+	c := a.Concat(b)
+	assertSlicesEqual(t, []interface{}{1, 2, 3, 4, 5}, c.Slice())
+	assert.Equal(t, 5, c.Size())
+	assert.Equal(t, 2, a.Size())
+	assert.Equal(t, 3, b.Size())
+
+	self := a.Concat(a)
+	assertSlicesEqual(t, []interface{}{1, 2, 1, 2}, self.Slice())
+}
+
+func TestLinkedList_Split(t *testing.T) {
+	tt := []struct {
+		at   int
+		head []interface{}
+		tail []interface{}
+	}{
+		{at: 0, head: []interface{}{}, tail: []interface{}{1, 2, 3, 4}},
+		{at: 1, head: []interface{}{1}, tail: []interface{}{2, 3, 4}},
+		{at: 3, head: []interface{}{1, 2, 3}, tail: []interface{}{4}},
+		{at: 4, head: []interface{}{1, 2, 3, 4}, tail: []interface{}{}},
+	}
+
+	for _, tc := range tt {
+		t.Run(fmt.Sprintf("split at %d", tc.at), func(t *testing.T) {
+			ll := seedList(false, 1, 2, 3, 4)
+			tail, err := ll.Split(tc.at)
+			require.NoError(t, err)
+
+			assertSlicesEqual(t, tc.head, ll.Slice())
+			assertSlicesEqual(t, tc.tail, tail.Slice())
+			assert.Equal(t, len(tc.head), ll.Size())
+			assert.Equal(t, len(tc.tail), tail.Size())
+		})
+	}
+
+	t.Run("out of range", func(t *testing.T) {
+		ll := seedList(false, 1, 2)
+		_, err := ll.Split(3)
+		assert.ErrorIs(t, err, linkedlist.ErrIndexOutOfRange)
+		_, err = ll.Split(-1)
+		assert.ErrorIs(t, err, linkedlist.ErrIndexOutOfRange)
+	})
+}
+
+func TestLinkedList_Splice(t *testing.T) {
+	tt := []struct {
+		at  int
+		exp []interface{}
+	}{
+		{at: 0, exp: []interface{}{"x", "y", "a", "b", "c"}},
+		{at: 1, exp: []interface{}{"a", "x", "y", "b", "c"}},
+		{at: 3, exp: []interface{}{"a", "b", "c", "x", "y"}},
+	}
+
+	for _, tc := range tt {
+		t.Run(fmt.Sprintf("splice at %d", tc.at), func(t *testing.T) {
+			ll := seedList(true, "a", "b", "c")
+			other := seedList(true, "x", "y")
+
+			require.NoError(t, ll.Splice(tc.at, other))
+			assertSlicesEqual(t, tc.exp, ll.Slice())
+			assert.Equal(t, 5, ll.Size())
+			assert.True(t, other.Empty())
+			assertSlicesEqual(t, []interface{}{}, other.Slice())
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		ll := seedList(false, 1)
+		assert.ErrorIs(t, ll.Splice(2, seedList(false, 2)), linkedlist.ErrIndexOutOfRange)
+		assert.Error(t, ll.Splice(0, ll))
+		require.NoError(t, ll.Splice(1, linkedlist.New(false)))
+		assertSlicesEqual(t, []interface{}{1}, ll.Slice())
+	})
+
+	t.Run("concurrent splices in both directions do not deadlock", func(t *testing.T) {
+		a := seedList(true, 1)
+		b := seedList(true, 2)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				_ = a.Splice(0, b)
+				_ = a.Equal(b, intsEqual)
+			}()
+			go func() {
+				defer wg.Done()
+				_ = b.Splice(0, a)
+				_ = b.Concat(a)
+			}()
+		}
+
+		wg.Wait()
+		assert.Equal(t, 2, a.Size()+b.Size())
+	})
+}
@@ -0,0 +1,80 @@
+package linkedlist_test
+
+import (
+	"github.com/denismitr/gds/linkedlist"
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"testing"
+)
+
+func TestLinkedList_Typed(t *testing.T) {
+	t.Run("typed values without assertions", func(t *testing.T) {
+		ll := linkedlist.NewOf[int](true)
+		assert.True(t, ll.Empty())
+
+		for _, n := range []int{3, 1, 2} {
+			ll.Append(n)
+		}
+
+		n, ok := ll.PeakAt(1)
+		assert.True(t, ok)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, []int{3, 1, 2}, ll.Slice())
+
+		ll.Reverse()
+		assert.Equal(t, []int{2, 1, 3}, ll.Slice())
+
+		ll.Sort(func(a, b int) bool { return a < b })
+		assert.Equal(t, []int{1, 2, 3}, ll.Slice())
+
+		head, ok := ll.Pop()
+		assert.True(t, ok)
+		assert.Equal(t, 1, head)
+		assert.Equal(t, 2, ll.Size())
+	})
+
+	t.Run("zero values on empty list", func(t *testing.T) {
+		ll := linkedlist.NewOf[string](false)
+
+		s, ok := ll.Pop()
+		assert.False(t, ok)
+		assert.Equal(t, "", s)
+
+		s, ok = ll.PeakAt(0)
+		assert.False(t, ok)
+		assert.Equal(t, "", s)
+	})
+
+	t.Run("map to another type and fold", func(t *testing.T) {
+		ll := linkedlist.NewOf[int](false)
+		for i := 1; i <= 4; i++ {
+			ll.Append(i)
+		}
+
+		strs := linkedlist.MapTo(ll, strconv.Itoa)
+		assert.Equal(t, []string{"1", "2", "3", "4"}, strs.Slice())
+
+		joined := linkedlist.Fold(ll, "", func(acc string, n int) string {
+			return acc + strconv.Itoa(n)
+		})
+		assert.Equal(t, "1234", joined)
+
+		sum := ll.Reduce(0, func(acc, n int) int { return acc + n })
+		assert.Equal(t, 10, sum)
+	})
+
+	t.Run("iterator yields typed values", func(t *testing.T) {
+		ll := linkedlist.NewOf[float64](false)
+		ll.Append(1.5)
+		ll.Append(2.5)
+
+		total := 0.0
+		it := ll.Iterator()
+		for it.Next() {
+			total += it.Value()
+		}
+
+		assert.NoError(t, it.Err())
+		assert.Equal(t, 4.0, total)
+	})
+}
@@ -0,0 +1,236 @@
+package linkedlist
+
+import "github.com/pkg/errors"
+
+var ErrConcurrentModification = errors.New("linked list was modified during iteration")
+var ErrNoCurrentElement = errors.New("cursor is not positioned at an element")
+
+// Iterator walks the linked list from head to tail without copying it.
+// Iteration is fail-fast: if the list gets modified after the iterator
+// was created, Next returns false and Err reports ErrConcurrentModification.
+type Iterator[T any] struct {
+	ll      *LinkedList[T]
+	next    *Node[T]
+	data    T
+	version uint64
+	err     error
+}
+
+// Iterator returns a forward iterator positioned before the head
+func (ll *LinkedList[T]) Iterator() *Iterator[T] {
+	ll.locker.ReadLock()
+	defer ll.locker.ReadUnlock()
+
+	return &Iterator[T]{ll: ll, next: ll.head, version: ll.version}
+}
+
+// Next advances the iterator and reports whether there is a value to read
+func (it *Iterator[T]) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	it.ll.locker.ReadLock()
+	defer it.ll.locker.ReadUnlock()
+
+	if it.version != it.ll.version {
+		it.err = ErrConcurrentModification
+		return false
+	}
+
+	if it.next == nil {
+		return false
+	}
+
+	it.data = it.next.data
+	it.next = it.next.next
+	return true
+}
+
+// Value returns data at the current iterator position
+func (it *Iterator[T]) Value() T {
+	return it.data
+}
+
+// Err returns ErrConcurrentModification if the iteration was interrupted
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// Cursor is a mutable iterator that can modify the list at its position.
+// Changes made through the cursor do not invalidate it, any other change
+// to the list does and makes every subsequent call fail with ErrConcurrentModification.
+type Cursor[T any] struct {
+	ll      *LinkedList[T]
+	prev    *Node[T]
+	curr    *Node[T]
+	index   int
+	version uint64
+	err     error
+}
+
+// Cursor returns a cursor positioned before the head
+func (ll *LinkedList[T]) Cursor() *Cursor[T] {
+	ll.locker.ReadLock()
+	defer ll.locker.ReadUnlock()
+
+	return &Cursor[T]{ll: ll, index: -1, version: ll.version}
+}
+
+// Next moves the cursor to the following element
+func (c *Cursor[T]) Next() bool {
+	if c.err != nil {
+		return false
+	}
+
+	c.ll.locker.ReadLock()
+	defer c.ll.locker.ReadUnlock()
+
+	if !c.valid() {
+		return false
+	}
+
+	if c.curr != nil {
+		c.prev = c.curr
+	}
+
+	if c.prev == nil {
+		c.curr = c.ll.head
+	} else {
+		c.curr = c.prev.next
+	}
+
+	if c.curr == nil {
+		return false
+	}
+
+	c.index++
+	return true
+}
+
+// Value returns data at the cursor position
+func (c *Cursor[T]) Value() T {
+	if c.curr == nil {
+		var zero T
+		return zero
+	}
+
+	return c.curr.data
+}
+
+// Index of the element under the cursor
+func (c *Cursor[T]) Index() int {
+	return c.index
+}
+
+// Set replaces data at the cursor position
+func (c *Cursor[T]) Set(data T) error {
+	c.ll.locker.WriteLock()
+	defer c.ll.locker.WriteUnlock()
+
+	if err := c.checkCurrent(); err != nil {
+		return err
+	}
+
+	c.curr.data = data
+	return nil
+}
+
+// InsertBefore inserts data in front of the current element,
+// the cursor stays at the same element
+func (c *Cursor[T]) InsertBefore(data T) error {
+	c.ll.locker.WriteLock()
+	defer c.ll.locker.WriteUnlock()
+
+	if err := c.checkCurrent(); err != nil {
+		return err
+	}
+
+	newNode := &Node[T]{data: data, next: c.curr}
+	if c.prev == nil {
+		c.ll.head = newNode
+	} else {
+		c.prev.next = newNode
+	}
+
+	c.prev = newNode
+	c.index++
+	c.modified(1)
+	return nil
+}
+
+// InsertAfter inserts data right after the current element,
+// so it will be the next one visited by the cursor
+func (c *Cursor[T]) InsertAfter(data T) error {
+	c.ll.locker.WriteLock()
+	defer c.ll.locker.WriteUnlock()
+
+	if err := c.checkCurrent(); err != nil {
+		return err
+	}
+
+	c.curr.next = &Node[T]{data: data, next: c.curr.next}
+	c.modified(1)
+	return nil
+}
+
+// Remove deletes the current element and returns its data,
+// after that the cursor points between the neighbours of the removed element
+// and Next moves it to the element that followed the removed one
+func (c *Cursor[T]) Remove() (T, error) {
+	c.ll.locker.WriteLock()
+	defer c.ll.locker.WriteUnlock()
+
+	if err := c.checkCurrent(); err != nil {
+		var zero T
+		return zero, err
+	}
+
+	removed := c.curr
+	if c.prev == nil {
+		c.ll.head = removed.next
+	} else {
+		c.prev.next = removed.next
+	}
+
+	c.curr = nil
+	c.index--
+	c.modified(-1)
+	return removed.data, nil
+}
+
+// Err returns ErrConcurrentModification if the cursor was invalidated
+func (c *Cursor[T]) Err() error {
+	return c.err
+}
+
+func (c *Cursor[T]) valid() bool {
+	if c.version != c.ll.version {
+		c.err = ErrConcurrentModification
+		return false
+	}
+
+	return true
+}
+
+func (c *Cursor[T]) checkCurrent() error {
+	if c.err != nil {
+		return c.err
+	}
+
+	if !c.valid() {
+		return c.err
+	}
+
+	if c.curr == nil {
+		return ErrNoCurrentElement
+	}
+
+	return nil
+}
+
+func (c *Cursor[T]) modified(sizeDelta int) {
+	c.ll.size += sizeDelta
+	c.ll.version++
+	c.version = c.ll.version
+}
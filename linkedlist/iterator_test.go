@@ -0,0 +1,199 @@
+package linkedlist_test
+
+import (
+	"github.com/denismitr/gds/linkedlist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+)
+
+func TestLinkedList_Iterator(t *testing.T) {
+	t.Run("it walks all the items in order", func(t *testing.T) {
+		ll := linkedlist.New(false)
+		seed := []interface{}{1, 2, 3, 4, 5}
+		for _, d := range seed {
+			ll.Append(d)
+		}
+
+		var items []interface{}
+		it := ll.Iterator()
+		for it.Next() {
+			items = append(items, it.Value())
+		}
+
+		require.NoError(t, it.Err())
+		assertSlicesEqual(t, seed, items)
+	})
+
+	t.Run("empty list", func(t *testing.T) {
+		it := linkedlist.New(true).Iterator()
+		assert.False(t, it.Next())
+		assert.NoError(t, it.Err())
+	})
+
+	t.Run("it fails fast on modification", func(t *testing.T) {
+		ll := linkedlist.New(true)
+		ll.Append("a")
+		ll.Append("b")
+		ll.Append("c")
+
+		it := ll.Iterator()
+		require.True(t, it.Next())
+		assert.Equal(t, "a", it.Value())
+
+		ll.Append("d")
+
+		assert.False(t, it.Next())
+		assert.ErrorIs(t, it.Err(), linkedlist.ErrConcurrentModification)
+		assert.False(t, it.Next())
+	})
+
+	t.Run("iterating concurrently with writers never crashes", func(t *testing.T) {
+		ll := linkedlist.New(true)
+		for i := 0; i < 100; i++ {
+			ll.Append(i)
+		}
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				ll.Append(i)
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				it := ll.Iterator()
+				prev := -1
+				for it.Next() {
+					n := it.Value().(int)
+					if prev >= 0 && n != prev+1 && n != 0 {
+						t.Errorf("unexpected value %d after %d", n, prev)
+					}
+					prev = n
+				}
+
+				if it.Err() != nil {
+					assert.ErrorIs(t, it.Err(), linkedlist.ErrConcurrentModification)
+				}
+			}
+		}()
+
+		wg.Wait()
+	})
+}
+
+func TestLinkedList_Cursor(t *testing.T) {
+	t.Run("remove every even number", func(t *testing.T) {
+		ll := linkedlist.New(true)
+		for i := 1; i <= 10; i++ {
+			ll.Append(i)
+		}
+
+		c := ll.Cursor()
+		for c.Next() {
+			if c.Value().(int)%2 == 0 {
+				d, err := c.Remove()
+				require.NoError(t, err)
+				assert.Equal(t, 0, d.(int)%2)
+			}
+		}
+
+		require.NoError(t, c.Err())
+		assert.Equal(t, 5, ll.Size())
+		assertSlicesEqual(t, []interface{}{1, 3, 5, 7, 9}, ll.Slice())
+	})
+
+	t.Run("remove head and tail", func(t *testing.T) {
+		ll := linkedlist.New(false)
+		ll.Append("a")
+		ll.Append("b")
+		ll.Append("c")
+
+		c := ll.Cursor()
+		require.True(t, c.Next())
+		_, err := c.Remove()
+		require.NoError(t, err)
+
+		require.True(t, c.Next())
+		assert.Equal(t, "b", c.Value())
+		assert.Equal(t, 0, c.Index())
+
+		require.True(t, c.Next())
+		_, err = c.Remove()
+		require.NoError(t, err)
+		assert.False(t, c.Next())
+
+		assertSlicesEqual(t, []interface{}{"b"}, ll.Slice())
+		assert.Equal(t, 1, ll.Size())
+	})
+
+	t.Run("insert before and after", func(t *testing.T) {
+		ll := linkedlist.New(false)
+		ll.Append(2)
+		ll.Append(4)
+
+		c := ll.Cursor()
+		var visited []interface{}
+		for c.Next() {
+			visited = append(visited, c.Value())
+			switch c.Value() {
+			case 2:
+				require.NoError(t, c.InsertBefore(1))
+				require.NoError(t, c.InsertAfter(3))
+				assert.Equal(t, 1, c.Index())
+			case 4:
+				require.NoError(t, c.Set(40))
+			}
+		}
+
+		require.NoError(t, c.Err())
+		assertSlicesEqual(t, []interface{}{2, 3, 4}, visited)
+		assertSlicesEqual(t, []interface{}{1, 2, 3, 40}, ll.Slice())
+		assert.Equal(t, 4, ll.Size())
+
+		d, ok := ll.PeakAt(3)
+		assert.True(t, ok)
+		assert.Equal(t, 40, d)
+	})
+
+	t.Run("cursor without current element", func(t *testing.T) {
+		ll := linkedlist.New(false)
+		ll.Append(1)
+
+		c := ll.Cursor()
+		assert.ErrorIs(t, c.Set(2), linkedlist.ErrNoCurrentElement)
+		assert.ErrorIs(t, c.InsertAfter(2), linkedlist.ErrNoCurrentElement)
+
+		require.True(t, c.Next())
+		_, err := c.Remove()
+		require.NoError(t, err)
+
+		_, err = c.Remove()
+		assert.ErrorIs(t, err, linkedlist.ErrNoCurrentElement)
+		assert.True(t, ll.Empty())
+	})
+
+	t.Run("cursor is invalidated by foreign modification", func(t *testing.T) {
+		ll := linkedlist.New(true)
+		ll.Append(1)
+		ll.Append(2)
+
+		c := ll.Cursor()
+		require.True(t, c.Next())
+
+		_, ok := ll.Pop()
+		require.True(t, ok)
+
+		_, err := c.Remove()
+		assert.ErrorIs(t, err, linkedlist.ErrConcurrentModification)
+		assert.False(t, c.Next())
+		assert.ErrorIs(t, c.Err(), linkedlist.ErrConcurrentModification)
+		assertSlicesEqual(t, []interface{}{2}, ll.Slice())
+	})
+}
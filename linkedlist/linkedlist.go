@@ -1,22 +1,51 @@
 package linkedlist
 
-import "github.com/denismitr/gds/internal/utils"
-
-type Node struct {
-	data interface{}
-	next *Node
+import (
+	"github.com/denismitr/gds/internal/utils"
+	"sync/atomic"
+)
+
+// lastID is used to give every list an identity
+// that defines the order of locking when two lists are involved
+var lastID uint64
+
+type Node[T any] struct {
+	data T
+	next *Node[T]
 }
 
-type LinkedList struct {
+type LinkedList[T any] struct {
+	id     uint64
+	locks  bool
 	locker utils.Locker
-	head *Node
-	size int
+	head   *Node[T]
+	size   int
+
+	// version is bumped on every structural modification,
+	// iterators and cursors use it to detect concurrent changes
+	version uint64
+
+	// codec encodes elements for MarshalBinary and GobEncode, JSONCodec is used when it is nil
+	codec Codec[T]
+}
+
+// Untyped is the original linked list of interface{} values. The name LinkedList now needs
+// a type argument, so code that refers to *LinkedList has to switch to *Untyped or *LinkedList[T],
+// New and the methods of the list are unchanged.
+type Untyped = LinkedList[interface{}]
+
+// New creates an untyped linked list
+func New(locks bool) *Untyped {
+	return NewOf[interface{}](locks)
 }
 
-func New(locks bool) *LinkedList {
-	ll := &LinkedList{
-		size: 0,
-		head: nil,
+// NewOf creates a linked list of T values
+func NewOf[T any](locks bool) *LinkedList[T] {
+	ll := &LinkedList[T]{
+		id:    atomic.AddUint64(&lastID, 1),
+		locks: locks,
+		size:  0,
+		head:  nil,
 	}
 
 	if locks {
@@ -29,15 +58,16 @@ func New(locks bool) *LinkedList {
 }
 
 // Append any data to linked list and returns an index
-func (ll *LinkedList) Append(data interface{}) int {
+func (ll *LinkedList[T]) Append(data T) int {
 	ll.locker.WriteLock()
 	defer ll.locker.WriteUnlock()
 
-	newNode := &Node{data: data, next: nil}
+	newNode := &Node[T]{data: data, next: nil}
 
 	if ll.head == nil {
 		ll.head = newNode
 		ll.size = 1
+		ll.version++
 		return 0
 	}
 
@@ -48,17 +78,19 @@ func (ll *LinkedList) Append(data interface{}) int {
 
 	curr.next = newNode
 	ll.size++
+	ll.version++
 	return ll.size - 1
 }
 
-// Slice returns data stored at linked list nodes as a slice
-func (ll *LinkedList) Slice() []interface{} {
+// Slice returns data stored at linked list nodes as a slice,
+// it never visits more than Size nodes, so a corrupted cyclic list can not make it loop forever
+func (ll *LinkedList[T]) Slice() []T {
 	ll.locker.ReadLock()
 	defer ll.locker.ReadUnlock()
 
-	result := make([]interface{}, 0, ll.size)
+	result := make([]T, 0, ll.size)
 	curr := ll.head
-	for curr != nil {
+	for curr != nil && len(result) < ll.size {
 		result = append(result, curr.data)
 		curr = curr.next
 	}
@@ -66,7 +98,7 @@ func (ll *LinkedList) Slice() []interface{} {
 }
 
 // Reverse the linked list
-func (ll *LinkedList) Reverse() {
+func (ll *LinkedList[T]) Reverse() {
 	ll.locker.WriteLock()
 	defer ll.locker.WriteUnlock()
 
@@ -75,8 +107,8 @@ func (ll *LinkedList) Reverse() {
 	}
 
 	curr := ll.head
-	var prev *Node
-	var next *Node
+	var prev *Node[T]
+	var next *Node[T]
 
 	for curr != nil {
 		next = curr.next
@@ -86,30 +118,34 @@ func (ll *LinkedList) Reverse() {
 	}
 
 	ll.head = prev
+	ll.version++
 }
 
 // Pop takes data at head, returns it and sets head to head.next
-func (ll *LinkedList) Pop() (interface{}, bool) {
+func (ll *LinkedList[T]) Pop() (T, bool) {
 	ll.locker.WriteLock()
 	defer ll.locker.WriteUnlock()
 
 	if ll.head == nil {
-		return nil, false
+		var zero T
+		return zero, false
 	}
 
 	n := ll.head
 	ll.head = ll.head.next
 	ll.size -= 1
+	ll.version++
 
 	return n.data, true
 }
 
-func (ll *LinkedList) PeakAt(index int) (interface{}, bool) {
+func (ll *LinkedList[T]) PeakAt(index int) (T, bool) {
 	ll.locker.ReadLock()
 	defer ll.locker.ReadUnlock()
 
-	if ll.head == nil || index > ll.size - 1 {
-		return nil, false
+	if ll.head == nil || index > ll.size-1 {
+		var zero T
+		return zero, false
 	}
 
 	curr := ll.head
@@ -121,14 +157,14 @@ func (ll *LinkedList) PeakAt(index int) (interface{}, bool) {
 }
 
 // Size of the linked list
-func (ll *LinkedList) Size() int {
+func (ll *LinkedList[T]) Size() int {
 	ll.locker.ReadLock()
 	defer ll.locker.ReadUnlock()
 	return ll.size
 }
 
-func (ll *LinkedList) Empty() bool {
+func (ll *LinkedList[T]) Empty() bool {
 	ll.locker.ReadLock()
 	defer ll.locker.ReadUnlock()
 	return ll.size == 0
-}
\ No newline at end of file
+}
@@ -0,0 +1,142 @@
+package linkedlist
+
+// Persistent is an immutable singly linked list. Operations never modify
+// an existing list, they return a new one that shares as many nodes as possible
+// with the original, so persistent lists can be passed between goroutines without locks.
+// A nil *Persistent is a valid empty list.
+type Persistent[T any] struct {
+	head T
+	tail *Persistent[T]
+	size int
+}
+
+// PersistentOf builds a persistent list with the items in the same order
+func PersistentOf[T any](items ...T) *Persistent[T] {
+	var l *Persistent[T]
+	for i := len(items) - 1; i >= 0; i-- {
+		l = l.Prepend(items[i])
+	}
+
+	return l
+}
+
+// Persistent returns an immutable snapshot of the list
+func (ll *LinkedList[T]) Persistent() *Persistent[T] {
+	ll.locker.ReadLock()
+	defer ll.locker.ReadUnlock()
+
+	items := make([]T, 0, ll.size)
+	for curr := ll.head; curr != nil && len(items) < ll.size; curr = curr.next {
+		items = append(items, curr.data)
+	}
+
+	return PersistentOf(items...)
+}
+
+// Prepend returns a new list with data in front, the original list becomes its tail
+func (l *Persistent[T]) Prepend(data T) *Persistent[T] {
+	return &Persistent[T]{head: data, tail: l, size: l.Size() + 1}
+}
+
+// Head returns the first element
+func (l *Persistent[T]) Head() (T, bool) {
+	if l == nil {
+		var zero T
+		return zero, false
+	}
+
+	return l.head, true
+}
+
+// Tail returns the list without its first element, it costs O(1) and shares all the nodes
+func (l *Persistent[T]) Tail() *Persistent[T] {
+	if l == nil {
+		return nil
+	}
+
+	return l.tail
+}
+
+func (l *Persistent[T]) Size() int {
+	if l == nil {
+		return 0
+	}
+
+	return l.size
+}
+
+func (l *Persistent[T]) Empty() bool {
+	return l == nil
+}
+
+// Reverse returns a new list in the reverse order
+func (l *Persistent[T]) Reverse() *Persistent[T] {
+	var result *Persistent[T]
+	for curr := l; curr != nil; curr = curr.tail {
+		result = result.Prepend(curr.head)
+	}
+
+	return result
+}
+
+// Map returns a new list with fn applied to every element, no nodes can be shared
+func (l *Persistent[T]) Map(fn func(data T) T) *Persistent[T] {
+	items := make([]T, 0, l.Size())
+	for curr := l; curr != nil; curr = curr.tail {
+		items = append(items, fn(curr.head))
+	}
+
+	return PersistentOf(items...)
+}
+
+// Filter returns a new list with the elements that satisfy the predicate,
+// the suffix after the last rejected element is shared with the original list
+func (l *Persistent[T]) Filter(predicate func(data T) bool) *Persistent[T] {
+	var kept, pending []T
+	shared := l
+	for curr := l; curr != nil; curr = curr.tail {
+		if predicate(curr.head) {
+			pending = append(pending, curr.head)
+		} else {
+			kept = append(kept, pending...)
+			pending = pending[:0]
+			shared = curr.tail
+		}
+	}
+
+	result := shared
+	for i := len(kept) - 1; i >= 0; i-- {
+		result = result.Prepend(kept[i])
+	}
+
+	return result
+}
+
+// ForEach calls fn for every element from head to tail
+func (l *Persistent[T]) ForEach(fn func(index int, data T)) {
+	i := 0
+	for curr := l; curr != nil; curr = curr.tail {
+		fn(i, curr.head)
+		i++
+	}
+}
+
+// Slice returns the elements as a slice
+func (l *Persistent[T]) Slice() []T {
+	result := make([]T, 0, l.Size())
+	for curr := l; curr != nil; curr = curr.tail {
+		result = append(result, curr.head)
+	}
+
+	return result
+}
+
+// LinkedList copies the elements into a new mutable list
+func (l *Persistent[T]) LinkedList(locks bool) *LinkedList[T] {
+	var b builder[T]
+	for curr := l; curr != nil; curr = curr.tail {
+		b.append(curr.head)
+	}
+
+	return b.build(locks)
+}
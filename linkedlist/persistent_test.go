@@ -0,0 +1,105 @@
+package linkedlist_test
+
+import (
+	"github.com/denismitr/gds/linkedlist"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+)
+
+func TestPersistent_Basics(t *testing.T) {
+	var empty *linkedlist.Persistent[int]
+	assert.True(t, empty.Empty())
+	assert.Equal(t, 0, empty.Size())
+	assert.Nil(t, empty.Tail())
+	_, ok := empty.Head()
+	assert.False(t, ok)
+	assert.Equal(t, []int{}, empty.Slice())
+
+	l := linkedlist.PersistentOf(1, 2, 3)
+	assert.Equal(t, 3, l.Size())
+	assert.Equal(t, []int{1, 2, 3}, l.Slice())
+
+	head, ok := l.Head()
+	assert.True(t, ok)
+	assert.Equal(t, 1, head)
+
+	l0 := l.Prepend(0)
+	assert.Equal(t, []int{0, 1, 2, 3}, l0.Slice())
+	assert.Equal(t, []int{1, 2, 3}, l.Slice(), "original list is not modified")
+	assert.Same(t, l, l0.Tail(), "prepend shares the whole original list")
+	assert.Same(t, l.Tail(), l0.Tail().Tail())
+
+	reversed := l.Reverse()
+	assert.Equal(t, []int{3, 2, 1}, reversed.Slice())
+	assert.Equal(t, []int{1, 2, 3}, l.Slice())
+
+	doubled := l.Map(func(n int) int { return n * 2 })
+	assert.Equal(t, []int{2, 4, 6}, doubled.Slice())
+
+	var visited []int
+	l.ForEach(func(i, n int) {
+		assert.Equal(t, len(visited), i)
+		visited = append(visited, n)
+	})
+	assert.Equal(t, []int{1, 2, 3}, visited)
+}
+
+func TestPersistent_FilterSharesSuffix(t *testing.T) {
+	l := linkedlist.PersistentOf(1, 2, 3, 4, 6, 8)
+	even := l.Filter(func(n int) bool { return n%2 == 0 })
+
+	assert.Equal(t, []int{2, 4, 6, 8}, even.Slice())
+	assert.Equal(t, 4, even.Size())
+
+	// 4, 6, 8 follow the last rejected element 3, so they are shared
+	suffix := l.Tail().Tail().Tail()
+	assert.Same(t, suffix, even.Tail())
+
+	all := l.Filter(func(int) bool { return true })
+	assert.Same(t, l, all)
+
+	none := l.Filter(func(int) bool { return false })
+	assert.True(t, none.Empty())
+}
+
+func TestPersistent_Conversions(t *testing.T) {
+	ll := linkedlist.NewOf[string](true)
+	ll.Append("a")
+	ll.Append("b")
+
+	snapshot := ll.Persistent()
+	ll.Append("c")
+
+	assert.Equal(t, []string{"a", "b"}, snapshot.Slice())
+
+	back := snapshot.Prepend("z").LinkedList(true)
+	assert.Equal(t, []string{"z", "a", "b"}, back.Slice())
+	assert.Equal(t, 3, back.Size())
+
+	back.Append("y")
+	assert.Equal(t, []string{"a", "b"}, snapshot.Slice())
+}
+
+func TestPersistent_SharedBetweenGoroutines(t *testing.T) {
+	base := linkedlist.PersistentOf(1, 2, 3)
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			l := base
+			for i := 0; i < 100; i++ {
+				l = l.Prepend(10 + w)
+			}
+
+			assert.Equal(t, 103, l.Size())
+			assert.Same(t, base, l.Filter(func(n int) bool { return n < 10 }))
+			assert.Equal(t, []int{3, 2, 1}, base.Reverse().Slice())
+		}(w)
+	}
+
+	wg.Wait()
+	assert.Equal(t, []int{1, 2, 3}, base.Slice())
+}
@@ -0,0 +1,120 @@
+package linkedlist
+
+// LessFunc reports whether a must be placed before b,
+// same contract as Less of sorting.Sortable but for element values
+type LessFunc[T any] func(a, b T) bool
+
+// Sort sorts the list in place by relinking its nodes, it needs O(n log n)
+// comparisons and O(1) extra space. The order of equal elements is not
+// part of the contract, use StableSort when it matters.
+func (ll *LinkedList[T]) Sort(less LessFunc[T]) {
+	ll.StableSort(less)
+}
+
+// StableSort sorts the list in place with bottom-up merge sort
+// keeping the original order of equal elements
+func (ll *LinkedList[T]) StableSort(less LessFunc[T]) {
+	ll.locker.WriteLock()
+	defer ll.locker.WriteUnlock()
+
+	if ll.size < 2 {
+		return
+	}
+
+	for width := 1; width < ll.size; width *= 2 {
+		var head, tail *Node[T]
+
+		curr := ll.head
+		for curr != nil {
+			left := curr
+			right := cut(left, width)
+			curr = cut(right, width)
+
+			first, last := merge(left, right, less)
+			if tail == nil {
+				head = first
+			} else {
+				tail.next = first
+			}
+			tail = last
+		}
+
+		ll.head = head
+	}
+
+	ll.version++
+}
+
+// MergeSorted merges two lists, each sorted according to less, into a new sorted list.
+// The nodes are moved rather than copied, so both a and b are empty afterwards.
+// The new list uses the same locking mode as a.
+func MergeSorted[T any](a, b *LinkedList[T], less LessFunc[T]) *LinkedList[T] {
+	result := NewOf[T](a.locks)
+	if a == b {
+		a.locker.WriteLock()
+		defer a.locker.WriteUnlock()
+
+		result.head, result.size = a.head, a.size
+		a.head, a.size = nil, 0
+		a.version++
+		return result
+	}
+
+	unlock := writeLockBoth(a, b)
+	defer unlock()
+
+	result.head, _ = merge(a.head, b.head, less)
+	result.size = a.size + b.size
+
+	a.head, a.size = nil, 0
+	a.version++
+	b.head, b.size = nil, 0
+	b.version++
+
+	return result
+}
+
+// cut detaches the chain after n nodes and returns the head of the detached part
+func cut[T any](head *Node[T], n int) *Node[T] {
+	for i := 1; head != nil && i < n; i++ {
+		head = head.next
+	}
+
+	if head == nil {
+		return nil
+	}
+
+	rest := head.next
+	head.next = nil
+	return rest
+}
+
+// merge links two sorted chains together and returns the first and the last node,
+// on equal elements the node from the left chain goes first
+func merge[T any](left, right *Node[T], less LessFunc[T]) (*Node[T], *Node[T]) {
+	var dummy Node[T]
+	tail := &dummy
+
+	for left != nil && right != nil {
+		if less(right.data, left.data) {
+			tail.next = right
+			right = right.next
+		} else {
+			tail.next = left
+			left = left.next
+		}
+		tail = tail.next
+	}
+
+	if left != nil {
+		tail.next = left
+	} else {
+		tail.next = right
+	}
+
+	for tail.next != nil {
+		tail = tail.next
+	}
+
+	return dummy.next, tail
+}
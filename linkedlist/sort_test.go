@@ -0,0 +1,137 @@
+package linkedlist_test
+
+import (
+	"fmt"
+	"github.com/denismitr/gds/linkedlist"
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+type pair struct {
+	key   int
+	order int
+}
+
+func intsLess(a, b interface{}) bool {
+	return a.(int) < b.(int)
+}
+
+func pairsLess(a, b interface{}) bool {
+	return a.(pair).key < b.(pair).key
+}
+
+func TestLinkedList_Sort(t *testing.T) {
+	tt := []struct {
+		in  []interface{}
+		exp []interface{}
+	}{
+		{in: []interface{}{}, exp: []interface{}{}},
+		{in: []interface{}{1}, exp: []interface{}{1}},
+		{in: []interface{}{2, 1}, exp: []interface{}{1, 2}},
+		{in: []interface{}{2, 3, 4, 1, 9}, exp: []interface{}{1, 2, 3, 4, 9}},
+		{in: []interface{}{2, 3, 4, 1, 9, 89, -876, 414, 0}, exp: []interface{}{-876, 0, 1, 2, 3, 4, 9, 89, 414}},
+		{in: []interface{}{5, 5, 5, 1, 1}, exp: []interface{}{1, 1, 5, 5, 5}},
+	}
+
+	for i, tc := range tt {
+		t.Run(fmt.Sprintf("Case %d", i), func(t *testing.T) {
+			ll := seedList(true, tc.in...)
+			ll.Sort(intsLess)
+
+			assertSlicesEqual(t, tc.exp, ll.Slice())
+			assert.Equal(t, len(tc.exp), ll.Size())
+		})
+	}
+}
+
+func TestLinkedList_SortRandomized(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+
+	for _, size := range []int{3, 17, 128, 1000, 20000} {
+		t.Run(fmt.Sprintf("stable sort of %d items", size), func(t *testing.T) {
+			ll := linkedlist.New(false)
+			expected := make([]pair, size)
+			for i := 0; i < size; i++ {
+				p := pair{key: rnd.Intn(size/3 + 1), order: i}
+				expected[i] = p
+				ll.Append(p)
+			}
+
+			sort.SliceStable(expected, func(i, j int) bool {
+				return expected[i].key < expected[j].key
+			})
+
+			ll.StableSort(pairsLess)
+
+			items := ll.Slice()
+			assert.Equal(t, size, ll.Size())
+			assert.Len(t, items, size)
+			for i := range expected {
+				if expected[i] != items[i].(pair) {
+					t.Fatalf("expected %+v at index %d, got %+v", expected[i], i, items[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMergeSorted(t *testing.T) {
+	t.Run("two sorted lists", func(t *testing.T) {
+		a := seedList(true, 1, 3, 5, 7)
+		b := seedList(true, 2, 3, 4, 10, 11)
+
+		merged := linkedlist.MergeSorted(a, b, intsLess)
+		assertSlicesEqual(t, []interface{}{1, 2, 3, 3, 4, 5, 7, 10, 11}, merged.Slice())
+		assert.Equal(t, 9, merged.Size())
+		assert.True(t, a.Empty())
+		assert.True(t, b.Empty())
+	})
+
+	t.Run("one of the lists is empty", func(t *testing.T) {
+		merged := linkedlist.MergeSorted(linkedlist.New(false), seedList(false, 1, 2), intsLess)
+		assertSlicesEqual(t, []interface{}{1, 2}, merged.Slice())
+	})
+
+	t.Run("equal elements of the first list go first", func(t *testing.T) {
+		a := seedList(false, pair{key: 1, order: 0}, pair{key: 2, order: 0})
+		b := seedList(false, pair{key: 1, order: 1}, pair{key: 2, order: 1})
+
+		merged := linkedlist.MergeSorted(a, b, pairsLess)
+		assertSlicesEqual(t, []interface{}{
+			pair{key: 1, order: 0},
+			pair{key: 1, order: 1},
+			pair{key: 2, order: 0},
+			pair{key: 2, order: 1},
+		}, merged.Slice())
+	})
+
+	t.Run("randomized", func(t *testing.T) {
+		rnd := rand.New(rand.NewSource(7))
+		var expected []int
+		a, b := linkedlist.New(false), linkedlist.New(false)
+		for i := 0; i < 10000; i++ {
+			n := rnd.Intn(1000)
+			expected = append(expected, n)
+			if rnd.Intn(2) == 0 {
+				a.Append(n)
+			} else {
+				b.Append(n)
+			}
+		}
+
+		a.Sort(intsLess)
+		b.Sort(intsLess)
+		sort.Ints(expected)
+
+		merged := linkedlist.MergeSorted(a, b, intsLess)
+		items := merged.Slice()
+		assert.Equal(t, len(expected), merged.Size())
+		for i := range expected {
+			if expected[i] != items[i].(int) {
+				t.Fatalf("expected %d at index %d, got %d", expected[i], i, items[i])
+			}
+		}
+	})
+}
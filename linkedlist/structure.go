@@ -0,0 +1,205 @@
+package linkedlist
+
+import "github.com/pkg/errors"
+
+var ErrInvalidGroupSize = errors.New("group size must be greater than 0")
+
+// HasCycle reports whether following next pointers from the head
+// ever comes back to a visited node (Floyd's tortoise and hare)
+func (ll *LinkedList[T]) HasCycle() bool {
+	ll.locker.ReadLock()
+	defer ll.locker.ReadUnlock()
+
+	return hasCycle(ll.head)
+}
+
+// Middle returns data of the middle element, for an even size it is the second of the two middle ones
+func (ll *LinkedList[T]) Middle() (T, bool) {
+	ll.locker.ReadLock()
+	defer ll.locker.ReadUnlock()
+
+	if ll.head == nil {
+		var zero T
+		return zero, false
+	}
+
+	return ll.nodeAt(ll.size / 2).data, true
+}
+
+// NthFromEnd returns data of the n-th element counting from the tail, the tail itself is the 1st
+func (ll *LinkedList[T]) NthFromEnd(n int) (T, bool) {
+	ll.locker.ReadLock()
+	defer ll.locker.ReadUnlock()
+
+	if n < 1 || n > ll.size {
+		var zero T
+		return zero, false
+	}
+
+	return ll.nodeAt(ll.size - n).data, true
+}
+
+// RotateLeft moves the first k elements to the tail
+func (ll *LinkedList[T]) RotateLeft(k int) {
+	ll.locker.WriteLock()
+	defer ll.locker.WriteUnlock()
+
+	ll.rotateLeft(k)
+}
+
+// RotateRight moves the last k elements to the head
+func (ll *LinkedList[T]) RotateRight(k int) {
+	ll.locker.WriteLock()
+	defer ll.locker.WriteUnlock()
+
+	if ll.size == 0 {
+		return
+	}
+
+	ll.rotateLeft(ll.size - k%ll.size)
+}
+
+// ReverseRange reverses the order of elements between indexes i and j inclusive
+func (ll *LinkedList[T]) ReverseRange(i, j int) error {
+	ll.locker.WriteLock()
+	defer ll.locker.WriteUnlock()
+
+	if i < 0 || j >= ll.size || i > j {
+		return errors.Wrapf(ErrIndexOutOfRange, "cannot reverse range [%d, %d] of list of size %d", i, j, ll.size)
+	}
+
+	if i == j {
+		return nil
+	}
+
+	var before *Node[T]
+	first := ll.head
+	if i > 0 {
+		before = ll.nodeAt(i - 1)
+		first = before.next
+	}
+
+	head, tail, after := reverseChain(first, j-i+1)
+	tail.next = after
+	if before == nil {
+		ll.head = head
+	} else {
+		before.next = head
+	}
+
+	ll.version++
+	return nil
+}
+
+// ReverseInGroups reverses every consecutive group of k elements,
+// a trailing group shorter than k stays as it is
+func (ll *LinkedList[T]) ReverseInGroups(k int) error {
+	ll.locker.WriteLock()
+	defer ll.locker.WriteUnlock()
+
+	if k < 1 {
+		return errors.Wrapf(ErrInvalidGroupSize, "got %d", k)
+	}
+
+	if k == 1 || ll.size < k {
+		return nil
+	}
+
+	var prevTail *Node[T]
+	curr := ll.head
+	for remaining := ll.size; remaining >= k; remaining -= k {
+		head, tail, after := reverseChain(curr, k)
+		if prevTail == nil {
+			ll.head = head
+		} else {
+			prevTail.next = head
+		}
+
+		tail.next = after
+		prevTail = tail
+		curr = after
+	}
+
+	ll.version++
+	return nil
+}
+
+// Deduplicate keeps only the first of the equal elements and returns the number of removed ones
+func (ll *LinkedList[T]) Deduplicate(equal func(a, b T) bool) int {
+	ll.locker.WriteLock()
+	defer ll.locker.WriteUnlock()
+
+	removed := 0
+	for curr := ll.head; curr != nil; curr = curr.next {
+		prev := curr
+		for candidate := curr.next; candidate != nil; candidate = candidate.next {
+			if equal(curr.data, candidate.data) {
+				prev.next = candidate.next
+				removed++
+			} else {
+				prev = candidate
+			}
+		}
+	}
+
+	if removed > 0 {
+		ll.size -= removed
+		ll.version++
+	}
+
+	return removed
+}
+
+func (ll *LinkedList[T]) rotateLeft(k int) {
+	if ll.size < 2 {
+		return
+	}
+
+	k %= ll.size
+	if k < 0 {
+		k += ll.size
+	}
+
+	if k == 0 {
+		return
+	}
+
+	newTail := ll.nodeAt(k - 1)
+	last := newTail
+	for last.next != nil {
+		last = last.next
+	}
+
+	last.next = ll.head
+	ll.head = newTail.next
+	newTail.next = nil
+	ll.version++
+}
+
+// reverseChain reverses n nodes starting at first and returns
+// the new head, the new tail and the node that followed the reversed part
+func reverseChain[T any](first *Node[T], n int) (*Node[T], *Node[T], *Node[T]) {
+	var prev *Node[T]
+	curr := first
+	for i := 0; i < n; i++ {
+		next := curr.next
+		curr.next = prev
+		prev = curr
+		curr = next
+	}
+
+	return prev, first, curr
+}
+
+func hasCycle[T any](head *Node[T]) bool {
+	slow, fast := head, head
+	for fast != nil && fast.next != nil {
+		slow = slow.next
+		fast = fast.next.next
+		if slow == fast {
+			return true
+		}
+	}
+
+	return false
+}
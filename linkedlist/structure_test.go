@@ -0,0 +1,161 @@
+package linkedlist_test
+
+import (
+	"fmt"
+	"github.com/denismitr/gds/linkedlist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func intList(items ...int) *linkedlist.LinkedList[int] {
+	ll := linkedlist.NewOf[int](true)
+	for _, n := range items {
+		ll.Append(n)
+	}
+	return ll
+}
+
+func TestLinkedList_HasCycle(t *testing.T) {
+	assert.False(t, intList().HasCycle())
+	assert.False(t, intList(1).HasCycle())
+	assert.False(t, intList(1, 2, 3, 4).HasCycle())
+
+	ll := intList(1, 2, 3, 4, 5)
+	linkedlist.MakeCycle(ll, 4, 1)
+	assert.True(t, ll.HasCycle())
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, ll.Slice(), "slice must not loop forever")
+
+	self := intList(1)
+	linkedlist.MakeCycle(self, 0, 0)
+	assert.True(t, self.HasCycle())
+	assert.Equal(t, []int{1}, self.Slice())
+}
+
+func TestLinkedList_MiddleAndNthFromEnd(t *testing.T) {
+	_, ok := intList().Middle()
+	assert.False(t, ok)
+
+	m, ok := intList(1, 2, 3).Middle()
+	require.True(t, ok)
+	assert.Equal(t, 2, m)
+
+	m, ok = intList(1, 2, 3, 4).Middle()
+	require.True(t, ok)
+	assert.Equal(t, 3, m)
+
+	ll := intList(1, 2, 3, 4, 5)
+	for n := 1; n <= 5; n++ {
+		v, ok := ll.NthFromEnd(n)
+		require.True(t, ok)
+		assert.Equal(t, 6-n, v)
+	}
+
+	_, ok = ll.NthFromEnd(0)
+	assert.False(t, ok)
+	_, ok = ll.NthFromEnd(6)
+	assert.False(t, ok)
+}
+
+func TestLinkedList_Rotate(t *testing.T) {
+	tt := []struct {
+		k     int
+		left  []int
+		right []int
+	}{
+		{k: 0, left: []int{1, 2, 3, 4, 5}, right: []int{1, 2, 3, 4, 5}},
+		{k: 1, left: []int{2, 3, 4, 5, 1}, right: []int{5, 1, 2, 3, 4}},
+		{k: 2, left: []int{3, 4, 5, 1, 2}, right: []int{4, 5, 1, 2, 3}},
+		{k: 5, left: []int{1, 2, 3, 4, 5}, right: []int{1, 2, 3, 4, 5}},
+		{k: 7, left: []int{3, 4, 5, 1, 2}, right: []int{4, 5, 1, 2, 3}},
+		{k: -1, left: []int{5, 1, 2, 3, 4}, right: []int{2, 3, 4, 5, 1}},
+	}
+
+	for _, tc := range tt {
+		t.Run(fmt.Sprintf("k=%d", tc.k), func(t *testing.T) {
+			left := intList(1, 2, 3, 4, 5)
+			left.RotateLeft(tc.k)
+			assert.Equal(t, tc.left, left.Slice())
+
+			right := intList(1, 2, 3, 4, 5)
+			right.RotateRight(tc.k)
+			assert.Equal(t, tc.right, right.Slice())
+			assert.Equal(t, 5, right.Size())
+		})
+	}
+
+	empty := intList()
+	empty.RotateLeft(3)
+	empty.RotateRight(3)
+	assert.True(t, empty.Empty())
+}
+
+func TestLinkedList_ReverseRange(t *testing.T) {
+	tt := []struct {
+		i, j int
+		exp  []int
+	}{
+		{i: 0, j: 4, exp: []int{5, 4, 3, 2, 1}},
+		{i: 1, j: 3, exp: []int{1, 4, 3, 2, 5}},
+		{i: 0, j: 1, exp: []int{2, 1, 3, 4, 5}},
+		{i: 3, j: 4, exp: []int{1, 2, 3, 5, 4}},
+		{i: 2, j: 2, exp: []int{1, 2, 3, 4, 5}},
+	}
+
+	for _, tc := range tt {
+		t.Run(fmt.Sprintf("[%d, %d]", tc.i, tc.j), func(t *testing.T) {
+			ll := intList(1, 2, 3, 4, 5)
+			require.NoError(t, ll.ReverseRange(tc.i, tc.j))
+			assert.Equal(t, tc.exp, ll.Slice())
+			assert.Equal(t, 5, ll.Size())
+		})
+	}
+
+	ll := intList(1, 2, 3)
+	assert.ErrorIs(t, ll.ReverseRange(-1, 1), linkedlist.ErrIndexOutOfRange)
+	assert.ErrorIs(t, ll.ReverseRange(1, 3), linkedlist.ErrIndexOutOfRange)
+	assert.ErrorIs(t, ll.ReverseRange(2, 1), linkedlist.ErrIndexOutOfRange)
+}
+
+func TestLinkedList_ReverseInGroups(t *testing.T) {
+	tt := []struct {
+		k   int
+		exp []int
+	}{
+		{k: 1, exp: []int{1, 2, 3, 4, 5, 6, 7}},
+		{k: 2, exp: []int{2, 1, 4, 3, 6, 5, 7}},
+		{k: 3, exp: []int{3, 2, 1, 6, 5, 4, 7}},
+		{k: 7, exp: []int{7, 6, 5, 4, 3, 2, 1}},
+		{k: 8, exp: []int{1, 2, 3, 4, 5, 6, 7}},
+	}
+
+	for _, tc := range tt {
+		t.Run(fmt.Sprintf("k=%d", tc.k), func(t *testing.T) {
+			ll := intList(1, 2, 3, 4, 5, 6, 7)
+			require.NoError(t, ll.ReverseInGroups(tc.k))
+			assert.Equal(t, tc.exp, ll.Slice())
+			assert.Equal(t, 7, ll.Size())
+		})
+	}
+
+	assert.ErrorIs(t, intList(1).ReverseInGroups(0), linkedlist.ErrInvalidGroupSize)
+}
+
+func TestLinkedList_Deduplicate(t *testing.T) {
+	equal := func(a, b int) bool { return a == b }
+
+	ll := intList(1, 2, 1, 3, 2, 2, 4, 1)
+	assert.Equal(t, 4, ll.Deduplicate(equal))
+	assert.Equal(t, []int{1, 2, 3, 4}, ll.Slice())
+	assert.Equal(t, 4, ll.Size())
+
+	assert.Equal(t, 0, ll.Deduplicate(equal))
+
+	same := intList(7, 7, 7)
+	assert.Equal(t, 2, same.Deduplicate(equal))
+	assert.Equal(t, []int{7}, same.Slice())
+
+	byParity := intList(1, 2, 3, 4, 5)
+	assert.Equal(t, 3, byParity.Deduplicate(func(a, b int) bool { return a%2 == b%2 }))
+	assert.Equal(t, []int{1, 2}, byParity.Slice())
+}
@@ -0,0 +1,273 @@
+package linkedlist
+
+import (
+	"github.com/denismitr/gds/internal/utils"
+	"github.com/pkg/errors"
+)
+
+var ErrInvalidChunkCapacity = errors.New("chunk capacity must be at least 2")
+
+// DefaultChunkCapacity keeps a chunk of small values within a few cache lines
+const DefaultChunkCapacity = 64
+
+type chunk[T any] struct {
+	items []T
+	next  *chunk[T]
+}
+
+// Unrolled is a linked list where every node holds up to chunkCapacity elements.
+// Compared to LinkedList it needs one allocation per chunk instead of one per element
+// and indexed access skips whole chunks, which makes it O(n/chunkCapacity).
+// Every chunk but the last one is kept at least half full.
+type Unrolled[T any] struct {
+	locker        utils.Locker
+	head          *chunk[T]
+	tail          *chunk[T]
+	size          int
+	chunkCapacity int
+}
+
+// NewUnrolled creates an unrolled list, use DefaultChunkCapacity unless benchmarks say otherwise
+func NewUnrolled[T any](chunkCapacity int, locks bool) (*Unrolled[T], error) {
+	if chunkCapacity < 2 {
+		return nil, errors.Wrapf(ErrInvalidChunkCapacity, "got %d", chunkCapacity)
+	}
+
+	ul := Unrolled[T]{chunkCapacity: chunkCapacity}
+	if locks {
+		ul.locker = &utils.MutexLock{}
+	} else {
+		ul.locker = utils.NullLocker{}
+	}
+
+	return &ul, nil
+}
+
+// Append adds data to the end of the list and returns its index
+func (ul *Unrolled[T]) Append(data T) int {
+	ul.locker.WriteLock()
+	defer ul.locker.WriteUnlock()
+
+	if ul.tail == nil || len(ul.tail.items) == ul.chunkCapacity {
+		ul.pushChunk()
+	}
+
+	ul.tail.items = append(ul.tail.items, data)
+	ul.size++
+	return ul.size - 1
+}
+
+// Get returns data stored at the index
+func (ul *Unrolled[T]) Get(index int) (T, bool) {
+	ul.locker.ReadLock()
+	defer ul.locker.ReadUnlock()
+
+	if index < 0 || index >= ul.size {
+		var zero T
+		return zero, false
+	}
+
+	c, offset, _ := ul.locate(index)
+	return c.items[offset], true
+}
+
+// Set replaces data stored at the index
+func (ul *Unrolled[T]) Set(index int, data T) error {
+	ul.locker.WriteLock()
+	defer ul.locker.WriteUnlock()
+
+	if index < 0 || index >= ul.size {
+		return errors.Wrapf(ErrIndexOutOfRange, "cannot set index %d of list of size %d", index, ul.size)
+	}
+
+	c, offset, _ := ul.locate(index)
+	c.items[offset] = data
+	return nil
+}
+
+// Insert puts data at the index shifting the following elements,
+// index equal to Size appends to the end of the list
+func (ul *Unrolled[T]) Insert(index int, data T) error {
+	ul.locker.WriteLock()
+	defer ul.locker.WriteUnlock()
+
+	if index < 0 || index > ul.size {
+		return errors.Wrapf(ErrIndexOutOfRange, "cannot insert at index %d of list of size %d", index, ul.size)
+	}
+
+	if index == ul.size {
+		if ul.tail == nil || len(ul.tail.items) == ul.chunkCapacity {
+			ul.pushChunk()
+		}
+
+		ul.tail.items = append(ul.tail.items, data)
+		ul.size++
+		return nil
+	}
+
+	c, offset, _ := ul.locate(index)
+	if len(c.items) == ul.chunkCapacity {
+		ul.split(c)
+		if offset >= len(c.items) {
+			offset -= len(c.items)
+			c = c.next
+		}
+	}
+
+	var zero T
+	c.items = append(c.items, zero)
+	copy(c.items[offset+1:], c.items[offset:])
+	c.items[offset] = data
+	ul.size++
+	return nil
+}
+
+// Delete removes the element at the index and returns its data
+func (ul *Unrolled[T]) Delete(index int) (T, error) {
+	ul.locker.WriteLock()
+	defer ul.locker.WriteUnlock()
+
+	if index < 0 || index >= ul.size {
+		var zero T
+		return zero, errors.Wrapf(ErrIndexOutOfRange, "cannot delete index %d of list of size %d", index, ul.size)
+	}
+
+	c, offset, prev := ul.locate(index)
+	data := c.items[offset]
+
+	last := len(c.items) - 1
+	copy(c.items[offset:], c.items[offset+1:])
+	var zero T
+	c.items[last] = zero
+	c.items = c.items[:last]
+	ul.size--
+
+	ul.rebalance(c, prev)
+	return data, nil
+}
+
+// Slice returns the elements as a slice
+func (ul *Unrolled[T]) Slice() []T {
+	ul.locker.ReadLock()
+	defer ul.locker.ReadUnlock()
+
+	result := make([]T, 0, ul.size)
+	for c := ul.head; c != nil; c = c.next {
+		result = append(result, c.items...)
+	}
+
+	return result
+}
+
+// ForEach calls fn for every element in order
+func (ul *Unrolled[T]) ForEach(fn func(index int, data T)) {
+	ul.locker.ReadLock()
+	defer ul.locker.ReadUnlock()
+
+	i := 0
+	for c := ul.head; c != nil; c = c.next {
+		for _, data := range c.items {
+			fn(i, data)
+			i++
+		}
+	}
+}
+
+func (ul *Unrolled[T]) Size() int {
+	ul.locker.ReadLock()
+	defer ul.locker.ReadUnlock()
+	return ul.size
+}
+
+func (ul *Unrolled[T]) Empty() bool {
+	ul.locker.ReadLock()
+	defer ul.locker.ReadUnlock()
+	return ul.size == 0
+}
+
+// locate returns the chunk holding the element at the index,
+// the offset of the element inside that chunk and the chunk before it
+func (ul *Unrolled[T]) locate(index int) (*chunk[T], int, *chunk[T]) {
+	var prev *chunk[T]
+	c := ul.head
+	for index >= len(c.items) {
+		index -= len(c.items)
+		prev = c
+		c = c.next
+	}
+
+	return c, index, prev
+}
+
+func (ul *Unrolled[T]) pushChunk() {
+	c := &chunk[T]{items: make([]T, 0, ul.chunkCapacity)}
+	if ul.tail == nil {
+		ul.head = c
+	} else {
+		ul.tail.next = c
+	}
+
+	ul.tail = c
+}
+
+// split moves the second half of a full chunk into a new chunk right after it
+func (ul *Unrolled[T]) split(c *chunk[T]) {
+	half := len(c.items) / 2
+	next := &chunk[T]{items: make([]T, 0, ul.chunkCapacity), next: c.next}
+	next.items = append(next.items, c.items[half:]...)
+
+	var zero T
+	for i := half; i < len(c.items); i++ {
+		c.items[i] = zero
+	}
+
+	c.items = c.items[:half]
+	c.next = next
+	if ul.tail == c {
+		ul.tail = next
+	}
+}
+
+// rebalance restores the invariant after a deletion from c:
+// an empty chunk is unlinked, a chunk less than half full either
+// absorbs its successor or borrows elements from it
+func (ul *Unrolled[T]) rebalance(c, prev *chunk[T]) {
+	if len(c.items) == 0 {
+		ul.unlink(c, prev)
+		return
+	}
+
+	next := c.next
+	if next == nil || len(c.items) >= ul.chunkCapacity/2 {
+		return
+	}
+
+	if len(c.items)+len(next.items) <= ul.chunkCapacity {
+		c.items = append(c.items, next.items...)
+		ul.unlink(next, c)
+		return
+	}
+
+	borrow := ul.chunkCapacity/2 - len(c.items)
+	c.items = append(c.items, next.items[:borrow]...)
+
+	remaining := copy(next.items, next.items[borrow:])
+	var zero T
+	for i := remaining; i < len(next.items); i++ {
+		next.items[i] = zero
+	}
+
+	next.items = next.items[:remaining]
+}
+
+func (ul *Unrolled[T]) unlink(c, prev *chunk[T]) {
+	if prev == nil {
+		ul.head = c.next
+	} else {
+		prev.next = c.next
+	}
+
+	if ul.tail == c {
+		ul.tail = prev
+	}
+}
@@ -0,0 +1,87 @@
+package linkedlist_test
+
+import (
+	"github.com/denismitr/gds/linkedlist"
+	"testing"
+)
+
+const benchSize = 10000
+
+func BenchmarkUnrolled_Append(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		ul, _ := linkedlist.NewUnrolled[int](linkedlist.DefaultChunkCapacity, false)
+		for j := 0; j < benchSize; j++ {
+			ul.Append(j)
+		}
+	}
+}
+
+func BenchmarkSlice_Append(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		var s []int
+		for j := 0; j < benchSize; j++ {
+			s = append(s, j)
+		}
+	}
+}
+
+func BenchmarkUnrolled_Get(b *testing.B) {
+	ul, _ := linkedlist.NewUnrolled[int](linkedlist.DefaultChunkCapacity, false)
+	for j := 0; j < benchSize; j++ {
+		ul.Append(j)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ul.Get(i % benchSize)
+	}
+}
+
+func BenchmarkLinkedList_PeakAt(b *testing.B) {
+	ll := linkedlist.NewOf[int](false)
+	for j := 0; j < benchSize; j++ {
+		ll.Append(j)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ll.PeakAt(i % benchSize)
+	}
+}
+
+func BenchmarkSlice_Index(b *testing.B) {
+	s := make([]int, benchSize)
+	for j := range s {
+		s[j] = j
+	}
+
+	b.ResetTimer()
+	sum := 0
+	for i := 0; i < b.N; i++ {
+		sum += s[i%benchSize]
+	}
+	_ = sum
+}
+
+func BenchmarkUnrolled_InsertMiddle(b *testing.B) {
+	ul, _ := linkedlist.NewUnrolled[int](linkedlist.DefaultChunkCapacity, false)
+	for j := 0; j < benchSize; j++ {
+		ul.Append(j)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = ul.Insert(benchSize/2, i)
+		_, _ = ul.Delete(benchSize / 2)
+	}
+}
+
+func BenchmarkSlice_InsertMiddle(b *testing.B) {
+	s := make([]int, benchSize)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s = append(s[:benchSize/2], append([]int{i}, s[benchSize/2:]...)...)
+		s = append(s[:benchSize/2], s[benchSize/2+1:]...)
+	}
+}
@@ -0,0 +1,159 @@
+package linkedlist_test
+
+import (
+	"github.com/denismitr/gds/linkedlist"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/rand"
+	"testing"
+)
+
+func assertChunksBalanced[T any](t *testing.T, ul *linkedlist.Unrolled[T], chunkCapacity int) {
+	t.Helper()
+
+	sizes := linkedlist.ChunkSizes(ul)
+	total := 0
+	for i, size := range sizes {
+		total += size
+		assert.LessOrEqual(t, size, chunkCapacity, "chunk %d overflows", i)
+		assert.Greater(t, size, 0, "chunk %d is empty", i)
+		if i < len(sizes)-1 {
+			assert.GreaterOrEqual(t, size, chunkCapacity/2, "chunk %d is less than half full", i)
+		}
+	}
+
+	assert.Equal(t, ul.Size(), total)
+}
+
+func TestNewUnrolled_InvalidChunkCapacity(t *testing.T) {
+	for _, capacity := range []int{-1, 0, 1} {
+		ul, err := linkedlist.NewUnrolled[int](capacity, false)
+		assert.Nil(t, ul)
+		assert.True(t, errors.Is(err, linkedlist.ErrInvalidChunkCapacity))
+	}
+}
+
+func TestUnrolled_AppendGetSet(t *testing.T) {
+	ul, err := linkedlist.NewUnrolled[int](4, true)
+	require.NoError(t, err)
+	assert.True(t, ul.Empty())
+
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, i, ul.Append(i*10))
+	}
+
+	assert.Equal(t, 10, ul.Size())
+	assert.Equal(t, []int{4, 4, 2}, linkedlist.ChunkSizes(ul))
+
+	v, ok := ul.Get(5)
+	assert.True(t, ok)
+	assert.Equal(t, 50, v)
+
+	_, ok = ul.Get(10)
+	assert.False(t, ok)
+	_, ok = ul.Get(-1)
+	assert.False(t, ok)
+
+	require.NoError(t, ul.Set(9, 99))
+	assert.Equal(t, []int{0, 10, 20, 30, 40, 50, 60, 70, 80, 99}, ul.Slice())
+
+	err = ul.Set(10, 1)
+	assert.True(t, errors.Is(err, linkedlist.ErrIndexOutOfRange))
+}
+
+func TestUnrolled_InsertSplitsFullChunk(t *testing.T) {
+	ul, err := linkedlist.NewUnrolled[string](4, false)
+	require.NoError(t, err)
+
+	for _, s := range []string{"a", "b", "c", "d"} {
+		ul.Append(s)
+	}
+
+	require.NoError(t, ul.Insert(1, "x"))
+	assert.Equal(t, []string{"a", "x", "b", "c", "d"}, ul.Slice())
+	assert.Equal(t, []int{3, 2}, linkedlist.ChunkSizes(ul))
+
+	require.NoError(t, ul.Insert(0, "first"))
+	require.NoError(t, ul.Insert(ul.Size(), "last"))
+	assert.Equal(t, []string{"first", "a", "x", "b", "c", "d", "last"}, ul.Slice())
+
+	err = ul.Insert(100, "z")
+	assert.True(t, errors.Is(err, linkedlist.ErrIndexOutOfRange))
+}
+
+func TestUnrolled_DeleteMergesChunks(t *testing.T) {
+	ul, err := linkedlist.NewUnrolled[int](4, false)
+	require.NoError(t, err)
+
+	for i := 0; i < 8; i++ {
+		ul.Append(i)
+	}
+	require.Equal(t, []int{4, 4}, linkedlist.ChunkSizes(ul))
+
+	v, err := ul.Delete(0)
+	require.NoError(t, err)
+	assert.Equal(t, 0, v)
+	assert.Equal(t, []int{3, 4}, linkedlist.ChunkSizes(ul))
+
+	_, err = ul.Delete(0)
+	require.NoError(t, err)
+	_, err = ul.Delete(0)
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 3}, linkedlist.ChunkSizes(ul), "underflowing chunk borrows from the next one")
+
+	_, err = ul.Delete(0)
+	require.NoError(t, err)
+	assert.Equal(t, []int{4}, linkedlist.ChunkSizes(ul), "underflowing chunk absorbs the next one")
+	assert.Equal(t, []int{4, 5, 6, 7}, ul.Slice())
+
+	for !ul.Empty() {
+		_, err = ul.Delete(ul.Size() - 1)
+		require.NoError(t, err)
+	}
+
+	assert.Empty(t, linkedlist.ChunkSizes(ul))
+	ul.Append(42)
+	assert.Equal(t, []int{42}, ul.Slice())
+
+	_, err = ul.Delete(1)
+	assert.True(t, errors.Is(err, linkedlist.ErrIndexOutOfRange))
+}
+
+func TestUnrolled_RandomOperationsMatchSlice(t *testing.T) {
+	for _, chunkCapacity := range []int{2, 3, 8, linkedlist.DefaultChunkCapacity} {
+		rnd := rand.New(rand.NewSource(int64(chunkCapacity)))
+		ul, err := linkedlist.NewUnrolled[int](chunkCapacity, false)
+		require.NoError(t, err)
+
+		var model []int
+		for i := 0; i < 5000; i++ {
+			switch op := rnd.Intn(4); {
+			case op == 0:
+				ul.Append(i)
+				model = append(model, i)
+			case op == 1:
+				at := rnd.Intn(len(model) + 1)
+				require.NoError(t, ul.Insert(at, i))
+				model = append(model[:at], append([]int{i}, model[at:]...)...)
+			case op == 2 && len(model) > 0:
+				at := rnd.Intn(len(model))
+				v, err := ul.Delete(at)
+				require.NoError(t, err)
+				require.Equal(t, model[at], v)
+				model = append(model[:at], model[at+1:]...)
+			case op == 3 && len(model) > 0:
+				at := rnd.Intn(len(model))
+				require.NoError(t, ul.Set(at, -i))
+				model[at] = -i
+			}
+		}
+
+		assert.Equal(t, model, ul.Slice())
+		assertChunksBalanced(t, ul, chunkCapacity)
+
+		ul.ForEach(func(i, v int) {
+			assert.Equal(t, model[i], v)
+		})
+	}
+}
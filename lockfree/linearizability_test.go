@@ -0,0 +1,196 @@
+package lockfree_test
+
+import (
+	"github.com/denismitr/gds/lockfree"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// operation is a single recorded call, call and ret are
+// logical timestamps taken right before and right after the call
+type operation struct {
+	push  bool
+	value int
+	ok    bool
+	call  int64
+	ret   int64
+}
+
+// model is a sequential specification of a container
+type model interface {
+	apply(op operation) bool
+	undo(op operation)
+}
+
+type queueModel struct{ items []int }
+
+func (m *queueModel) apply(op operation) bool {
+	if op.push {
+		m.items = append(m.items, op.value)
+		return true
+	}
+
+	if len(m.items) == 0 {
+		return !op.ok
+	}
+
+	if !op.ok || m.items[0] != op.value {
+		return false
+	}
+
+	m.items = m.items[1:]
+	return true
+}
+
+func (m *queueModel) undo(op operation) {
+	if op.push {
+		m.items = m.items[:len(m.items)-1]
+	} else if op.ok {
+		m.items = append([]int{op.value}, m.items...)
+	}
+}
+
+type stackModel struct{ items []int }
+
+func (m *stackModel) apply(op operation) bool {
+	if op.push {
+		m.items = append(m.items, op.value)
+		return true
+	}
+
+	if len(m.items) == 0 {
+		return !op.ok
+	}
+
+	if !op.ok || m.items[len(m.items)-1] != op.value {
+		return false
+	}
+
+	m.items = m.items[:len(m.items)-1]
+	return true
+}
+
+func (m *stackModel) undo(op operation) {
+	if op.push {
+		m.items = m.items[:len(m.items)-1]
+	} else if op.ok {
+		m.items = append(m.items, op.value)
+	}
+}
+
+// linearizable searches for an order of the operations that respects
+// real time (an operation that returned before another one was called goes first)
+// and is valid according to the sequential model
+func linearizable(history []operation, m model) bool {
+	done := make([]bool, len(history))
+
+	var search func(left int) bool
+	search = func(left int) bool {
+		if left == 0 {
+			return true
+		}
+
+		// the earliest return among pending operations bounds the candidates
+		minRet := int64(-1)
+		for i, op := range history {
+			if !done[i] && (minRet < 0 || op.ret < minRet) {
+				minRet = op.ret
+			}
+		}
+
+		for i, op := range history {
+			if done[i] || op.call > minRet {
+				continue
+			}
+
+			if m.apply(op) {
+				done[i] = true
+				if search(left - 1) {
+					return true
+				}
+				done[i] = false
+				m.undo(op)
+			}
+		}
+
+		return false
+	}
+
+	return search(len(history))
+}
+
+func recordHistory(workers, opsPerWorker int, push func(int), pop func() (int, bool)) []operation {
+	var clock int64
+	histories := make([][]operation, workers)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < opsPerWorker; i++ {
+				var op operation
+				if (w+i)%2 == 0 {
+					op.push = true
+					op.value = w*opsPerWorker + i
+					op.call = atomic.AddInt64(&clock, 1)
+					push(op.value)
+				} else {
+					op.call = atomic.AddInt64(&clock, 1)
+					op.value, op.ok = pop()
+				}
+				op.ret = atomic.AddInt64(&clock, 1)
+				histories[w] = append(histories[w], op)
+			}
+		}(w)
+	}
+
+	wg.Wait()
+
+	var history []operation
+	for _, h := range histories {
+		history = append(history, h...)
+	}
+
+	return history
+}
+
+func TestQueue_Linearizability(t *testing.T) {
+	for i := 0; i < 300; i++ {
+		q := lockfree.NewQueue[int]()
+		history := recordHistory(4, 4, q.Enqueue, q.Dequeue)
+		if !linearizable(history, &queueModel{}) {
+			t.Fatalf("history is not linearizable: %+v", history)
+		}
+	}
+}
+
+func TestStack_Linearizability(t *testing.T) {
+	for i := 0; i < 300; i++ {
+		s := lockfree.NewStack[int]()
+		history := recordHistory(4, 4, s.Push, s.Pop)
+		if !linearizable(history, &stackModel{}) {
+			t.Fatalf("history is not linearizable: %+v", history)
+		}
+	}
+}
+
+func TestLinearizabilityChecker(t *testing.T) {
+	// sanity check of the checker itself: a pop that returns a value
+	// strictly before it was pushed can not be linearized
+	history := []operation{
+		{push: false, value: 1, ok: true, call: 1, ret: 2},
+		{push: true, value: 1, call: 3, ret: 4},
+	}
+
+	if linearizable(history, &queueModel{}) {
+		t.Fatal("expected history to be rejected")
+	}
+
+	history[0], history[1] = history[1], history[0]
+	history[0].call, history[0].ret, history[1].call, history[1].ret = 1, 2, 3, 4
+	if !linearizable(history, &queueModel{}) {
+		t.Fatal("expected history to be accepted")
+	}
+}
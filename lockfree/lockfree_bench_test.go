@@ -0,0 +1,46 @@
+package lockfree_test
+
+import (
+	"github.com/denismitr/gds/linkedlist"
+	"github.com/denismitr/gds/lockfree"
+	"testing"
+)
+
+func BenchmarkQueue_EnqueueDequeue(b *testing.B) {
+	q := lockfree.NewQueue[int]()
+
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			q.Enqueue(i)
+			q.Dequeue()
+			i++
+		}
+	})
+}
+
+func BenchmarkStack_PushPop(b *testing.B) {
+	s := lockfree.NewStack[int]()
+
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			s.Push(i)
+			s.Pop()
+			i++
+		}
+	})
+}
+
+func BenchmarkLinkedListWithMutex_AppendPop(b *testing.B) {
+	ll := linkedlist.NewOf[int](true)
+
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			ll.Append(i)
+			ll.Pop()
+			i++
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package lockfree
+
+import "sync/atomic"
+
+type queueNode[T any] struct {
+	value T
+	next  atomic.Pointer[queueNode[T]]
+}
+
+// Queue is an unbounded Michael-Scott lock-free FIFO queue.
+// It is safe for concurrent use by any number of producers and consumers.
+type Queue[T any] struct {
+	// head always points to a dummy node,
+	// the first value of the queue is stored in head.next
+	head atomic.Pointer[queueNode[T]]
+	tail atomic.Pointer[queueNode[T]]
+	size atomic.Int64
+}
+
+func NewQueue[T any]() *Queue[T] {
+	q := &Queue[T]{}
+	dummy := &queueNode[T]{}
+	q.head.Store(dummy)
+	q.tail.Store(dummy)
+	return q
+}
+
+// Enqueue adds value to the tail of the queue
+func (q *Queue[T]) Enqueue(value T) {
+	n := &queueNode[T]{value: value}
+
+	for {
+		tail := q.tail.Load()
+		next := tail.next.Load()
+
+		if tail != q.tail.Load() {
+			continue
+		}
+
+		if next != nil {
+			// tail is lagging behind, help the other enqueuer to move it
+			q.tail.CompareAndSwap(tail, next)
+			continue
+		}
+
+		if tail.next.CompareAndSwap(nil, n) {
+			// it is fine if this fails, somebody else has already moved the tail
+			q.tail.CompareAndSwap(tail, n)
+			q.size.Add(1)
+			return
+		}
+	}
+}
+
+// Dequeue removes and returns the value at the head of the queue,
+// the second return value is false if the queue is empty
+func (q *Queue[T]) Dequeue() (T, bool) {
+	for {
+		head := q.head.Load()
+		tail := q.tail.Load()
+		next := head.next.Load()
+
+		if head != q.head.Load() {
+			continue
+		}
+
+		if next == nil {
+			var zero T
+			return zero, false
+		}
+
+		if head == tail {
+			// tail is lagging behind
+			q.tail.CompareAndSwap(tail, next)
+			continue
+		}
+
+		value := next.value
+		if q.head.CompareAndSwap(head, next) {
+			q.size.Add(-1)
+			return value, true
+		}
+	}
+}
+
+// Peek returns the value at the head of the queue without removing it
+func (q *Queue[T]) Peek() (T, bool) {
+	next := q.head.Load().next.Load()
+	if next == nil {
+		var zero T
+		return zero, false
+	}
+
+	return next.value, true
+}
+
+// Empty reports whether the queue had no values at the moment of the call
+func (q *Queue[T]) Empty() bool {
+	return q.head.Load().next.Load() == nil
+}
+
+// Size of the queue, under concurrent modification it is only an estimate
+func (q *Queue[T]) Size() int {
+	if n := q.size.Load(); n > 0 {
+		return int(n)
+	}
+
+	return 0
+}
@@ -0,0 +1,117 @@
+package lockfree_test
+
+import (
+	"github.com/denismitr/gds/lockfree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+)
+
+type message struct {
+	producer int
+	seq      int
+}
+
+func TestQueue_Sequential(t *testing.T) {
+	q := lockfree.NewQueue[int]()
+	assert.True(t, q.Empty())
+
+	_, ok := q.Dequeue()
+	assert.False(t, ok)
+
+	for i := 1; i <= 5; i++ {
+		q.Enqueue(i)
+	}
+
+	assert.False(t, q.Empty())
+	assert.Equal(t, 5, q.Size())
+
+	v, ok := q.Peek()
+	require.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	for i := 1; i <= 5; i++ {
+		v, ok := q.Dequeue()
+		require.True(t, ok)
+		assert.Equal(t, i, v)
+	}
+
+	assert.True(t, q.Empty())
+	assert.Equal(t, 0, q.Size())
+
+	_, ok = q.Peek()
+	assert.False(t, ok)
+}
+
+func TestQueue_Stress(t *testing.T) {
+	const producers = 8
+	const consumers = 8
+	const perProducer = 5000
+
+	q := lockfree.NewQueue[message]()
+
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < perProducer; i++ {
+				q.Enqueue(message{producer: p, seq: i})
+			}
+		}(p)
+	}
+
+	received := make([][]message, consumers)
+	var consumed sync.WaitGroup
+	var mu sync.Mutex
+	total := 0
+	for c := 0; c < consumers; c++ {
+		consumed.Add(1)
+		go func(c int) {
+			defer consumed.Done()
+			for {
+				mu.Lock()
+				done := total == producers*perProducer
+				mu.Unlock()
+				if done {
+					return
+				}
+
+				m, ok := q.Dequeue()
+				if !ok {
+					continue
+				}
+
+				received[c] = append(received[c], m)
+				mu.Lock()
+				total++
+				mu.Unlock()
+			}
+		}(c)
+	}
+
+	wg.Wait()
+	consumed.Wait()
+
+	seen := make(map[message]bool, producers*perProducer)
+	for _, msgs := range received {
+		// every consumer must see the messages of each producer in the order they were sent
+		last := make(map[int]int)
+		for _, m := range msgs {
+			if prev, ok := last[m.producer]; ok && prev >= m.seq {
+				t.Fatalf("message %+v received after seq %d", m, prev)
+			}
+			last[m.producer] = m.seq
+
+			if seen[m] {
+				t.Fatalf("message %+v received twice", m)
+			}
+			seen[m] = true
+		}
+	}
+
+	assert.Len(t, seen, producers*perProducer)
+	assert.True(t, q.Empty())
+	assert.Equal(t, 0, q.Size())
+}
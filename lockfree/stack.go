@@ -0,0 +1,78 @@
+package lockfree
+
+import "sync/atomic"
+
+type stackNode[T any] struct {
+	value T
+	// next is written only before the node gets published with CAS,
+	// so it does not need to be atomic
+	next *stackNode[T]
+}
+
+// Stack is an unbounded Treiber lock-free LIFO stack.
+// It is safe for concurrent use by any number of goroutines.
+type Stack[T any] struct {
+	top  atomic.Pointer[stackNode[T]]
+	size atomic.Int64
+}
+
+func NewStack[T any]() *Stack[T] {
+	return &Stack[T]{}
+}
+
+// Push puts value on top of the stack
+func (s *Stack[T]) Push(value T) {
+	n := &stackNode[T]{value: value}
+
+	for {
+		top := s.top.Load()
+		n.next = top
+		if s.top.CompareAndSwap(top, n) {
+			s.size.Add(1)
+			return
+		}
+	}
+}
+
+// Pop removes and returns the value on top of the stack,
+// the second return value is false if the stack is empty
+func (s *Stack[T]) Pop() (T, bool) {
+	for {
+		top := s.top.Load()
+		if top == nil {
+			var zero T
+			return zero, false
+		}
+
+		// nodes are never reused, so the garbage collector protects us from ABA
+		if s.top.CompareAndSwap(top, top.next) {
+			s.size.Add(-1)
+			return top.value, true
+		}
+	}
+}
+
+// Peek returns the value on top of the stack without removing it
+func (s *Stack[T]) Peek() (T, bool) {
+	top := s.top.Load()
+	if top == nil {
+		var zero T
+		return zero, false
+	}
+
+	return top.value, true
+}
+
+// Empty reports whether the stack had no values at the moment of the call
+func (s *Stack[T]) Empty() bool {
+	return s.top.Load() == nil
+}
+
+// Size of the stack, under concurrent modification it is only an estimate
+func (s *Stack[T]) Size() int {
+	if n := s.size.Load(); n > 0 {
+		return int(n)
+	}
+
+	return 0
+}
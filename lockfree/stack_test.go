@@ -0,0 +1,88 @@
+package lockfree_test
+
+import (
+	"github.com/denismitr/gds/lockfree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+)
+
+func TestStack_Sequential(t *testing.T) {
+	s := lockfree.NewStack[string]()
+	assert.True(t, s.Empty())
+
+	_, ok := s.Pop()
+	assert.False(t, ok)
+
+	for _, v := range []string{"a", "b", "c"} {
+		s.Push(v)
+	}
+
+	assert.False(t, s.Empty())
+	assert.Equal(t, 3, s.Size())
+
+	v, ok := s.Peek()
+	require.True(t, ok)
+	assert.Equal(t, "c", v)
+
+	for _, exp := range []string{"c", "b", "a"} {
+		v, ok := s.Pop()
+		require.True(t, ok)
+		assert.Equal(t, exp, v)
+	}
+
+	assert.True(t, s.Empty())
+	assert.Equal(t, 0, s.Size())
+}
+
+func TestStack_Stress(t *testing.T) {
+	const workers = 16
+	const perWorker = 5000
+
+	s := lockfree.NewStack[message]()
+
+	var wg sync.WaitGroup
+	popped := make([][]message, workers)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				s.Push(message{producer: w, seq: i})
+				// pop every other iteration so the stack is contended from both sides
+				if i%2 == 1 {
+					for j := 0; j < 2; j++ {
+						if m, ok := s.Pop(); ok {
+							popped[w] = append(popped[w], m)
+						}
+					}
+				}
+			}
+		}(w)
+	}
+
+	wg.Wait()
+
+	for {
+		m, ok := s.Pop()
+		if !ok {
+			break
+		}
+		popped[0] = append(popped[0], m)
+	}
+
+	seen := make(map[message]bool, workers*perWorker)
+	for _, msgs := range popped {
+		for _, m := range msgs {
+			if seen[m] {
+				t.Fatalf("message %+v popped twice", m)
+			}
+			seen[m] = true
+		}
+	}
+
+	assert.Len(t, seen, workers*perWorker)
+	assert.True(t, s.Empty())
+	assert.Equal(t, 0, s.Size())
+}
@@ -0,0 +1,212 @@
+package ringbuffer
+
+import (
+	"context"
+	"github.com/denismitr/gds/internal/utils"
+	"github.com/pkg/errors"
+)
+
+var ErrInvalidCapacity = errors.New("capacity must be greater than 0")
+var ErrFull = errors.New("ring buffer is full")
+
+type options struct {
+	overwrite bool
+	useMutex  bool
+}
+
+type OptionFunc func(*options)
+
+// WithOverwrite makes a full buffer drop its oldest value on Push
+// instead of rejecting the new one
+func WithOverwrite() OptionFunc {
+	return func(o *options) {
+		o.overwrite = true
+	}
+}
+
+// WithMutex makes the buffer safe for concurrent use,
+// it is required for the blocking Put and Take to be useful
+func WithMutex() OptionFunc {
+	return func(o *options) {
+		o.useMutex = true
+	}
+}
+
+// RingBuffer is a fixed capacity FIFO buffer
+type RingBuffer[T any] struct {
+	locker    utils.Locker
+	signal    utils.Signal
+	buf       []T
+	head      int
+	size      int
+	overwrite bool
+}
+
+func New[T any](capacity int, ofs ...OptionFunc) (*RingBuffer[T], error) {
+	if capacity < 1 {
+		return nil, ErrInvalidCapacity
+	}
+
+	var opts options
+	for _, opt := range ofs {
+		opt(&opts)
+	}
+
+	rb := RingBuffer[T]{
+		buf:       make([]T, capacity),
+		overwrite: opts.overwrite,
+	}
+
+	if opts.useMutex {
+		rb.locker = &utils.MutexLock{}
+	} else {
+		rb.locker = utils.NullLocker{}
+	}
+
+	return &rb, nil
+}
+
+// Push adds value to the buffer, when the buffer is full it either
+// overwrites the oldest value or returns ErrFull depending on the mode
+func (rb *RingBuffer[T]) Push(value T) error {
+	rb.locker.WriteLock()
+	defer rb.locker.WriteUnlock()
+
+	return rb.push(value)
+}
+
+// Pop removes and returns the oldest value
+func (rb *RingBuffer[T]) Pop() (T, bool) {
+	rb.locker.WriteLock()
+	defer rb.locker.WriteUnlock()
+
+	return rb.pop()
+}
+
+// Put adds value to the buffer waiting for free space if the buffer is full,
+// in the overwrite mode it never waits
+func (rb *RingBuffer[T]) Put(ctx context.Context, value T) error {
+	for {
+		rb.locker.WriteLock()
+		err := rb.push(value)
+		var wait <-chan struct{}
+		if err != nil {
+			wait = rb.signal.Wait()
+		}
+		rb.locker.WriteUnlock()
+
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wait:
+		}
+	}
+}
+
+// Take removes and returns the oldest value waiting for one if the buffer is empty
+func (rb *RingBuffer[T]) Take(ctx context.Context) (T, error) {
+	for {
+		rb.locker.WriteLock()
+		value, ok := rb.pop()
+		var wait <-chan struct{}
+		if !ok {
+			wait = rb.signal.Wait()
+		}
+		rb.locker.WriteUnlock()
+
+		if ok {
+			return value, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		case <-wait:
+		}
+	}
+}
+
+// Peek returns the oldest value without removing it
+func (rb *RingBuffer[T]) Peek() (T, bool) {
+	rb.locker.ReadLock()
+	defer rb.locker.ReadUnlock()
+
+	if rb.size == 0 {
+		var zero T
+		return zero, false
+	}
+
+	return rb.buf[rb.head], true
+}
+
+// Slice returns the values from the oldest to the newest
+func (rb *RingBuffer[T]) Slice() []T {
+	rb.locker.ReadLock()
+	defer rb.locker.ReadUnlock()
+
+	result := make([]T, rb.size)
+	for i := range result {
+		result[i] = rb.buf[(rb.head+i)%len(rb.buf)]
+	}
+
+	return result
+}
+
+func (rb *RingBuffer[T]) Size() int {
+	rb.locker.ReadLock()
+	defer rb.locker.ReadUnlock()
+	return rb.size
+}
+
+func (rb *RingBuffer[T]) Capacity() int {
+	return len(rb.buf)
+}
+
+func (rb *RingBuffer[T]) Empty() bool {
+	rb.locker.ReadLock()
+	defer rb.locker.ReadUnlock()
+	return rb.size == 0
+}
+
+func (rb *RingBuffer[T]) Full() bool {
+	rb.locker.ReadLock()
+	defer rb.locker.ReadUnlock()
+	return rb.size == len(rb.buf)
+}
+
+func (rb *RingBuffer[T]) push(value T) error {
+	if rb.size == len(rb.buf) {
+		if !rb.overwrite {
+			return ErrFull
+		}
+
+		rb.buf[rb.head] = value
+		rb.head = (rb.head + 1) % len(rb.buf)
+		rb.signal.Broadcast()
+		return nil
+	}
+
+	rb.buf[(rb.head+rb.size)%len(rb.buf)] = value
+	rb.size++
+	rb.signal.Broadcast()
+	return nil
+}
+
+func (rb *RingBuffer[T]) pop() (T, bool) {
+	var zero T
+	if rb.size == 0 {
+		return zero, false
+	}
+
+	value := rb.buf[rb.head]
+	rb.buf[rb.head] = zero
+	rb.head = (rb.head + 1) % len(rb.buf)
+	rb.size--
+	rb.signal.Broadcast()
+	return value, true
+}
@@ -0,0 +1,141 @@
+package ringbuffer_test
+
+import (
+	"context"
+	"github.com/denismitr/gds/ringbuffer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRingBuffer_New(t *testing.T) {
+	_, err := ringbuffer.New[int](0)
+	assert.ErrorIs(t, err, ringbuffer.ErrInvalidCapacity)
+
+	rb, err := ringbuffer.New[int](3)
+	require.NoError(t, err)
+	assert.Equal(t, 3, rb.Capacity())
+	assert.True(t, rb.Empty())
+	assert.False(t, rb.Full())
+}
+
+func TestRingBuffer_Reject(t *testing.T) {
+	rb, err := ringbuffer.New[int](3)
+	require.NoError(t, err)
+
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, rb.Push(i))
+	}
+
+	assert.True(t, rb.Full())
+	assert.ErrorIs(t, rb.Push(4), ringbuffer.ErrFull)
+	assert.Equal(t, []int{1, 2, 3}, rb.Slice())
+
+	v, ok := rb.Pop()
+	require.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	require.NoError(t, rb.Push(4))
+	assert.Equal(t, []int{2, 3, 4}, rb.Slice())
+
+	peeked, ok := rb.Peek()
+	require.True(t, ok)
+	assert.Equal(t, 2, peeked)
+
+	for _, exp := range []int{2, 3, 4} {
+		v, ok := rb.Pop()
+		require.True(t, ok)
+		assert.Equal(t, exp, v)
+	}
+
+	_, ok = rb.Pop()
+	assert.False(t, ok)
+	_, ok = rb.Peek()
+	assert.False(t, ok)
+}
+
+func TestRingBuffer_Overwrite(t *testing.T) {
+	rb, err := ringbuffer.New[string](3, ringbuffer.WithOverwrite())
+	require.NoError(t, err)
+
+	for _, s := range []string{"a", "b", "c", "d", "e"} {
+		require.NoError(t, rb.Push(s))
+	}
+
+	assert.Equal(t, 3, rb.Size())
+	assert.Equal(t, []string{"c", "d", "e"}, rb.Slice())
+
+	v, ok := rb.Pop()
+	require.True(t, ok)
+	assert.Equal(t, "c", v)
+
+	require.NoError(t, rb.Put(context.Background(), "f"))
+	require.NoError(t, rb.Put(context.Background(), "g"))
+	assert.Equal(t, []string{"e", "f", "g"}, rb.Slice())
+}
+
+func TestRingBuffer_Blocking(t *testing.T) {
+	t.Run("put waits for free space", func(t *testing.T) {
+		rb, err := ringbuffer.New[int](1, ringbuffer.WithMutex())
+		require.NoError(t, err)
+		require.NoError(t, rb.Push(1))
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			rb.Pop()
+		}()
+
+		require.NoError(t, rb.Put(context.Background(), 2))
+		assert.Equal(t, []int{2}, rb.Slice())
+	})
+
+	t.Run("put and take are cancelled with context", func(t *testing.T) {
+		rb, err := ringbuffer.New[int](1, ringbuffer.WithMutex())
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err = rb.Take(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		require.NoError(t, rb.Push(1))
+		assert.ErrorIs(t, rb.Put(ctx, 2), context.DeadlineExceeded)
+	})
+
+	t.Run("bounded producer consumer pipeline", func(t *testing.T) {
+		const producers = 4
+		const perProducer = 2000
+
+		rb, err := ringbuffer.New[int](16, ringbuffer.WithMutex())
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		for p := 0; p < producers; p++ {
+			wg.Add(1)
+			go func(p int) {
+				defer wg.Done()
+				for i := 0; i < perProducer; i++ {
+					if err := rb.Put(context.Background(), p*perProducer+i); err != nil {
+						t.Error(err)
+						return
+					}
+				}
+			}(p)
+		}
+
+		seen := make(map[int]bool)
+		for i := 0; i < producers*perProducer; i++ {
+			v, err := rb.Take(context.Background())
+			require.NoError(t, err)
+			assert.False(t, seen[v])
+			seen[v] = true
+			assert.LessOrEqual(t, rb.Size(), rb.Capacity())
+		}
+
+		wg.Wait()
+		assert.True(t, rb.Empty())
+	})
+}
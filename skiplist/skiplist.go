@@ -0,0 +1,411 @@
+package skiplist
+
+import (
+	"github.com/denismitr/gds/internal/utils"
+	"github.com/pkg/errors"
+	"math/rand"
+	"time"
+)
+
+var ErrInvalidMaxLevel = errors.New("invalid max level")
+var ErrNilComparator = errors.New("comparator must not be nil")
+
+const (
+	DefaultMaxLevel = 32
+	MaxLevelLimit   = 64
+)
+
+// probability of a node to be promoted to the next level is 1/branching
+const branching = 4
+
+// Comparator returns a negative number when a < b, zero when a == b and a positive number when a > b
+type Comparator[K any] func(a, b K) int
+
+type options struct {
+	seed     int64
+	hasSeed  bool
+	maxLevel int
+	useMutex bool
+}
+
+type OptionFunc func(*options)
+
+// WithSeed makes the levels of the nodes deterministic
+func WithSeed(seed int64) OptionFunc {
+	return func(o *options) {
+		o.seed = seed
+		o.hasSeed = true
+	}
+}
+
+// WithMaxLevel limits the number of levels, 32 levels are enough for 4^32 elements
+func WithMaxLevel(maxLevel int) OptionFunc {
+	return func(o *options) {
+		o.maxLevel = maxLevel
+	}
+}
+
+// WithMutex makes the skip list safe for concurrent use
+func WithMutex() OptionFunc {
+	return func(o *options) {
+		o.useMutex = true
+	}
+}
+
+type node[K any, V any] struct {
+	key   K
+	value V
+	next  []*node[K, V]
+	// span[i] is the number of nodes on the bottom level between this node and next[i],
+	// the sum of spans along the search path gives the rank of a node
+	span []int
+}
+
+// SkipList is an ordered map built from several levels of linked lists,
+// every level skips over roughly 3/4 of the nodes of the level below it,
+// which gives O(log n) expected time for lookups, updates and rank queries.
+type SkipList[K any, V any] struct {
+	locker   utils.Locker
+	head     *node[K, V]
+	level    int
+	size     int
+	maxLevel int
+	compare  Comparator[K]
+	rnd      *rand.Rand
+}
+
+func New[K any, V any](compare Comparator[K], ofs ...OptionFunc) (*SkipList[K, V], error) {
+	if compare == nil {
+		return nil, ErrNilComparator
+	}
+
+	opts := options{maxLevel: DefaultMaxLevel}
+	for _, opt := range ofs {
+		opt(&opts)
+	}
+
+	if opts.maxLevel < 1 || opts.maxLevel > MaxLevelLimit {
+		return nil, errors.Wrapf(ErrInvalidMaxLevel, "must be between 1 and %d", MaxLevelLimit)
+	}
+
+	if !opts.hasSeed {
+		opts.seed = time.Now().UnixNano()
+	}
+
+	sl := SkipList[K, V]{
+		head: &node[K, V]{
+			next: make([]*node[K, V], opts.maxLevel),
+			span: make([]int, opts.maxLevel),
+		},
+		level:    1,
+		maxLevel: opts.maxLevel,
+		compare:  compare,
+		rnd:      rand.New(rand.NewSource(opts.seed)),
+	}
+
+	if opts.useMutex {
+		sl.locker = &utils.MutexLock{}
+	} else {
+		sl.locker = utils.NullLocker{}
+	}
+
+	return &sl, nil
+}
+
+// Put stores value by key and reports whether an existing value was replaced
+func (sl *SkipList[K, V]) Put(key K, value V) bool {
+	sl.locker.WriteLock()
+	defer sl.locker.WriteUnlock()
+
+	update := make([]*node[K, V], sl.maxLevel)
+	rank := make([]int, sl.maxLevel)
+
+	x := sl.head
+	for i := sl.level - 1; i >= 0; i-- {
+		if i < sl.level-1 {
+			rank[i] = rank[i+1]
+		}
+
+		for x.next[i] != nil && sl.compare(x.next[i].key, key) < 0 {
+			rank[i] += x.span[i]
+			x = x.next[i]
+		}
+
+		update[i] = x
+	}
+
+	if next := x.next[0]; next != nil && sl.compare(next.key, key) == 0 {
+		next.value = value
+		return true
+	}
+
+	level := sl.randomLevel()
+	if level > sl.level {
+		for i := sl.level; i < level; i++ {
+			rank[i] = 0
+			update[i] = sl.head
+			update[i].span[i] = sl.size
+		}
+		sl.level = level
+	}
+
+	n := &node[K, V]{
+		key:   key,
+		value: value,
+		next:  make([]*node[K, V], level),
+		span:  make([]int, level),
+	}
+
+	for i := 0; i < level; i++ {
+		n.next[i] = update[i].next[i]
+		update[i].next[i] = n
+
+		// rank[0] - rank[i] is the distance between update[i] and update[0]
+		n.span[i] = update[i].span[i] - (rank[0] - rank[i])
+		update[i].span[i] = rank[0] - rank[i] + 1
+	}
+
+	for i := level; i < sl.level; i++ {
+		update[i].span[i]++
+	}
+
+	sl.size++
+	return false
+}
+
+// Get returns value stored by key
+func (sl *SkipList[K, V]) Get(key K) (V, bool) {
+	sl.locker.ReadLock()
+	defer sl.locker.ReadUnlock()
+
+	x := sl.lowerBound(key)
+	if x != nil && sl.compare(x.key, key) == 0 {
+		return x.value, true
+	}
+
+	var zero V
+	return zero, false
+}
+
+// Contains reports whether the key is present
+func (sl *SkipList[K, V]) Contains(key K) bool {
+	_, ok := sl.Get(key)
+	return ok
+}
+
+// Delete removes the key and returns the value that was stored by it
+func (sl *SkipList[K, V]) Delete(key K) (V, bool) {
+	sl.locker.WriteLock()
+	defer sl.locker.WriteUnlock()
+
+	update := make([]*node[K, V], sl.maxLevel)
+	x := sl.head
+	for i := sl.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && sl.compare(x.next[i].key, key) < 0 {
+			x = x.next[i]
+		}
+		update[i] = x
+	}
+
+	x = x.next[0]
+	if x == nil || sl.compare(x.key, key) != 0 {
+		var zero V
+		return zero, false
+	}
+
+	for i := 0; i < sl.level; i++ {
+		if update[i].next[i] == x {
+			update[i].span[i] += x.span[i] - 1
+			update[i].next[i] = x.next[i]
+		} else {
+			update[i].span[i]--
+		}
+	}
+
+	for sl.level > 1 && sl.head.next[sl.level-1] == nil {
+		sl.level--
+	}
+
+	sl.size--
+	return x.value, true
+}
+
+// Floor returns the greatest key less than or equal to the given one
+func (sl *SkipList[K, V]) Floor(key K) (K, V, bool) {
+	sl.locker.ReadLock()
+	defer sl.locker.ReadUnlock()
+
+	x := sl.head
+	for i := sl.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && sl.compare(x.next[i].key, key) <= 0 {
+			x = x.next[i]
+		}
+	}
+
+	if x == sl.head {
+		return notFound[K, V]()
+	}
+
+	return x.key, x.value, true
+}
+
+// Ceiling returns the least key greater than or equal to the given one
+func (sl *SkipList[K, V]) Ceiling(key K) (K, V, bool) {
+	sl.locker.ReadLock()
+	defer sl.locker.ReadUnlock()
+
+	x := sl.lowerBound(key)
+	if x == nil {
+		return notFound[K, V]()
+	}
+
+	return x.key, x.value, true
+}
+
+// First returns the smallest key
+func (sl *SkipList[K, V]) First() (K, V, bool) {
+	return sl.At(0)
+}
+
+// Last returns the greatest key
+func (sl *SkipList[K, V]) Last() (K, V, bool) {
+	sl.locker.ReadLock()
+	defer sl.locker.ReadUnlock()
+
+	x := sl.head
+	for i := sl.level - 1; i >= 0; i-- {
+		for x.next[i] != nil {
+			x = x.next[i]
+		}
+	}
+
+	if x == sl.head {
+		return notFound[K, V]()
+	}
+
+	return x.key, x.value, true
+}
+
+// Rank returns the zero based position of the key in the sorted order
+func (sl *SkipList[K, V]) Rank(key K) (int, bool) {
+	sl.locker.ReadLock()
+	defer sl.locker.ReadUnlock()
+
+	rank := 0
+	x := sl.head
+	for i := sl.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && sl.compare(x.next[i].key, key) <= 0 {
+			rank += x.span[i]
+			x = x.next[i]
+		}
+	}
+
+	if x == sl.head || sl.compare(x.key, key) != 0 {
+		return 0, false
+	}
+
+	return rank - 1, true
+}
+
+// At returns the key and value at the zero based position in the sorted order
+func (sl *SkipList[K, V]) At(index int) (K, V, bool) {
+	sl.locker.ReadLock()
+	defer sl.locker.ReadUnlock()
+
+	if index < 0 || index >= sl.size {
+		return notFound[K, V]()
+	}
+
+	target := index + 1
+	traversed := 0
+	x := sl.head
+	for i := sl.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && traversed+x.span[i] <= target {
+			traversed += x.span[i]
+			x = x.next[i]
+		}
+
+		if traversed == target {
+			break
+		}
+	}
+
+	return x.key, x.value, true
+}
+
+// Range calls fn for every key in [from, to) in ascending order until fn returns false.
+// fn is called while the list is locked, so it must not modify the list.
+func (sl *SkipList[K, V]) Range(from, to K, fn func(key K, value V) bool) {
+	sl.locker.ReadLock()
+	defer sl.locker.ReadUnlock()
+
+	for x := sl.lowerBound(from); x != nil && sl.compare(x.key, to) < 0; x = x.next[0] {
+		if !fn(x.key, x.value) {
+			return
+		}
+	}
+}
+
+// ForEach calls fn for every key in ascending order until fn returns false
+func (sl *SkipList[K, V]) ForEach(fn func(key K, value V) bool) {
+	sl.locker.ReadLock()
+	defer sl.locker.ReadUnlock()
+
+	for x := sl.head.next[0]; x != nil; x = x.next[0] {
+		if !fn(x.key, x.value) {
+			return
+		}
+	}
+}
+
+// Keys returns all the keys in ascending order
+func (sl *SkipList[K, V]) Keys() []K {
+	sl.locker.ReadLock()
+	defer sl.locker.ReadUnlock()
+
+	result := make([]K, 0, sl.size)
+	for x := sl.head.next[0]; x != nil; x = x.next[0] {
+		result = append(result, x.key)
+	}
+
+	return result
+}
+
+func (sl *SkipList[K, V]) Size() int {
+	sl.locker.ReadLock()
+	defer sl.locker.ReadUnlock()
+	return sl.size
+}
+
+func (sl *SkipList[K, V]) Empty() bool {
+	sl.locker.ReadLock()
+	defer sl.locker.ReadUnlock()
+	return sl.size == 0
+}
+
+// lowerBound returns the first node with key greater than or equal to the given one
+func (sl *SkipList[K, V]) lowerBound(key K) *node[K, V] {
+	x := sl.head
+	for i := sl.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && sl.compare(x.next[i].key, key) < 0 {
+			x = x.next[i]
+		}
+	}
+
+	return x.next[0]
+}
+
+func (sl *SkipList[K, V]) randomLevel() int {
+	level := 1
+	for level < sl.maxLevel && sl.rnd.Intn(branching) == 0 {
+		level++
+	}
+
+	return level
+}
+
+func notFound[K any, V any]() (K, V, bool) {
+	var key K
+	var value V
+	return key, value, false
+}
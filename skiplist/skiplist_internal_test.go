@@ -0,0 +1,33 @@
+package skiplist
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func levels(sl *SkipList[int, int]) []int {
+	var result []int
+	for x := sl.head.next[0]; x != nil; x = x.next[0] {
+		result = append(result, len(x.next))
+	}
+	return result
+}
+
+func TestSkipList_DeterministicSeed(t *testing.T) {
+	build := func(seed int64) *SkipList[int, int] {
+		sl, err := New[int, int](func(a, b int) int { return a - b }, WithSeed(seed))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < 1000; i++ {
+			sl.Put((i*7919)%1000, i)
+		}
+		return sl
+	}
+
+	a, b, c := build(5), build(5), build(6)
+	assert.Equal(t, levels(a), levels(b))
+	assert.Equal(t, a.level, b.level)
+	assert.NotEqual(t, levels(a), levels(c))
+}
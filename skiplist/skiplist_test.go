@@ -0,0 +1,237 @@
+package skiplist_test
+
+import (
+	"fmt"
+	"github.com/denismitr/gds/skiplist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func compareInts(a, b int) int {
+	return a - b
+}
+
+func newIntList(t *testing.T, ofs ...skiplist.OptionFunc) *skiplist.SkipList[int, string] {
+	t.Helper()
+
+	sl, err := skiplist.New[int, string](compareInts, ofs...)
+	require.NoError(t, err)
+	return sl
+}
+
+func TestNew(t *testing.T) {
+	_, err := skiplist.New[int, int](compareInts, skiplist.WithMaxLevel(0))
+	assert.ErrorIs(t, err, skiplist.ErrInvalidMaxLevel)
+
+	_, err = skiplist.New[int, int](compareInts, skiplist.WithMaxLevel(65))
+	assert.ErrorIs(t, err, skiplist.ErrInvalidMaxLevel)
+
+	_, err = skiplist.New[int, int](nil)
+	assert.ErrorIs(t, err, skiplist.ErrNilComparator)
+}
+
+func TestSkipList_Basics(t *testing.T) {
+	sl := newIntList(t, skiplist.WithSeed(1))
+	assert.True(t, sl.Empty())
+
+	_, ok := sl.Get(1)
+	assert.False(t, ok)
+	_, _, ok = sl.First()
+	assert.False(t, ok)
+	_, _, ok = sl.Last()
+	assert.False(t, ok)
+
+	for _, k := range []int{50, 10, 40, 20, 30} {
+		assert.False(t, sl.Put(k, fmt.Sprint(k)))
+	}
+
+	assert.True(t, sl.Put(30, "thirty"))
+	assert.Equal(t, 5, sl.Size())
+	assert.Equal(t, []int{10, 20, 30, 40, 50}, sl.Keys())
+
+	v, ok := sl.Get(30)
+	require.True(t, ok)
+	assert.Equal(t, "thirty", v)
+	assert.True(t, sl.Contains(10))
+	assert.False(t, sl.Contains(11))
+
+	k, _, ok := sl.Floor(35)
+	require.True(t, ok)
+	assert.Equal(t, 30, k)
+	k, _, ok = sl.Floor(30)
+	require.True(t, ok)
+	assert.Equal(t, 30, k)
+	_, _, ok = sl.Floor(9)
+	assert.False(t, ok)
+
+	k, _, ok = sl.Ceiling(35)
+	require.True(t, ok)
+	assert.Equal(t, 40, k)
+	k, _, ok = sl.Ceiling(-100)
+	require.True(t, ok)
+	assert.Equal(t, 10, k)
+	_, _, ok = sl.Ceiling(51)
+	assert.False(t, ok)
+
+	k, _, _ = sl.First()
+	assert.Equal(t, 10, k)
+	k, _, _ = sl.Last()
+	assert.Equal(t, 50, k)
+
+	rank, ok := sl.Rank(40)
+	require.True(t, ok)
+	assert.Equal(t, 3, rank)
+	_, ok = sl.Rank(41)
+	assert.False(t, ok)
+
+	k, v, ok = sl.At(1)
+	require.True(t, ok)
+	assert.Equal(t, 20, k)
+	assert.Equal(t, "20", v)
+	_, _, ok = sl.At(5)
+	assert.False(t, ok)
+
+	var visited []int
+	sl.Range(15, 50, func(k int, _ string) bool {
+		visited = append(visited, k)
+		return true
+	})
+	assert.Equal(t, []int{20, 30, 40}, visited)
+
+	visited = nil
+	sl.ForEach(func(k int, _ string) bool {
+		visited = append(visited, k)
+		return k < 20
+	})
+	assert.Equal(t, []int{10, 20}, visited)
+
+	v, ok = sl.Delete(10)
+	require.True(t, ok)
+	assert.Equal(t, "10", v)
+	_, ok = sl.Delete(10)
+	assert.False(t, ok)
+
+	rank, _ = sl.Rank(40)
+	assert.Equal(t, 2, rank)
+	assert.Equal(t, 4, sl.Size())
+}
+
+func TestSkipList_CustomComparator(t *testing.T) {
+	// case insensitive keys in descending order
+	sl, err := skiplist.New[string, int](func(a, b string) int {
+		return strings.Compare(strings.ToLower(b), strings.ToLower(a))
+	})
+	require.NoError(t, err)
+
+	sl.Put("b", 1)
+	sl.Put("A", 2)
+	sl.Put("c", 3)
+	assert.True(t, sl.Put("B", 4))
+
+	assert.Equal(t, []string{"c", "b", "A"}, sl.Keys())
+	v, ok := sl.Get("a")
+	require.True(t, ok)
+	assert.Equal(t, 2, v)
+}
+
+func TestSkipList_AgainstSortedSlice(t *testing.T) {
+	rnd := rand.New(rand.NewSource(99))
+	sl := newIntList(t, skiplist.WithSeed(99))
+	model := make(map[int]string)
+
+	sortedKeys := func() []int {
+		keys := make([]int, 0, len(model))
+		for k := range model {
+			keys = append(keys, k)
+		}
+		sort.Ints(keys)
+		return keys
+	}
+
+	for i := 0; i < 20000; i++ {
+		k := rnd.Intn(2000)
+		if rnd.Intn(3) == 0 {
+			_, expected := model[k]
+			_, ok := sl.Delete(k)
+			require.Equal(t, expected, ok)
+			delete(model, k)
+		} else {
+			_, expected := model[k]
+			require.Equal(t, expected, sl.Put(k, fmt.Sprint(i)))
+			model[k] = fmt.Sprint(i)
+		}
+
+		if i%1000 != 0 {
+			continue
+		}
+
+		keys := sortedKeys()
+		require.Equal(t, keys, sl.Keys())
+		require.Equal(t, len(keys), sl.Size())
+
+		for rank, k := range keys {
+			r, ok := sl.Rank(k)
+			require.True(t, ok)
+			require.Equal(t, rank, r)
+
+			atKey, atValue, ok := sl.At(rank)
+			require.True(t, ok)
+			require.Equal(t, k, atKey)
+			require.Equal(t, model[k], atValue)
+		}
+
+		probe := rnd.Intn(2100) - 50
+		idx := sort.SearchInts(keys, probe)
+		ceiling, _, ok := sl.Ceiling(probe)
+		require.Equal(t, idx < len(keys), ok)
+		if ok {
+			require.Equal(t, keys[idx], ceiling)
+		}
+
+		floor, _, ok := sl.Floor(probe)
+		if idx < len(keys) && keys[idx] == probe {
+			require.True(t, ok)
+			require.Equal(t, probe, floor)
+		} else {
+			require.Equal(t, idx > 0, ok)
+			if ok {
+				require.Equal(t, keys[idx-1], floor)
+			}
+		}
+	}
+}
+
+func TestSkipList_Concurrent(t *testing.T) {
+	sl := newIntList(t, skiplist.WithMutex())
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				k := w*500 + i
+				sl.Put(k, fmt.Sprint(k))
+				v, ok := sl.Get(k)
+				assert.True(t, ok)
+				assert.Equal(t, fmt.Sprint(k), v)
+				if i%2 == 0 {
+					sl.Delete(k)
+				}
+				sl.Rank(k)
+				sl.Floor(k)
+			}
+		}(w)
+	}
+
+	wg.Wait()
+	assert.Equal(t, 8*250, sl.Size())
+
+	keys := sl.Keys()
+	assert.True(t, sort.IntsAreSorted(keys))
+}